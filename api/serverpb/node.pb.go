@@ -24,28 +24,70 @@ const (
 type RaftLogCommand int32
 
 const (
-	RaftLogCommand_SetWithTTL    RaftLogCommand = 0
-	RaftLogCommand_TrySetWithTTL RaftLogCommand = 1
-	RaftLogCommand_Set           RaftLogCommand = 2
-	RaftLogCommand_TrySet        RaftLogCommand = 3
-	RaftLogCommand_Del           RaftLogCommand = 4
+	RaftLogCommand_SetWithTTL      RaftLogCommand = 0
+	RaftLogCommand_TrySetWithTTL   RaftLogCommand = 1
+	RaftLogCommand_Set             RaftLogCommand = 2
+	RaftLogCommand_TrySet          RaftLogCommand = 3
+	RaftLogCommand_Del             RaftLogCommand = 4
+	RaftLogCommand_NamespaceCreate RaftLogCommand = 5
+	RaftLogCommand_NamespaceDrop   RaftLogCommand = 6
+	RaftLogCommand_NamespaceQuota  RaftLogCommand = 7
+	RaftLogCommand_AuthUser        RaftLogCommand = 8
+	RaftLogCommand_AuthRole        RaftLogCommand = 9
+	// the auth updates below are applied to the record at apply time, so that concurrent
+	// updates of the same user or role don't overwrite each other
+	// key is the user, value the role
+	RaftLogCommand_AuthUserGrantRole  RaftLogCommand = 10
+	RaftLogCommand_AuthUserRevokeRole RaftLogCommand = 11
+	// key is the user, value the User holding the new credentials
+	RaftLogCommand_AuthUserPassword RaftLogCommand = 12
+	// key is the role, value the Grant. the revoke only reads its namespace and prefix
+	RaftLogCommand_AuthRoleGrantPermission  RaftLogCommand = 13
+	RaftLogCommand_AuthRoleRevokePermission RaftLogCommand = 14
+	// key is the user or role, value the record. the record is only stored when it doesn't exist
+	RaftLogCommand_AuthUserCreate RaftLogCommand = 15
+	RaftLogCommand_AuthRoleCreate RaftLogCommand = 16
 )
 
 // Enum value maps for RaftLogCommand.
 var (
 	RaftLogCommand_name = map[int32]string{
-		0: "SetWithTTL",
-		1: "TrySetWithTTL",
-		2: "Set",
-		3: "TrySet",
-		4: "Del",
+		0:  "SetWithTTL",
+		1:  "TrySetWithTTL",
+		2:  "Set",
+		3:  "TrySet",
+		4:  "Del",
+		5:  "NamespaceCreate",
+		6:  "NamespaceDrop",
+		7:  "NamespaceQuota",
+		8:  "AuthUser",
+		9:  "AuthRole",
+		10: "AuthUserGrantRole",
+		11: "AuthUserRevokeRole",
+		12: "AuthUserPassword",
+		13: "AuthRoleGrantPermission",
+		14: "AuthRoleRevokePermission",
+		15: "AuthUserCreate",
+		16: "AuthRoleCreate",
 	}
 	RaftLogCommand_value = map[string]int32{
-		"SetWithTTL":    0,
-		"TrySetWithTTL": 1,
-		"Set":           2,
-		"TrySet":        3,
-		"Del":           4,
+		"SetWithTTL":               0,
+		"TrySetWithTTL":            1,
+		"Set":                      2,
+		"TrySet":                   3,
+		"Del":                      4,
+		"NamespaceCreate":          5,
+		"NamespaceDrop":            6,
+		"NamespaceQuota":           7,
+		"AuthUser":                 8,
+		"AuthRole":                 9,
+		"AuthUserGrantRole":        10,
+		"AuthUserRevokeRole":       11,
+		"AuthUserPassword":         12,
+		"AuthRoleGrantPermission":  13,
+		"AuthRoleRevokePermission": 14,
+		"AuthUserCreate":           15,
+		"AuthRoleCreate":           16,
 	}
 )
 
@@ -131,6 +173,55 @@ func (RaftState) EnumDescriptor() ([]byte, []int) {
 	return file_api_serverpb_node_proto_rawDescGZIP(), []int{1}
 }
 
+type RaftSuffrage int32
+
+const (
+	RaftSuffrage_voter    RaftSuffrage = 0
+	RaftSuffrage_nonvoter RaftSuffrage = 1
+	RaftSuffrage_staging  RaftSuffrage = 2
+)
+
+// Enum value maps for RaftSuffrage.
+var (
+	RaftSuffrage_name = map[int32]string{
+		0: "voter",
+		1: "nonvoter",
+		2: "staging",
+	}
+	RaftSuffrage_value = map[string]int32{
+		"voter":    0,
+		"nonvoter": 1,
+		"staging":  2,
+	}
+)
+
+func (x RaftSuffrage) Enum() *RaftSuffrage {
+	p := new(RaftSuffrage)
+	*p = x
+	return p
+}
+
+func (x RaftSuffrage) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RaftSuffrage) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_serverpb_node_proto_enumTypes[2].Descriptor()
+}
+
+func (RaftSuffrage) Type() protoreflect.EnumType {
+	return &file_api_serverpb_node_proto_enumTypes[2]
+}
+
+func (x RaftSuffrage) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RaftSuffrage.Descriptor instead.
+func (RaftSuffrage) EnumDescriptor() ([]byte, []int) {
+	return file_api_serverpb_node_proto_rawDescGZIP(), []int{2}
+}
+
 type RaftLogPayload struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -218,6 +309,8 @@ type AppendClusterRequest struct {
 	ServerId string `protobuf:"bytes,1,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
 	PeerAddr string `protobuf:"bytes,2,opt,name=peer_addr,json=peerAddr,proto3" json:"peer_addr,omitempty"`
 	Voter    bool   `protobuf:"varint,3,opt,name=voter,proto3" json:"voter,omitempty"`
+	// the advertised grpc address of the server, published for client discovery
+	ClientAddr *string `protobuf:"bytes,4,opt,name=client_addr,json=clientAddr,proto3,oneof" json:"client_addr,omitempty"`
 }
 
 func (x *AppendClusterRequest) Reset() {
@@ -270,32 +363,380 @@ func (x *AppendClusterRequest) GetVoter() bool {
 	if x != nil {
 		return x.Voter
 	}
-	return false
+	return false
+}
+
+func (x *AppendClusterRequest) GetClientAddr() string {
+	if x != nil && x.ClientAddr != nil {
+		return *x.ClientAddr
+	}
+	return ""
+}
+
+type AppendClusterResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *AppendClusterResponse) Reset() {
+	*x = AppendClusterResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_serverpb_node_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AppendClusterResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AppendClusterResponse) ProtoMessage() {}
+
+func (x *AppendClusterResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_serverpb_node_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AppendClusterResponse.ProtoReflect.Descriptor instead.
+func (*AppendClusterResponse) Descriptor() ([]byte, []int) {
+	return file_api_serverpb_node_proto_rawDescGZIP(), []int{2}
+}
+
+type RemoveClusterRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ServerId string `protobuf:"bytes,1,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
+}
+
+func (x *RemoveClusterRequest) Reset() {
+	*x = RemoveClusterRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_serverpb_node_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RemoveClusterRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveClusterRequest) ProtoMessage() {}
+
+func (x *RemoveClusterRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_serverpb_node_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveClusterRequest.ProtoReflect.Descriptor instead.
+func (*RemoveClusterRequest) Descriptor() ([]byte, []int) {
+	return file_api_serverpb_node_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *RemoveClusterRequest) GetServerId() string {
+	if x != nil {
+		return x.ServerId
+	}
+	return ""
+}
+
+type RemoveClusterResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *RemoveClusterResponse) Reset() {
+	*x = RemoveClusterResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_serverpb_node_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RemoveClusterResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveClusterResponse) ProtoMessage() {}
+
+func (x *RemoveClusterResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_serverpb_node_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveClusterResponse.ProtoReflect.Descriptor instead.
+func (*RemoveClusterResponse) Descriptor() ([]byte, []int) {
+	return file_api_serverpb_node_proto_rawDescGZIP(), []int{4}
+}
+
+type DemoteVoterRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ServerId string `protobuf:"bytes,1,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
+}
+
+func (x *DemoteVoterRequest) Reset() {
+	*x = DemoteVoterRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_serverpb_node_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DemoteVoterRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DemoteVoterRequest) ProtoMessage() {}
+
+func (x *DemoteVoterRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_serverpb_node_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DemoteVoterRequest.ProtoReflect.Descriptor instead.
+func (*DemoteVoterRequest) Descriptor() ([]byte, []int) {
+	return file_api_serverpb_node_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *DemoteVoterRequest) GetServerId() string {
+	if x != nil {
+		return x.ServerId
+	}
+	return ""
+}
+
+type DemoteVoterResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *DemoteVoterResponse) Reset() {
+	*x = DemoteVoterResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_serverpb_node_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DemoteVoterResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DemoteVoterResponse) ProtoMessage() {}
+
+func (x *DemoteVoterResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_serverpb_node_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DemoteVoterResponse.ProtoReflect.Descriptor instead.
+func (*DemoteVoterResponse) Descriptor() ([]byte, []int) {
+	return file_api_serverpb_node_proto_rawDescGZIP(), []int{6}
+}
+
+type PromoteLearnerRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ServerId string `protobuf:"bytes,1,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
+}
+
+func (x *PromoteLearnerRequest) Reset() {
+	*x = PromoteLearnerRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_serverpb_node_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PromoteLearnerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PromoteLearnerRequest) ProtoMessage() {}
+
+func (x *PromoteLearnerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_serverpb_node_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PromoteLearnerRequest.ProtoReflect.Descriptor instead.
+func (*PromoteLearnerRequest) Descriptor() ([]byte, []int) {
+	return file_api_serverpb_node_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *PromoteLearnerRequest) GetServerId() string {
+	if x != nil {
+		return x.ServerId
+	}
+	return ""
+}
+
+type PromoteLearnerResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *PromoteLearnerResponse) Reset() {
+	*x = PromoteLearnerResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_serverpb_node_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PromoteLearnerResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PromoteLearnerResponse) ProtoMessage() {}
+
+func (x *PromoteLearnerResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_serverpb_node_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PromoteLearnerResponse.ProtoReflect.Descriptor instead.
+func (*PromoteLearnerResponse) Descriptor() ([]byte, []int) {
+	return file_api_serverpb_node_proto_rawDescGZIP(), []int{8}
+}
+
+type LeadershipTransferRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// transfer to the most up to date voter when server_id is empty
+	ServerId *string `protobuf:"bytes,1,opt,name=server_id,json=serverId,proto3,oneof" json:"server_id,omitempty"`
+}
+
+func (x *LeadershipTransferRequest) Reset() {
+	*x = LeadershipTransferRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_serverpb_node_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LeadershipTransferRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LeadershipTransferRequest) ProtoMessage() {}
+
+func (x *LeadershipTransferRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_serverpb_node_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LeadershipTransferRequest.ProtoReflect.Descriptor instead.
+func (*LeadershipTransferRequest) Descriptor() ([]byte, []int) {
+	return file_api_serverpb_node_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *LeadershipTransferRequest) GetServerId() string {
+	if x != nil && x.ServerId != nil {
+		return *x.ServerId
+	}
+	return ""
 }
 
-type AppendClusterResponse struct {
+type LeadershipTransferResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 }
 
-func (x *AppendClusterResponse) Reset() {
-	*x = AppendClusterResponse{}
+func (x *LeadershipTransferResponse) Reset() {
+	*x = LeadershipTransferResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_api_serverpb_node_proto_msgTypes[2]
+		mi := &file_api_serverpb_node_proto_msgTypes[10]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *AppendClusterResponse) String() string {
+func (x *LeadershipTransferResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AppendClusterResponse) ProtoMessage() {}
+func (*LeadershipTransferResponse) ProtoMessage() {}
 
-func (x *AppendClusterResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_serverpb_node_proto_msgTypes[2]
+func (x *LeadershipTransferResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_serverpb_node_proto_msgTypes[10]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -306,9 +747,9 @@ func (x *AppendClusterResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AppendClusterResponse.ProtoReflect.Descriptor instead.
-func (*AppendClusterResponse) Descriptor() ([]byte, []int) {
-	return file_api_serverpb_node_proto_rawDescGZIP(), []int{2}
+// Deprecated: Use LeadershipTransferResponse.ProtoReflect.Descriptor instead.
+func (*LeadershipTransferResponse) Descriptor() ([]byte, []int) {
+	return file_api_serverpb_node_proto_rawDescGZIP(), []int{10}
 }
 
 type LeaderMonitorRequest struct {
@@ -320,7 +761,7 @@ type LeaderMonitorRequest struct {
 func (x *LeaderMonitorRequest) Reset() {
 	*x = LeaderMonitorRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_api_serverpb_node_proto_msgTypes[3]
+		mi := &file_api_serverpb_node_proto_msgTypes[11]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -333,7 +774,7 @@ func (x *LeaderMonitorRequest) String() string {
 func (*LeaderMonitorRequest) ProtoMessage() {}
 
 func (x *LeaderMonitorRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_serverpb_node_proto_msgTypes[3]
+	mi := &file_api_serverpb_node_proto_msgTypes[11]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -346,7 +787,7 @@ func (x *LeaderMonitorRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use LeaderMonitorRequest.ProtoReflect.Descriptor instead.
 func (*LeaderMonitorRequest) Descriptor() ([]byte, []int) {
-	return file_api_serverpb_node_proto_rawDescGZIP(), []int{3}
+	return file_api_serverpb_node_proto_rawDescGZIP(), []int{11}
 }
 
 type LeaderMonitorResponse struct {
@@ -360,7 +801,7 @@ type LeaderMonitorResponse struct {
 func (x *LeaderMonitorResponse) Reset() {
 	*x = LeaderMonitorResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_api_serverpb_node_proto_msgTypes[4]
+		mi := &file_api_serverpb_node_proto_msgTypes[12]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -373,7 +814,7 @@ func (x *LeaderMonitorResponse) String() string {
 func (*LeaderMonitorResponse) ProtoMessage() {}
 
 func (x *LeaderMonitorResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_serverpb_node_proto_msgTypes[4]
+	mi := &file_api_serverpb_node_proto_msgTypes[12]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -386,7 +827,7 @@ func (x *LeaderMonitorResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use LeaderMonitorResponse.ProtoReflect.Descriptor instead.
 func (*LeaderMonitorResponse) Descriptor() ([]byte, []int) {
-	return file_api_serverpb_node_proto_rawDescGZIP(), []int{4}
+	return file_api_serverpb_node_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *LeaderMonitorResponse) GetLeader() bool {
@@ -405,7 +846,7 @@ type RaftStateRequest struct {
 func (x *RaftStateRequest) Reset() {
 	*x = RaftStateRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_api_serverpb_node_proto_msgTypes[5]
+		mi := &file_api_serverpb_node_proto_msgTypes[13]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -418,7 +859,7 @@ func (x *RaftStateRequest) String() string {
 func (*RaftStateRequest) ProtoMessage() {}
 
 func (x *RaftStateRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_serverpb_node_proto_msgTypes[5]
+	mi := &file_api_serverpb_node_proto_msgTypes[13]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -431,7 +872,7 @@ func (x *RaftStateRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RaftStateRequest.ProtoReflect.Descriptor instead.
 func (*RaftStateRequest) Descriptor() ([]byte, []int) {
-	return file_api_serverpb_node_proto_rawDescGZIP(), []int{5}
+	return file_api_serverpb_node_proto_rawDescGZIP(), []int{13}
 }
 
 type RaftStateResponse struct {
@@ -445,7 +886,7 @@ type RaftStateResponse struct {
 func (x *RaftStateResponse) Reset() {
 	*x = RaftStateResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_api_serverpb_node_proto_msgTypes[6]
+		mi := &file_api_serverpb_node_proto_msgTypes[14]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -458,7 +899,7 @@ func (x *RaftStateResponse) String() string {
 func (*RaftStateResponse) ProtoMessage() {}
 
 func (x *RaftStateResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_serverpb_node_proto_msgTypes[6]
+	mi := &file_api_serverpb_node_proto_msgTypes[14]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -471,7 +912,7 @@ func (x *RaftStateResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RaftStateResponse.ProtoReflect.Descriptor instead.
 func (*RaftStateResponse) Descriptor() ([]byte, []int) {
-	return file_api_serverpb_node_proto_rawDescGZIP(), []int{6}
+	return file_api_serverpb_node_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *RaftStateResponse) GetState() RaftState {
@@ -481,6 +922,194 @@ func (x *RaftStateResponse) GetState() RaftState {
 	return RaftState_follower
 }
 
+type RaftMember struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ServerId string       `protobuf:"bytes,1,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
+	Address  string       `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+	Suffrage RaftSuffrage `protobuf:"varint,3,opt,name=suffrage,proto3,enum=serverpb.RaftSuffrage" json:"suffrage,omitempty"`
+	Leader   bool         `protobuf:"varint,4,opt,name=leader,proto3" json:"leader,omitempty"`
+	// unix milliseconds of the last contact, 0 when unknown.
+	// the leader tracks it for every follower, a follower only for itself
+	LastContact int64 `protobuf:"varint,5,opt,name=last_contact,json=lastContact,proto3" json:"last_contact,omitempty"`
+	// only known for the server answering the request
+	AppliedIndex *uint64 `protobuf:"varint,6,opt,name=applied_index,json=appliedIndex,proto3,oneof" json:"applied_index,omitempty"`
+	CommitIndex  uint64  `protobuf:"varint,7,opt,name=commit_index,json=commitIndex,proto3" json:"commit_index,omitempty"`
+	LastLogIndex uint64  `protobuf:"varint,8,opt,name=last_log_index,json=lastLogIndex,proto3" json:"last_log_index,omitempty"`
+	// only set by the leader when autopilot is enabled
+	Healthy *bool `protobuf:"varint,9,opt,name=healthy,proto3,oneof" json:"healthy,omitempty"`
+	// the advertised grpc address, empty when the server never published it
+	ClientAddr string `protobuf:"bytes,10,opt,name=client_addr,json=clientAddr,proto3" json:"client_addr,omitempty"`
+}
+
+func (x *RaftMember) Reset() {
+	*x = RaftMember{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_serverpb_node_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RaftMember) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RaftMember) ProtoMessage() {}
+
+func (x *RaftMember) ProtoReflect() protoreflect.Message {
+	mi := &file_api_serverpb_node_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RaftMember.ProtoReflect.Descriptor instead.
+func (*RaftMember) Descriptor() ([]byte, []int) {
+	return file_api_serverpb_node_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *RaftMember) GetServerId() string {
+	if x != nil {
+		return x.ServerId
+	}
+	return ""
+}
+
+func (x *RaftMember) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *RaftMember) GetSuffrage() RaftSuffrage {
+	if x != nil {
+		return x.Suffrage
+	}
+	return RaftSuffrage_voter
+}
+
+func (x *RaftMember) GetLeader() bool {
+	if x != nil {
+		return x.Leader
+	}
+	return false
+}
+
+func (x *RaftMember) GetLastContact() int64 {
+	if x != nil {
+		return x.LastContact
+	}
+	return 0
+}
+
+func (x *RaftMember) GetAppliedIndex() uint64 {
+	if x != nil && x.AppliedIndex != nil {
+		return *x.AppliedIndex
+	}
+	return 0
+}
+
+func (x *RaftMember) GetCommitIndex() uint64 {
+	if x != nil {
+		return x.CommitIndex
+	}
+	return 0
+}
+
+func (x *RaftMember) GetLastLogIndex() uint64 {
+	if x != nil {
+		return x.LastLogIndex
+	}
+	return 0
+}
+
+func (x *RaftMember) GetHealthy() bool {
+	if x != nil && x.Healthy != nil {
+		return *x.Healthy
+	}
+	return false
+}
+
+func (x *RaftMember) GetClientAddr() string {
+	if x != nil {
+		return x.ClientAddr
+	}
+	return ""
+}
+
+type MemberListResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Members []*RaftMember `protobuf:"bytes,1,rep,name=members,proto3" json:"members,omitempty"`
+	// the server answering the request
+	ServerId string `protobuf:"bytes,2,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
+	Term     uint64 `protobuf:"varint,3,opt,name=term,proto3" json:"term,omitempty"`
+}
+
+func (x *MemberListResponse) Reset() {
+	*x = MemberListResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_api_serverpb_node_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MemberListResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MemberListResponse) ProtoMessage() {}
+
+func (x *MemberListResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_serverpb_node_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MemberListResponse.ProtoReflect.Descriptor instead.
+func (*MemberListResponse) Descriptor() ([]byte, []int) {
+	return file_api_serverpb_node_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *MemberListResponse) GetMembers() []*RaftMember {
+	if x != nil {
+		return x.Members
+	}
+	return nil
+}
+
+func (x *MemberListResponse) GetServerId() string {
+	if x != nil {
+		return x.ServerId
+	}
+	return ""
+}
+
+func (x *MemberListResponse) GetTerm() uint64 {
+	if x != nil {
+		return x.Term
+	}
+	return 0
+}
+
 type RaftSnapshotRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -492,7 +1121,7 @@ type RaftSnapshotRequest struct {
 func (x *RaftSnapshotRequest) Reset() {
 	*x = RaftSnapshotRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_api_serverpb_node_proto_msgTypes[7]
+		mi := &file_api_serverpb_node_proto_msgTypes[17]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -505,7 +1134,7 @@ func (x *RaftSnapshotRequest) String() string {
 func (*RaftSnapshotRequest) ProtoMessage() {}
 
 func (x *RaftSnapshotRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_serverpb_node_proto_msgTypes[7]
+	mi := &file_api_serverpb_node_proto_msgTypes[17]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -518,7 +1147,7 @@ func (x *RaftSnapshotRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RaftSnapshotRequest.ProtoReflect.Descriptor instead.
 func (*RaftSnapshotRequest) Descriptor() ([]byte, []int) {
-	return file_api_serverpb_node_proto_rawDescGZIP(), []int{7}
+	return file_api_serverpb_node_proto_rawDescGZIP(), []int{17}
 }
 
 func (x *RaftSnapshotRequest) GetPath() string {
@@ -547,54 +1176,156 @@ var file_api_serverpb_node_proto_rawDesc = []byte{
 	0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x48, 0x02,
 	0x52, 0x09, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x88, 0x01, 0x01, 0x42, 0x08,
 	0x0a, 0x06, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x42, 0x06, 0x0a, 0x04, 0x5f, 0x74, 0x74, 0x6c,
-	0x42, 0x0c, 0x0a, 0x0a, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x22, 0x66,
-	0x0a, 0x14, 0x41, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x43, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x52,
-	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72,
-	0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x73, 0x65, 0x72, 0x76, 0x65,
-	0x72, 0x49, 0x64, 0x12, 0x1b, 0x0a, 0x09, 0x70, 0x65, 0x65, 0x72, 0x5f, 0x61, 0x64, 0x64, 0x72,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x65, 0x65, 0x72, 0x41, 0x64, 0x64, 0x72,
-	0x12, 0x14, 0x0a, 0x05, 0x76, 0x6f, 0x74, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52,
-	0x05, 0x76, 0x6f, 0x74, 0x65, 0x72, 0x22, 0x17, 0x0a, 0x15, 0x41, 0x70, 0x70, 0x65, 0x6e, 0x64,
-	0x43, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22,
-	0x16, 0x0a, 0x14, 0x4c, 0x65, 0x61, 0x64, 0x65, 0x72, 0x4d, 0x6f, 0x6e, 0x69, 0x74, 0x6f, 0x72,
-	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x2f, 0x0a, 0x15, 0x4c, 0x65, 0x61, 0x64, 0x65,
-	0x72, 0x4d, 0x6f, 0x6e, 0x69, 0x74, 0x6f, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
-	0x12, 0x16, 0x0a, 0x06, 0x6c, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08,
-	0x52, 0x06, 0x6c, 0x65, 0x61, 0x64, 0x65, 0x72, 0x22, 0x12, 0x0a, 0x10, 0x52, 0x61, 0x66, 0x74,
-	0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x3e, 0x0a, 0x11,
-	0x52, 0x61, 0x66, 0x74, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
-	0x65, 0x12, 0x29, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e,
-	0x32, 0x13, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x70, 0x62, 0x2e, 0x52, 0x61, 0x66, 0x74,
-	0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x22, 0x37, 0x0a, 0x13,
-	0x52, 0x61, 0x66, 0x74, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x12, 0x17, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x09, 0x48, 0x00, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x88, 0x01, 0x01, 0x42, 0x07, 0x0a, 0x05,
-	0x5f, 0x70, 0x61, 0x74, 0x68, 0x2a, 0x51, 0x0a, 0x0e, 0x52, 0x61, 0x66, 0x74, 0x4c, 0x6f, 0x67,
-	0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x0e, 0x0a, 0x0a, 0x53, 0x65, 0x74, 0x57, 0x69,
-	0x74, 0x68, 0x54, 0x54, 0x4c, 0x10, 0x00, 0x12, 0x11, 0x0a, 0x0d, 0x54, 0x72, 0x79, 0x53, 0x65,
-	0x74, 0x57, 0x69, 0x74, 0x68, 0x54, 0x54, 0x4c, 0x10, 0x01, 0x12, 0x07, 0x0a, 0x03, 0x53, 0x65,
-	0x74, 0x10, 0x02, 0x12, 0x0a, 0x0a, 0x06, 0x54, 0x72, 0x79, 0x53, 0x65, 0x74, 0x10, 0x03, 0x12,
-	0x07, 0x0a, 0x03, 0x44, 0x65, 0x6c, 0x10, 0x04, 0x2a, 0x4f, 0x0a, 0x09, 0x52, 0x61, 0x66, 0x74,
-	0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x0c, 0x0a, 0x08, 0x66, 0x6f, 0x6c, 0x6c, 0x6f, 0x77, 0x65,
-	0x72, 0x10, 0x00, 0x12, 0x0d, 0x0a, 0x09, 0x63, 0x61, 0x6e, 0x64, 0x69, 0x64, 0x61, 0x74, 0x65,
-	0x10, 0x01, 0x12, 0x0a, 0x0a, 0x06, 0x6c, 0x65, 0x61, 0x64, 0x65, 0x72, 0x10, 0x02, 0x12, 0x0c,
-	0x0a, 0x08, 0x73, 0x68, 0x75, 0x74, 0x64, 0x6f, 0x77, 0x6e, 0x10, 0x03, 0x12, 0x0b, 0x0a, 0x07,
-	0x75, 0x6e, 0x6b, 0x6e, 0x6f, 0x77, 0x6e, 0x10, 0x04, 0x32, 0xc1, 0x02, 0x0a, 0x08, 0x52, 0x65,
-	0x64, 0x51, 0x75, 0x65, 0x65, 0x6e, 0x12, 0x52, 0x0a, 0x0d, 0x41, 0x70, 0x70, 0x65, 0x6e, 0x64,
-	0x43, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x12, 0x1e, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72,
-	0x70, 0x62, 0x2e, 0x41, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x43, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72,
-	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72,
-	0x70, 0x62, 0x2e, 0x41, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x43, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x54, 0x0a, 0x0d, 0x4c, 0x65,
-	0x61, 0x64, 0x65, 0x72, 0x4d, 0x6f, 0x6e, 0x69, 0x74, 0x6f, 0x72, 0x12, 0x1e, 0x2e, 0x73, 0x65,
-	0x72, 0x76, 0x65, 0x72, 0x70, 0x62, 0x2e, 0x4c, 0x65, 0x61, 0x64, 0x65, 0x72, 0x4d, 0x6f, 0x6e,
-	0x69, 0x74, 0x6f, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x73, 0x65,
-	0x72, 0x76, 0x65, 0x72, 0x70, 0x62, 0x2e, 0x4c, 0x65, 0x61, 0x64, 0x65, 0x72, 0x4d, 0x6f, 0x6e,
-	0x69, 0x74, 0x6f, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x30, 0x01,
-	0x12, 0x42, 0x0a, 0x09, 0x52, 0x61, 0x66, 0x74, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x16, 0x2e,
-	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
-	0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x1b, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x70, 0x62,
-	0x2e, 0x52, 0x61, 0x66, 0x74, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x42, 0x0c, 0x0a, 0x0a, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x22, 0x9c,
+	0x01, 0x0a, 0x14, 0x41, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x43, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x73, 0x65, 0x72, 0x76, 0x65,
+	0x72, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x73, 0x65, 0x72, 0x76,
+	0x65, 0x72, 0x49, 0x64, 0x12, 0x1b, 0x0a, 0x09, 0x70, 0x65, 0x65, 0x72, 0x5f, 0x61, 0x64, 0x64,
+	0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x65, 0x65, 0x72, 0x41, 0x64, 0x64,
+	0x72, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x6f, 0x74, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x05, 0x76, 0x6f, 0x74, 0x65, 0x72, 0x12, 0x24, 0x0a, 0x0b, 0x63, 0x6c, 0x69, 0x65, 0x6e,
+	0x74, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x0a,
+	0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x41, 0x64, 0x64, 0x72, 0x88, 0x01, 0x01, 0x42, 0x0e, 0x0a,
+	0x0c, 0x5f, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x22, 0x17, 0x0a,
+	0x15, 0x41, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x43, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x33, 0x0a, 0x14, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65,
+	0x43, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1b,
+	0x0a, 0x09, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x08, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x49, 0x64, 0x22, 0x17, 0x0a, 0x15, 0x52,
+	0x65, 0x6d, 0x6f, 0x76, 0x65, 0x43, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x22, 0x31, 0x0a, 0x12, 0x44, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x56, 0x6f,
+	0x74, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x73, 0x65,
+	0x72, 0x76, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x73,
+	0x65, 0x72, 0x76, 0x65, 0x72, 0x49, 0x64, 0x22, 0x15, 0x0a, 0x13, 0x44, 0x65, 0x6d, 0x6f, 0x74,
+	0x65, 0x56, 0x6f, 0x74, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x34,
+	0x0a, 0x15, 0x50, 0x72, 0x6f, 0x6d, 0x6f, 0x74, 0x65, 0x4c, 0x65, 0x61, 0x72, 0x6e, 0x65, 0x72,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x73, 0x65, 0x72, 0x76, 0x65,
+	0x72, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x73, 0x65, 0x72, 0x76,
+	0x65, 0x72, 0x49, 0x64, 0x22, 0x18, 0x0a, 0x16, 0x50, 0x72, 0x6f, 0x6d, 0x6f, 0x74, 0x65, 0x4c,
+	0x65, 0x61, 0x72, 0x6e, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x4b,
+	0x0a, 0x19, 0x4c, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x68, 0x69, 0x70, 0x54, 0x72, 0x61, 0x6e,
+	0x73, 0x66, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x20, 0x0a, 0x09, 0x73,
+	0x65, 0x72, 0x76, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00,
+	0x52, 0x08, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x49, 0x64, 0x88, 0x01, 0x01, 0x42, 0x0c, 0x0a,
+	0x0a, 0x5f, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x22, 0x1c, 0x0a, 0x1a, 0x4c,
+	0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x68, 0x69, 0x70, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65,
+	0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x16, 0x0a, 0x14, 0x4c, 0x65, 0x61,
+	0x64, 0x65, 0x72, 0x4d, 0x6f, 0x6e, 0x69, 0x74, 0x6f, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x22, 0x2f, 0x0a, 0x15, 0x4c, 0x65, 0x61, 0x64, 0x65, 0x72, 0x4d, 0x6f, 0x6e, 0x69, 0x74,
+	0x6f, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x6c, 0x65,
+	0x61, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x6c, 0x65, 0x61, 0x64,
+	0x65, 0x72, 0x22, 0x12, 0x0a, 0x10, 0x52, 0x61, 0x66, 0x74, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x3e, 0x0a, 0x11, 0x52, 0x61, 0x66, 0x74, 0x53, 0x74,
+	0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x29, 0x0a, 0x05, 0x73,
+	0x74, 0x61, 0x74, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x13, 0x2e, 0x73, 0x65, 0x72,
+	0x76, 0x65, 0x72, 0x70, 0x62, 0x2e, 0x52, 0x61, 0x66, 0x74, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52,
+	0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x22, 0x83, 0x03, 0x0a, 0x0a, 0x52, 0x61, 0x66, 0x74, 0x4d,
+	0x65, 0x6d, 0x62, 0x65, 0x72, 0x12, 0x1b, 0x0a, 0x09, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x5f,
+	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72,
+	0x49, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x32, 0x0a, 0x08,
+	0x73, 0x75, 0x66, 0x66, 0x72, 0x61, 0x67, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x16,
+	0x2e, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x70, 0x62, 0x2e, 0x52, 0x61, 0x66, 0x74, 0x53, 0x75,
+	0x66, 0x66, 0x72, 0x61, 0x67, 0x65, 0x52, 0x08, 0x73, 0x75, 0x66, 0x66, 0x72, 0x61, 0x67, 0x65,
+	0x12, 0x16, 0x0a, 0x06, 0x6c, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x06, 0x6c, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x21, 0x0a, 0x0c, 0x6c, 0x61, 0x73, 0x74,
+	0x5f, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x63, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0b,
+	0x6c, 0x61, 0x73, 0x74, 0x43, 0x6f, 0x6e, 0x74, 0x61, 0x63, 0x74, 0x12, 0x28, 0x0a, 0x0d, 0x61,
+	0x70, 0x70, 0x6c, 0x69, 0x65, 0x64, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x04, 0x48, 0x00, 0x52, 0x0c, 0x61, 0x70, 0x70, 0x6c, 0x69, 0x65, 0x64, 0x49, 0x6e, 0x64,
+	0x65, 0x78, 0x88, 0x01, 0x01, 0x12, 0x21, 0x0a, 0x0c, 0x63, 0x6f, 0x6d, 0x6d, 0x69, 0x74, 0x5f,
+	0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x07, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x63, 0x6f, 0x6d,
+	0x6d, 0x69, 0x74, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x24, 0x0a, 0x0e, 0x6c, 0x61, 0x73, 0x74,
+	0x5f, 0x6c, 0x6f, 0x67, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x08, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x0c, 0x6c, 0x61, 0x73, 0x74, 0x4c, 0x6f, 0x67, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x1d,
+	0x0a, 0x07, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x79, 0x18, 0x09, 0x20, 0x01, 0x28, 0x08, 0x48,
+	0x01, 0x52, 0x07, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x79, 0x88, 0x01, 0x01, 0x12, 0x1f, 0x0a,
+	0x0b, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x18, 0x0a, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0a, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x41, 0x64, 0x64, 0x72, 0x42, 0x10,
+	0x0a, 0x0e, 0x5f, 0x61, 0x70, 0x70, 0x6c, 0x69, 0x65, 0x64, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78,
+	0x42, 0x0a, 0x0a, 0x08, 0x5f, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x79, 0x22, 0x75, 0x0a, 0x12,
+	0x4d, 0x65, 0x6d, 0x62, 0x65, 0x72, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x2e, 0x0a, 0x07, 0x6d, 0x65, 0x6d, 0x62, 0x65, 0x72, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x70, 0x62, 0x2e, 0x52,
+	0x61, 0x66, 0x74, 0x4d, 0x65, 0x6d, 0x62, 0x65, 0x72, 0x52, 0x07, 0x6d, 0x65, 0x6d, 0x62, 0x65,
+	0x72, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x49, 0x64, 0x12,
+	0x12, 0x0a, 0x04, 0x74, 0x65, 0x72, 0x6d, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x04, 0x74,
+	0x65, 0x72, 0x6d, 0x22, 0x37, 0x0a, 0x13, 0x52, 0x61, 0x66, 0x74, 0x53, 0x6e, 0x61, 0x70, 0x73,
+	0x68, 0x6f, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x17, 0x0a, 0x04, 0x70, 0x61,
+	0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68,
+	0x88, 0x01, 0x01, 0x42, 0x07, 0x0a, 0x05, 0x5f, 0x70, 0x61, 0x74, 0x68, 0x2a, 0xd1, 0x02, 0x0a,
+	0x0e, 0x52, 0x61, 0x66, 0x74, 0x4c, 0x6f, 0x67, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12,
+	0x0e, 0x0a, 0x0a, 0x53, 0x65, 0x74, 0x57, 0x69, 0x74, 0x68, 0x54, 0x54, 0x4c, 0x10, 0x00, 0x12,
+	0x11, 0x0a, 0x0d, 0x54, 0x72, 0x79, 0x53, 0x65, 0x74, 0x57, 0x69, 0x74, 0x68, 0x54, 0x54, 0x4c,
+	0x10, 0x01, 0x12, 0x07, 0x0a, 0x03, 0x53, 0x65, 0x74, 0x10, 0x02, 0x12, 0x0a, 0x0a, 0x06, 0x54,
+	0x72, 0x79, 0x53, 0x65, 0x74, 0x10, 0x03, 0x12, 0x07, 0x0a, 0x03, 0x44, 0x65, 0x6c, 0x10, 0x04,
+	0x12, 0x13, 0x0a, 0x0f, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x43, 0x72, 0x65,
+	0x61, 0x74, 0x65, 0x10, 0x05, 0x12, 0x11, 0x0a, 0x0d, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61,
+	0x63, 0x65, 0x44, 0x72, 0x6f, 0x70, 0x10, 0x06, 0x12, 0x12, 0x0a, 0x0e, 0x4e, 0x61, 0x6d, 0x65,
+	0x73, 0x70, 0x61, 0x63, 0x65, 0x51, 0x75, 0x6f, 0x74, 0x61, 0x10, 0x07, 0x12, 0x0c, 0x0a, 0x08,
+	0x41, 0x75, 0x74, 0x68, 0x55, 0x73, 0x65, 0x72, 0x10, 0x08, 0x12, 0x0c, 0x0a, 0x08, 0x41, 0x75,
+	0x74, 0x68, 0x52, 0x6f, 0x6c, 0x65, 0x10, 0x09, 0x12, 0x15, 0x0a, 0x11, 0x41, 0x75, 0x74, 0x68,
+	0x55, 0x73, 0x65, 0x72, 0x47, 0x72, 0x61, 0x6e, 0x74, 0x52, 0x6f, 0x6c, 0x65, 0x10, 0x0a, 0x12,
+	0x16, 0x0a, 0x12, 0x41, 0x75, 0x74, 0x68, 0x55, 0x73, 0x65, 0x72, 0x52, 0x65, 0x76, 0x6f, 0x6b,
+	0x65, 0x52, 0x6f, 0x6c, 0x65, 0x10, 0x0b, 0x12, 0x14, 0x0a, 0x10, 0x41, 0x75, 0x74, 0x68, 0x55,
+	0x73, 0x65, 0x72, 0x50, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x10, 0x0c, 0x12, 0x1b, 0x0a,
+	0x17, 0x41, 0x75, 0x74, 0x68, 0x52, 0x6f, 0x6c, 0x65, 0x47, 0x72, 0x61, 0x6e, 0x74, 0x50, 0x65,
+	0x72, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x10, 0x0d, 0x12, 0x1c, 0x0a, 0x18, 0x41, 0x75,
+	0x74, 0x68, 0x52, 0x6f, 0x6c, 0x65, 0x52, 0x65, 0x76, 0x6f, 0x6b, 0x65, 0x50, 0x65, 0x72, 0x6d,
+	0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x10, 0x0e, 0x12, 0x12, 0x0a, 0x0e, 0x41, 0x75, 0x74, 0x68,
+	0x55, 0x73, 0x65, 0x72, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x10, 0x0f, 0x12, 0x12, 0x0a, 0x0e,
+	0x41, 0x75, 0x74, 0x68, 0x52, 0x6f, 0x6c, 0x65, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x10, 0x10,
+	0x2a, 0x4f, 0x0a, 0x09, 0x52, 0x61, 0x66, 0x74, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x0c, 0x0a,
+	0x08, 0x66, 0x6f, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x72, 0x10, 0x00, 0x12, 0x0d, 0x0a, 0x09, 0x63,
+	0x61, 0x6e, 0x64, 0x69, 0x64, 0x61, 0x74, 0x65, 0x10, 0x01, 0x12, 0x0a, 0x0a, 0x06, 0x6c, 0x65,
+	0x61, 0x64, 0x65, 0x72, 0x10, 0x02, 0x12, 0x0c, 0x0a, 0x08, 0x73, 0x68, 0x75, 0x74, 0x64, 0x6f,
+	0x77, 0x6e, 0x10, 0x03, 0x12, 0x0b, 0x0a, 0x07, 0x75, 0x6e, 0x6b, 0x6e, 0x6f, 0x77, 0x6e, 0x10,
+	0x04, 0x2a, 0x34, 0x0a, 0x0c, 0x52, 0x61, 0x66, 0x74, 0x53, 0x75, 0x66, 0x66, 0x72, 0x61, 0x67,
+	0x65, 0x12, 0x09, 0x0a, 0x05, 0x76, 0x6f, 0x74, 0x65, 0x72, 0x10, 0x00, 0x12, 0x0c, 0x0a, 0x08,
+	0x6e, 0x6f, 0x6e, 0x76, 0x6f, 0x74, 0x65, 0x72, 0x10, 0x01, 0x12, 0x0b, 0x0a, 0x07, 0x73, 0x74,
+	0x61, 0x67, 0x69, 0x6e, 0x67, 0x10, 0x02, 0x32, 0xe3, 0x05, 0x0a, 0x08, 0x52, 0x65, 0x64, 0x51,
+	0x75, 0x65, 0x65, 0x6e, 0x12, 0x52, 0x0a, 0x0d, 0x41, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x43, 0x6c,
+	0x75, 0x73, 0x74, 0x65, 0x72, 0x12, 0x1e, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x70, 0x62,
+	0x2e, 0x41, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x43, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x70, 0x62,
+	0x2e, 0x41, 0x70, 0x70, 0x65, 0x6e, 0x64, 0x43, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x52, 0x0a, 0x0d, 0x52, 0x65, 0x6d, 0x6f,
+	0x76, 0x65, 0x43, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x12, 0x1e, 0x2e, 0x73, 0x65, 0x72, 0x76,
+	0x65, 0x72, 0x70, 0x62, 0x2e, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x43, 0x6c, 0x75, 0x73, 0x74,
+	0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x73, 0x65, 0x72, 0x76,
+	0x65, 0x72, 0x70, 0x62, 0x2e, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x43, 0x6c, 0x75, 0x73, 0x74,
+	0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x4c, 0x0a, 0x0b,
+	0x44, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x56, 0x6f, 0x74, 0x65, 0x72, 0x12, 0x1c, 0x2e, 0x73, 0x65,
+	0x72, 0x76, 0x65, 0x72, 0x70, 0x62, 0x2e, 0x44, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x56, 0x6f, 0x74,
+	0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x73, 0x65, 0x72, 0x76,
+	0x65, 0x72, 0x70, 0x62, 0x2e, 0x44, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x56, 0x6f, 0x74, 0x65, 0x72,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x55, 0x0a, 0x0e, 0x50, 0x72,
+	0x6f, 0x6d, 0x6f, 0x74, 0x65, 0x4c, 0x65, 0x61, 0x72, 0x6e, 0x65, 0x72, 0x12, 0x1f, 0x2e, 0x73,
+	0x65, 0x72, 0x76, 0x65, 0x72, 0x70, 0x62, 0x2e, 0x50, 0x72, 0x6f, 0x6d, 0x6f, 0x74, 0x65, 0x4c,
+	0x65, 0x61, 0x72, 0x6e, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x20, 0x2e,
+	0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x70, 0x62, 0x2e, 0x50, 0x72, 0x6f, 0x6d, 0x6f, 0x74, 0x65,
+	0x4c, 0x65, 0x61, 0x72, 0x6e, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22,
+	0x00, 0x12, 0x61, 0x0a, 0x12, 0x4c, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x68, 0x69, 0x70, 0x54,
+	0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x12, 0x23, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72,
+	0x70, 0x62, 0x2e, 0x4c, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x68, 0x69, 0x70, 0x54, 0x72, 0x61,
+	0x6e, 0x73, 0x66, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x24, 0x2e, 0x73,
+	0x65, 0x72, 0x76, 0x65, 0x72, 0x70, 0x62, 0x2e, 0x4c, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x68,
+	0x69, 0x70, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x22, 0x00, 0x12, 0x54, 0x0a, 0x0d, 0x4c, 0x65, 0x61, 0x64, 0x65, 0x72, 0x4d, 0x6f,
+	0x6e, 0x69, 0x74, 0x6f, 0x72, 0x12, 0x1e, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x70, 0x62,
+	0x2e, 0x4c, 0x65, 0x61, 0x64, 0x65, 0x72, 0x4d, 0x6f, 0x6e, 0x69, 0x74, 0x6f, 0x72, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x70, 0x62,
+	0x2e, 0x4c, 0x65, 0x61, 0x64, 0x65, 0x72, 0x4d, 0x6f, 0x6e, 0x69, 0x74, 0x6f, 0x72, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x30, 0x01, 0x12, 0x42, 0x0a, 0x09, 0x52, 0x61,
+	0x66, 0x74, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a,
+	0x1b, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x70, 0x62, 0x2e, 0x52, 0x61, 0x66, 0x74, 0x53,
+	0x74, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x44,
+	0x0a, 0x0a, 0x4d, 0x65, 0x6d, 0x62, 0x65, 0x72, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x16, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45,
+	0x6d, 0x70, 0x74, 0x79, 0x1a, 0x1c, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x70, 0x62, 0x2e,
+	0x4d, 0x65, 0x6d, 0x62, 0x65, 0x72, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
 	0x73, 0x65, 0x22, 0x00, 0x12, 0x47, 0x0a, 0x0c, 0x52, 0x61, 0x66, 0x74, 0x53, 0x6e, 0x61, 0x70,
 	0x73, 0x68, 0x6f, 0x74, 0x12, 0x1d, 0x2e, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x70, 0x62, 0x2e,
 	0x52, 0x61, 0x66, 0x74, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x52, 0x65, 0x71, 0x75,
@@ -616,37 +1347,60 @@ func file_api_serverpb_node_proto_rawDescGZIP() []byte {
 	return file_api_serverpb_node_proto_rawDescData
 }
 
-var file_api_serverpb_node_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_api_serverpb_node_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_api_serverpb_node_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_api_serverpb_node_proto_msgTypes = make([]protoimpl.MessageInfo, 18)
 var file_api_serverpb_node_proto_goTypes = []interface{}{
-	(RaftLogCommand)(0),           // 0: serverpb.RaftLogCommand
-	(RaftState)(0),                // 1: serverpb.RaftState
-	(*RaftLogPayload)(nil),        // 2: serverpb.RaftLogPayload
-	(*AppendClusterRequest)(nil),  // 3: serverpb.AppendClusterRequest
-	(*AppendClusterResponse)(nil), // 4: serverpb.AppendClusterResponse
-	(*LeaderMonitorRequest)(nil),  // 5: serverpb.LeaderMonitorRequest
-	(*LeaderMonitorResponse)(nil), // 6: serverpb.LeaderMonitorResponse
-	(*RaftStateRequest)(nil),      // 7: serverpb.RaftStateRequest
-	(*RaftStateResponse)(nil),     // 8: serverpb.RaftStateResponse
-	(*RaftSnapshotRequest)(nil),   // 9: serverpb.RaftSnapshotRequest
-	(*emptypb.Empty)(nil),         // 10: google.protobuf.Empty
+	(RaftLogCommand)(0),                // 0: serverpb.RaftLogCommand
+	(RaftState)(0),                     // 1: serverpb.RaftState
+	(RaftSuffrage)(0),                  // 2: serverpb.RaftSuffrage
+	(*RaftLogPayload)(nil),             // 3: serverpb.RaftLogPayload
+	(*AppendClusterRequest)(nil),       // 4: serverpb.AppendClusterRequest
+	(*AppendClusterResponse)(nil),      // 5: serverpb.AppendClusterResponse
+	(*RemoveClusterRequest)(nil),       // 6: serverpb.RemoveClusterRequest
+	(*RemoveClusterResponse)(nil),      // 7: serverpb.RemoveClusterResponse
+	(*DemoteVoterRequest)(nil),         // 8: serverpb.DemoteVoterRequest
+	(*DemoteVoterResponse)(nil),        // 9: serverpb.DemoteVoterResponse
+	(*PromoteLearnerRequest)(nil),      // 10: serverpb.PromoteLearnerRequest
+	(*PromoteLearnerResponse)(nil),     // 11: serverpb.PromoteLearnerResponse
+	(*LeadershipTransferRequest)(nil),  // 12: serverpb.LeadershipTransferRequest
+	(*LeadershipTransferResponse)(nil), // 13: serverpb.LeadershipTransferResponse
+	(*LeaderMonitorRequest)(nil),       // 14: serverpb.LeaderMonitorRequest
+	(*LeaderMonitorResponse)(nil),      // 15: serverpb.LeaderMonitorResponse
+	(*RaftStateRequest)(nil),           // 16: serverpb.RaftStateRequest
+	(*RaftStateResponse)(nil),          // 17: serverpb.RaftStateResponse
+	(*RaftMember)(nil),                 // 18: serverpb.RaftMember
+	(*MemberListResponse)(nil),         // 19: serverpb.MemberListResponse
+	(*RaftSnapshotRequest)(nil),        // 20: serverpb.RaftSnapshotRequest
+	(*emptypb.Empty)(nil),              // 21: google.protobuf.Empty
 }
 var file_api_serverpb_node_proto_depIdxs = []int32{
 	0,  // 0: serverpb.RaftLogPayload.command:type_name -> serverpb.RaftLogCommand
 	1,  // 1: serverpb.RaftStateResponse.state:type_name -> serverpb.RaftState
-	3,  // 2: serverpb.RedQueen.AppendCluster:input_type -> serverpb.AppendClusterRequest
-	5,  // 3: serverpb.RedQueen.LeaderMonitor:input_type -> serverpb.LeaderMonitorRequest
-	10, // 4: serverpb.RedQueen.RaftState:input_type -> google.protobuf.Empty
-	9,  // 5: serverpb.RedQueen.RaftSnapshot:input_type -> serverpb.RaftSnapshotRequest
-	4,  // 6: serverpb.RedQueen.AppendCluster:output_type -> serverpb.AppendClusterResponse
-	6,  // 7: serverpb.RedQueen.LeaderMonitor:output_type -> serverpb.LeaderMonitorResponse
-	8,  // 8: serverpb.RedQueen.RaftState:output_type -> serverpb.RaftStateResponse
-	10, // 9: serverpb.RedQueen.RaftSnapshot:output_type -> google.protobuf.Empty
-	6,  // [6:10] is the sub-list for method output_type
-	2,  // [2:6] is the sub-list for method input_type
-	2,  // [2:2] is the sub-list for extension type_name
-	2,  // [2:2] is the sub-list for extension extendee
-	0,  // [0:2] is the sub-list for field type_name
+	2,  // 2: serverpb.RaftMember.suffrage:type_name -> serverpb.RaftSuffrage
+	18, // 3: serverpb.MemberListResponse.members:type_name -> serverpb.RaftMember
+	4,  // 4: serverpb.RedQueen.AppendCluster:input_type -> serverpb.AppendClusterRequest
+	6,  // 5: serverpb.RedQueen.RemoveCluster:input_type -> serverpb.RemoveClusterRequest
+	8,  // 6: serverpb.RedQueen.DemoteVoter:input_type -> serverpb.DemoteVoterRequest
+	10, // 7: serverpb.RedQueen.PromoteLearner:input_type -> serverpb.PromoteLearnerRequest
+	12, // 8: serverpb.RedQueen.LeadershipTransfer:input_type -> serverpb.LeadershipTransferRequest
+	14, // 9: serverpb.RedQueen.LeaderMonitor:input_type -> serverpb.LeaderMonitorRequest
+	21, // 10: serverpb.RedQueen.RaftState:input_type -> google.protobuf.Empty
+	21, // 11: serverpb.RedQueen.MemberList:input_type -> google.protobuf.Empty
+	20, // 12: serverpb.RedQueen.RaftSnapshot:input_type -> serverpb.RaftSnapshotRequest
+	5,  // 13: serverpb.RedQueen.AppendCluster:output_type -> serverpb.AppendClusterResponse
+	7,  // 14: serverpb.RedQueen.RemoveCluster:output_type -> serverpb.RemoveClusterResponse
+	9,  // 15: serverpb.RedQueen.DemoteVoter:output_type -> serverpb.DemoteVoterResponse
+	11, // 16: serverpb.RedQueen.PromoteLearner:output_type -> serverpb.PromoteLearnerResponse
+	13, // 17: serverpb.RedQueen.LeadershipTransfer:output_type -> serverpb.LeadershipTransferResponse
+	15, // 18: serverpb.RedQueen.LeaderMonitor:output_type -> serverpb.LeaderMonitorResponse
+	17, // 19: serverpb.RedQueen.RaftState:output_type -> serverpb.RaftStateResponse
+	19, // 20: serverpb.RedQueen.MemberList:output_type -> serverpb.MemberListResponse
+	21, // 21: serverpb.RedQueen.RaftSnapshot:output_type -> google.protobuf.Empty
+	13, // [13:22] is the sub-list for method output_type
+	4,  // [4:13] is the sub-list for method input_type
+	4,  // [4:4] is the sub-list for extension type_name
+	4,  // [4:4] is the sub-list for extension extendee
+	0,  // [0:4] is the sub-list for field type_name
 }
 
 func init() { file_api_serverpb_node_proto_init() }
@@ -692,7 +1446,7 @@ func file_api_serverpb_node_proto_init() {
 			}
 		}
 		file_api_serverpb_node_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*LeaderMonitorRequest); i {
+			switch v := v.(*RemoveClusterRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -704,7 +1458,7 @@ func file_api_serverpb_node_proto_init() {
 			}
 		}
 		file_api_serverpb_node_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*LeaderMonitorResponse); i {
+			switch v := v.(*RemoveClusterResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -716,7 +1470,7 @@ func file_api_serverpb_node_proto_init() {
 			}
 		}
 		file_api_serverpb_node_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*RaftStateRequest); i {
+			switch v := v.(*DemoteVoterRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -728,7 +1482,7 @@ func file_api_serverpb_node_proto_init() {
 			}
 		}
 		file_api_serverpb_node_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*RaftStateResponse); i {
+			switch v := v.(*DemoteVoterResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -740,6 +1494,126 @@ func file_api_serverpb_node_proto_init() {
 			}
 		}
 		file_api_serverpb_node_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PromoteLearnerRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_serverpb_node_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PromoteLearnerResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_serverpb_node_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LeadershipTransferRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_serverpb_node_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LeadershipTransferResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_serverpb_node_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LeaderMonitorRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_serverpb_node_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LeaderMonitorResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_serverpb_node_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RaftStateRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_serverpb_node_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RaftStateResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_serverpb_node_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RaftMember); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_serverpb_node_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MemberListResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_api_serverpb_node_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*RaftSnapshotRequest); i {
 			case 0:
 				return &v.state
@@ -753,14 +1627,17 @@ func file_api_serverpb_node_proto_init() {
 		}
 	}
 	file_api_serverpb_node_proto_msgTypes[0].OneofWrappers = []interface{}{}
-	file_api_serverpb_node_proto_msgTypes[7].OneofWrappers = []interface{}{}
+	file_api_serverpb_node_proto_msgTypes[1].OneofWrappers = []interface{}{}
+	file_api_serverpb_node_proto_msgTypes[9].OneofWrappers = []interface{}{}
+	file_api_serverpb_node_proto_msgTypes[15].OneofWrappers = []interface{}{}
+	file_api_serverpb_node_proto_msgTypes[17].OneofWrappers = []interface{}{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_api_serverpb_node_proto_rawDesc,
-			NumEnums:      2,
-			NumMessages:   8,
+			NumEnums:      3,
+			NumMessages:   18,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -24,8 +24,13 @@ const _ = grpc.SupportPackageIsVersion7
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type RedQueenClient interface {
 	AppendCluster(ctx context.Context, in *AppendClusterRequest, opts ...grpc.CallOption) (*AppendClusterResponse, error)
+	RemoveCluster(ctx context.Context, in *RemoveClusterRequest, opts ...grpc.CallOption) (*RemoveClusterResponse, error)
+	DemoteVoter(ctx context.Context, in *DemoteVoterRequest, opts ...grpc.CallOption) (*DemoteVoterResponse, error)
+	PromoteLearner(ctx context.Context, in *PromoteLearnerRequest, opts ...grpc.CallOption) (*PromoteLearnerResponse, error)
+	LeadershipTransfer(ctx context.Context, in *LeadershipTransferRequest, opts ...grpc.CallOption) (*LeadershipTransferResponse, error)
 	LeaderMonitor(ctx context.Context, in *LeaderMonitorRequest, opts ...grpc.CallOption) (RedQueen_LeaderMonitorClient, error)
 	RaftState(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*RaftStateResponse, error)
+	MemberList(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*MemberListResponse, error)
 	RaftSnapshot(ctx context.Context, in *RaftSnapshotRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 }
 
@@ -46,6 +51,42 @@ func (c *redQueenClient) AppendCluster(ctx context.Context, in *AppendClusterReq
 	return out, nil
 }
 
+func (c *redQueenClient) RemoveCluster(ctx context.Context, in *RemoveClusterRequest, opts ...grpc.CallOption) (*RemoveClusterResponse, error) {
+	out := new(RemoveClusterResponse)
+	err := c.cc.Invoke(ctx, "/serverpb.RedQueen/RemoveCluster", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *redQueenClient) DemoteVoter(ctx context.Context, in *DemoteVoterRequest, opts ...grpc.CallOption) (*DemoteVoterResponse, error) {
+	out := new(DemoteVoterResponse)
+	err := c.cc.Invoke(ctx, "/serverpb.RedQueen/DemoteVoter", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *redQueenClient) PromoteLearner(ctx context.Context, in *PromoteLearnerRequest, opts ...grpc.CallOption) (*PromoteLearnerResponse, error) {
+	out := new(PromoteLearnerResponse)
+	err := c.cc.Invoke(ctx, "/serverpb.RedQueen/PromoteLearner", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *redQueenClient) LeadershipTransfer(ctx context.Context, in *LeadershipTransferRequest, opts ...grpc.CallOption) (*LeadershipTransferResponse, error) {
+	out := new(LeadershipTransferResponse)
+	err := c.cc.Invoke(ctx, "/serverpb.RedQueen/LeadershipTransfer", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *redQueenClient) LeaderMonitor(ctx context.Context, in *LeaderMonitorRequest, opts ...grpc.CallOption) (RedQueen_LeaderMonitorClient, error) {
 	stream, err := c.cc.NewStream(ctx, &RedQueen_ServiceDesc.Streams[0], "/serverpb.RedQueen/LeaderMonitor", opts...)
 	if err != nil {
@@ -87,6 +128,15 @@ func (c *redQueenClient) RaftState(ctx context.Context, in *emptypb.Empty, opts
 	return out, nil
 }
 
+func (c *redQueenClient) MemberList(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*MemberListResponse, error) {
+	out := new(MemberListResponse)
+	err := c.cc.Invoke(ctx, "/serverpb.RedQueen/MemberList", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *redQueenClient) RaftSnapshot(ctx context.Context, in *RaftSnapshotRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
 	out := new(emptypb.Empty)
 	err := c.cc.Invoke(ctx, "/serverpb.RedQueen/RaftSnapshot", in, out, opts...)
@@ -101,8 +151,13 @@ func (c *redQueenClient) RaftSnapshot(ctx context.Context, in *RaftSnapshotReque
 // for forward compatibility
 type RedQueenServer interface {
 	AppendCluster(context.Context, *AppendClusterRequest) (*AppendClusterResponse, error)
+	RemoveCluster(context.Context, *RemoveClusterRequest) (*RemoveClusterResponse, error)
+	DemoteVoter(context.Context, *DemoteVoterRequest) (*DemoteVoterResponse, error)
+	PromoteLearner(context.Context, *PromoteLearnerRequest) (*PromoteLearnerResponse, error)
+	LeadershipTransfer(context.Context, *LeadershipTransferRequest) (*LeadershipTransferResponse, error)
 	LeaderMonitor(*LeaderMonitorRequest, RedQueen_LeaderMonitorServer) error
 	RaftState(context.Context, *emptypb.Empty) (*RaftStateResponse, error)
+	MemberList(context.Context, *emptypb.Empty) (*MemberListResponse, error)
 	RaftSnapshot(context.Context, *RaftSnapshotRequest) (*emptypb.Empty, error)
 	mustEmbedUnimplementedRedQueenServer()
 }
@@ -114,12 +169,27 @@ type UnimplementedRedQueenServer struct {
 func (UnimplementedRedQueenServer) AppendCluster(context.Context, *AppendClusterRequest) (*AppendClusterResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method AppendCluster not implemented")
 }
+func (UnimplementedRedQueenServer) RemoveCluster(context.Context, *RemoveClusterRequest) (*RemoveClusterResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveCluster not implemented")
+}
+func (UnimplementedRedQueenServer) DemoteVoter(context.Context, *DemoteVoterRequest) (*DemoteVoterResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DemoteVoter not implemented")
+}
+func (UnimplementedRedQueenServer) PromoteLearner(context.Context, *PromoteLearnerRequest) (*PromoteLearnerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PromoteLearner not implemented")
+}
+func (UnimplementedRedQueenServer) LeadershipTransfer(context.Context, *LeadershipTransferRequest) (*LeadershipTransferResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LeadershipTransfer not implemented")
+}
 func (UnimplementedRedQueenServer) LeaderMonitor(*LeaderMonitorRequest, RedQueen_LeaderMonitorServer) error {
 	return status.Errorf(codes.Unimplemented, "method LeaderMonitor not implemented")
 }
 func (UnimplementedRedQueenServer) RaftState(context.Context, *emptypb.Empty) (*RaftStateResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method RaftState not implemented")
 }
+func (UnimplementedRedQueenServer) MemberList(context.Context, *emptypb.Empty) (*MemberListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MemberList not implemented")
+}
 func (UnimplementedRedQueenServer) RaftSnapshot(context.Context, *RaftSnapshotRequest) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method RaftSnapshot not implemented")
 }
@@ -154,6 +224,78 @@ func _RedQueen_AppendCluster_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _RedQueen_RemoveCluster_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveClusterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RedQueenServer).RemoveCluster(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/serverpb.RedQueen/RemoveCluster",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RedQueenServer).RemoveCluster(ctx, req.(*RemoveClusterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RedQueen_DemoteVoter_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DemoteVoterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RedQueenServer).DemoteVoter(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/serverpb.RedQueen/DemoteVoter",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RedQueenServer).DemoteVoter(ctx, req.(*DemoteVoterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RedQueen_PromoteLearner_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PromoteLearnerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RedQueenServer).PromoteLearner(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/serverpb.RedQueen/PromoteLearner",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RedQueenServer).PromoteLearner(ctx, req.(*PromoteLearnerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RedQueen_LeadershipTransfer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LeadershipTransferRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RedQueenServer).LeadershipTransfer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/serverpb.RedQueen/LeadershipTransfer",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RedQueenServer).LeadershipTransfer(ctx, req.(*LeadershipTransferRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _RedQueen_LeaderMonitor_Handler(srv interface{}, stream grpc.ServerStream) error {
 	m := new(LeaderMonitorRequest)
 	if err := stream.RecvMsg(m); err != nil {
@@ -193,6 +335,24 @@ func _RedQueen_RaftState_Handler(srv interface{}, ctx context.Context, dec func(
 	return interceptor(ctx, in, info, handler)
 }
 
+func _RedQueen_MemberList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RedQueenServer).MemberList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/serverpb.RedQueen/MemberList",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RedQueenServer).MemberList(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _RedQueen_RaftSnapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(RaftSnapshotRequest)
 	if err := dec(in); err != nil {
@@ -222,10 +382,30 @@ var RedQueen_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "AppendCluster",
 			Handler:    _RedQueen_AppendCluster_Handler,
 		},
+		{
+			MethodName: "RemoveCluster",
+			Handler:    _RedQueen_RemoveCluster_Handler,
+		},
+		{
+			MethodName: "DemoteVoter",
+			Handler:    _RedQueen_DemoteVoter_Handler,
+		},
+		{
+			MethodName: "PromoteLearner",
+			Handler:    _RedQueen_PromoteLearner_Handler,
+		},
+		{
+			MethodName: "LeadershipTransfer",
+			Handler:    _RedQueen_LeadershipTransfer_Handler,
+		},
 		{
 			MethodName: "RaftState",
 			Handler:    _RedQueen_RaftState_Handler,
 		},
+		{
+			MethodName: "MemberList",
+			Handler:    _RedQueen_MemberList_Handler,
+		},
 		{
 			MethodName: "RaftSnapshot",
 			Handler:    _RedQueen_RaftSnapshot_Handler,
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/RealFax/RedQueen/api/serverpb"
+	"github.com/RealFax/RedQueen/pkg/client"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+)
+
+func UserList(c *cli.Context) error {
+	users, err := invoker.UserList(c.Context)
+	if err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		fmt.Println(strconv.Quote(user))
+	}
+	return nil
+}
+
+func UserAdd(c *cli.Context) error {
+	name := c.String("name")
+	if name == "" {
+		return errors.New("name should be not be empty")
+	}
+	return invoker.UserAdd(c.Context, name, c.String("password"))
+}
+
+// readPassword returns the password flag, or the first line of stdin when it's not set
+func readPassword(c *cli.Context) (string, error) {
+	if c.IsSet("password") {
+		return c.String("password"), nil
+	}
+
+	fmt.Fprint(os.Stderr, "Password: ")
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func UserPasswd(c *cli.Context) error {
+	name := c.String("name")
+	if name == "" {
+		return errors.New("name should be not be empty")
+	}
+	password, err := readPassword(c)
+	if err != nil {
+		return err
+	}
+	if password == "" {
+		return errors.New("password should be not be empty")
+	}
+	return invoker.UserChangePassword(c.Context, name, password)
+}
+
+func UserDelete(c *cli.Context) error {
+	return invoker.UserDelete(c.Context, c.String("name"))
+}
+
+func UserGet(c *cli.Context) error {
+	user, err := invoker.UserGet(c.Context, c.String("name"))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("User: %s\nRoles: %s\n", strconv.Quote(user.Name), strings.Join(user.Roles, ", "))
+	return nil
+}
+
+func UserGrantRole(c *cli.Context) error {
+	return invoker.UserGrantRole(c.Context, c.String("name"), c.String("role"))
+}
+
+func UserRevokeRole(c *cli.Context) error {
+	return invoker.UserRevokeRole(c.Context, c.String("name"), c.String("role"))
+}
+
+func RoleList(c *cli.Context) error {
+	roles, err := invoker.RoleList(c.Context)
+	if err != nil {
+		return err
+	}
+
+	for _, role := range roles {
+		fmt.Println(strconv.Quote(role))
+	}
+	return nil
+}
+
+func RoleAdd(c *cli.Context) error {
+	name := c.String("name")
+	if name == "" {
+		return errors.New("name should be not be empty")
+	}
+	return invoker.RoleAdd(c.Context, name)
+}
+
+func RoleDelete(c *cli.Context) error {
+	return invoker.RoleDelete(c.Context, c.String("name"))
+}
+
+func RoleGet(c *cli.Context) error {
+	role, err := invoker.RoleGet(c.Context, c.String("name"))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Role: %s\n", strconv.Quote(role.Name))
+	for _, grant := range role.Grants {
+		permissions := make([]string, len(grant.Permissions))
+		for i, perm := range grant.Permissions {
+			permissions[i] = perm.String()
+		}
+		fmt.Printf(
+			"Namespace: %s, Prefix: %s, Permissions: %s\n",
+			strconv.Quote(grant.Namespace), strconv.Quote(string(grant.Prefix)), strings.Join(permissions, ", "),
+		)
+	}
+	return nil
+}
+
+func RoleGrant(c *cli.Context) error {
+	grant := client.Grant{
+		Namespace: c.String("namespace"),
+		Prefix:    []byte(c.String("prefix")),
+	}
+	for _, name := range c.StringSlice("permission") {
+		perm, ok := serverpb.Permission_value[strings.TrimSpace(name)]
+		if !ok {
+			return errors.Errorf("unknown permission %s, options: read, write, watch, lock, admin", strconv.Quote(name))
+		}
+		grant.Permissions = append(grant.Permissions, client.Permission(perm))
+	}
+	if len(grant.Permissions) == 0 {
+		return errors.New("permission should be not be empty")
+	}
+	return invoker.RoleGrantPermission(c.Context, c.String("name"), grant)
+}
+
+func RoleRevoke(c *cli.Context) error {
+	return invoker.RoleRevokePermission(c.Context, c.String("name"), c.String("namespace"), []byte(c.String("prefix")))
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"github.com/RealFax/RedQueen/pkg/client"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+	"strconv"
+)
+
+func NamespaceList(c *cli.Context) error {
+	namespaces, err := invoker.ListNamespaces(c.Context)
+	if err != nil {
+		return err
+	}
+
+	for _, namespace := range namespaces {
+		fmt.Println(strconv.Quote(namespace))
+	}
+	return nil
+}
+
+func NamespaceCreate(c *cli.Context) error {
+	namespace := c.String("namespace")
+	if namespace == "" {
+		return errors.New("namespace should be not be empty")
+	}
+	return invoker.CreateNamespace(c.Context, namespace)
+}
+
+func NamespaceDrop(c *cli.Context) error {
+	namespace := c.String("namespace")
+	if namespace == "" {
+		return errors.New("namespace should be not be empty")
+	}
+	return invoker.DropNamespace(c.Context, namespace)
+}
+
+func NamespaceStats(c *cli.Context) error {
+	stats, err := invoker.NamespaceStats(c.Context, c.String("namespace"))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Namespace: %s\nKeys: %d\nBytes: %d\n", strconv.Quote(stats.Namespace), stats.Keys, stats.Bytes)
+	return nil
+}
+
+func NamespaceGetQuota(c *cli.Context) error {
+	quota, err := invoker.GetQuota(c.Context, c.String("namespace"))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf(
+		"MaxKeys: %d\nMaxBytes: %d\nMaxValueSize: %d\nMaxTTL: %d\n",
+		quota.MaxKeys, quota.MaxBytes, quota.MaxValueSize, quota.MaxTTL,
+	)
+	return nil
+}
+
+func NamespaceSetQuota(c *cli.Context) error {
+	namespace := c.String("namespace")
+	if namespace == "" {
+		return errors.New("namespace should be not be empty")
+	}
+	return invoker.SetQuota(c.Context, namespace, client.Quota{
+		MaxKeys:      c.Uint64("max-keys"),
+		MaxBytes:     c.Uint64("max-bytes"),
+		MaxValueSize: uint32(c.Uint("max-value-size")),
+		MaxTTL:       uint32(c.Uint("max-ttl")),
+	})
+}
@@ -1,23 +1,65 @@
 package main
 
 import (
+	"github.com/RealFax/RedQueen/internal/rqd/store"
 	"github.com/RealFax/RedQueen/pkg/client"
 	"github.com/urfave/cli/v2"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 	"log"
+	"os"
+	"strconv"
+	"time"
 )
 
 func NodeAppendCluster(c *cli.Context) error {
-	return invoker.AppendCluster(c.Context, c.String("server-id"), c.String("peer-addr"), true)
+	return invoker.AppendCluster(c.Context, c.String("server-id"), c.String("peer-addr"), !c.Bool("learner"))
+}
+
+func NodeRemoveCluster(c *cli.Context) error {
+	return invoker.RemoveCluster(c.Context, c.String("server-id"))
+}
+
+func NodeDemoteVoter(c *cli.Context) error {
+	return invoker.DemoteVoter(c.Context, c.String("server-id"))
+}
+
+func NodePromoteLearner(c *cli.Context) error {
+	return invoker.PromoteLearner(c.Context, c.String("server-id"))
+}
+
+func NodeLeadershipTransfer(c *cli.Context) error {
+	return invoker.LeadershipTransfer(c.Context, c.String("server-id"))
+}
+
+func NodeMemberList(c *cli.Context) error {
+	members, err := invoker.MemberList(c.Context)
+	if err != nil {
+		return err
+	}
+
+	for _, member := range members {
+		lastContact := "unknown"
+		if !member.LastContact.IsZero() {
+			lastContact = time.Since(member.LastContact).Round(time.Millisecond).String()
+		}
+		applied := "unknown"
+		if member.AppliedIndex != nil {
+			applied = strconv.FormatUint(*member.AppliedIndex, 10)
+		}
+		healthy := "unknown"
+		if member.Healthy != nil {
+			healthy = strconv.FormatBool(*member.Healthy)
+		}
+		log.Printf(
+			"[+] id: %s, address: %s, client address: %s, suffrage: %s, leader: %v, healthy: %s, last contact: %s, applied: %s, commit: %d, last log: %d",
+			member.ServerID, member.Address, member.ClientAddr, member.Suffrage, member.Leader, healthy, lastContact,
+			applied, member.CommitIndex, member.LastLogIndex,
+		)
+	}
+	return nil
 }
 
 func NodeLeaderMonitor(c *cli.Context) error {
-	_invoker, err := client.New(
-		c.Context,
-		[]string{c.String("endpoint")},
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
+	_invoker, err := client.New(c.Context, []string{c.String("endpoint")}, dialOptions()...)
 	if err != nil {
 		return err
 	}
@@ -41,11 +83,7 @@ func NodeLeaderMonitor(c *cli.Context) error {
 }
 
 func NodeSnapshot(c *cli.Context) error {
-	_invoker, err := client.New(
-		c.Context,
-		[]string{c.String("endpoint")},
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
+	_invoker, err := client.New(c.Context, []string{c.String("endpoint")}, dialOptions()...)
 	if err != nil {
 		return err
 	}
@@ -57,3 +95,37 @@ func NodeSnapshot(c *cli.Context) error {
 	log.Printf("[+] snapshot save success")
 	return nil
 }
+
+func NodeSnapshotVerify(c *cli.Context) error {
+	f, err := os.Open(c.String("path"))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var keyring *store.Keyring
+	if path := c.String("keyring"); path != "" {
+		if keyring, err = store.LoadKeyring(path); err != nil {
+			return err
+		}
+	}
+	src, err := keyring.OpenSnapshot(f)
+	if err != nil {
+		return err
+	}
+
+	manifest, err := store.VerifySnapshot(src, c.Bool("legacy"))
+	if err != nil {
+		return err
+	}
+
+	if manifest.Version == 0 {
+		log.Printf("[+] legacy snapshot without manifest, entries are valid but have no checksums")
+		return nil
+	}
+	log.Printf(
+		"[+] snapshot valid, version: %d, backend: %s, index: %d, term: %d, files: %d",
+		manifest.Version, manifest.Backend, manifest.Index, manifest.Term, len(manifest.Files),
+	)
+	return nil
+}
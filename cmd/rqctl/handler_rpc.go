@@ -58,16 +58,27 @@ func RPCGet(c *cli.Context) error {
 	var (
 		key       = c.String("key")
 		namespace = c.String("namespace")
+
+		err   error
+		value *client.Value
 	)
 	if key == "" {
 		return errors.New("key should be not be empty")
 	}
 
-	value, err := invoker.Get(c.Context, hack.String2Bytes(key), Pointer(namespace))
+	if c.IsSet("revision") {
+		value, err = invoker.GetRevision(c.Context, hack.String2Bytes(key), c.Uint64("revision"), Pointer(namespace))
+	} else {
+		value, err = invoker.Get(c.Context, hack.String2Bytes(key), Pointer(namespace))
+	}
 	if err != nil {
 		return err
 	}
 
+	if value.Revision != 0 {
+		fmt.Printf("Revision: %d\n", value.Revision)
+	}
+
 	if value.Key != nil {
 		fmt.Printf("Key: %s\n", client.BString(value.Key))
 	}
@@ -121,6 +132,37 @@ func RPCPrefixScan(c *cli.Context) error {
 	return nil
 }
 
+func RPCHistory(c *cli.Context) error {
+	var (
+		key       = c.String("key")
+		namespace = c.String("namespace")
+		limit     = c.Uint64("limit")
+	)
+	if key == "" {
+		return errors.New("key should be not be empty")
+	}
+
+	versions, err := invoker.History(c.Context, hack.String2Bytes(key), limit, Pointer(namespace))
+	if err != nil {
+		return err
+	}
+
+	for _, version := range versions {
+		fmt.Printf("Revision: %d, Time: %s, ", version.Revision, time.UnixMilli(int64(version.Timestamp)).Format(time.RFC3339))
+		if version.Deleted {
+			fmt.Println("Deleted")
+			continue
+		}
+		if version.TTL == 0 {
+			fmt.Print("TTL: never, ")
+		} else {
+			fmt.Printf("TTL: %d second, ", version.TTL)
+		}
+		fmt.Printf("Data: %s\n", client.BString(version.Data))
+	}
+	return nil
+}
+
 func RPCDel(c *cli.Context) error {
 	var (
 		key       = c.String("key")
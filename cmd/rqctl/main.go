@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"github.com/RealFax/RedQueen/internal/version"
 	"github.com/RealFax/RedQueen/pkg/client"
+	"github.com/RealFax/RedQueen/pkg/grpcutil"
 	"net/netip"
 	"os"
 	"strings"
@@ -19,6 +20,8 @@ import (
 const (
 	helpText = `put the endpoint info into the environment variable: RQ_ENDPOINTS
 	format: 172.16.0.100:5230,172.16.0.101:5230,172.16.0.102:5230
+put the basic auth credentials into the environment variable: RQ_AUTH (optional)
+	format: username:password
 `
 )
 
@@ -26,6 +29,16 @@ var (
 	invoker *client.Client
 )
 
+// dialOptions returns the options of the connections, RQ_AUTH carries the basic auth credentials
+func dialOptions() []grpc.DialOption {
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	if username, password, ok := strings.Cut(os.Getenv("RQ_AUTH"), ":"); ok {
+		auth := grpcutil.NewBasicAuthClient(username, password)
+		opts = append(opts, grpc.WithUnaryInterceptor(auth.Unary), grpc.WithStreamInterceptor(auth.Stream))
+	}
+	return opts
+}
+
 func dialRQ() error {
 	endpointString, ok := syscall.Getenv("RQ_ENDPOINTS")
 	if !ok {
@@ -48,11 +61,7 @@ func dialRQ() error {
 	}
 
 	client.SetMaxOpenConn(1)
-	if invoker, err = client.New(
-		context.Background(),
-		endpoints,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	); err != nil {
+	if invoker, err = client.New(context.Background(), endpoints, dialOptions()...); err != nil {
 		return err
 	}
 	return nil
@@ -81,8 +90,56 @@ func main() {
 						Name:  "peer-addr",
 						Usage: "RedQueen peer address",
 					},
+					&cli.BoolFlag{
+						Name:  "learner",
+						Usage: "Append the node as a learner (nonvoter) that only replicates the log",
+					},
 				},
 				Action: NodeAppendCluster,
+			}, {
+				Name:      "remove-cluster",
+				UsageText: "Remove a node from the raft cluster, e.g. to replace a failed node",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "server-id",
+						Usage: "RedQueen node server id",
+					},
+				},
+				Action: NodeRemoveCluster,
+			}, {
+				Name:      "demote-voter",
+				UsageText: "Demote a voter of the raft cluster to a learner",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "server-id",
+						Usage: "RedQueen node server id",
+					},
+				},
+				Action: NodeDemoteVoter,
+			}, {
+				Name:      "promote-learner",
+				UsageText: "Promote a learner of the raft cluster to a voter",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "server-id",
+						Usage: "RedQueen node server id",
+					},
+				},
+				Action: NodePromoteLearner,
+			}, {
+				Name:      "leadership-transfer",
+				UsageText: "Transfer the leadership to the specified node, or to any voter",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "server-id",
+						Usage: "RedQueen node server id, empty means any voter",
+					},
+				},
+				Action: NodeLeadershipTransfer,
+			}, {
+				Name:      "member-list",
+				UsageText: "List the servers of the raft cluster with their replication status",
+				Action:    NodeMemberList,
 			}, {
 				Name:      "leader-monitor",
 				UsageText: "Monitor the election (voting) status of the specified node",
@@ -107,6 +164,24 @@ func main() {
 					},
 				},
 				Action: NodeSnapshot,
+			}, {
+				Name:      "snapshot-verify",
+				UsageText: "Verify the manifest and checksums of a local snapshot file before installing it",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "path",
+						Usage: "The path of the local snapshot file",
+					},
+					&cli.StringFlag{
+						Name:  "keyring",
+						Usage: "The key file or keyring of an encrypted snapshot",
+					},
+					&cli.BoolFlag{
+						Name:  "legacy",
+						Usage: "Accept a legacy snapshot without manifest",
+					},
+				},
+				Action: NodeSnapshotVerify,
 			}, {
 				Name: "set",
 				Flags: []cli.Flag{
@@ -140,8 +215,28 @@ func main() {
 					&cli.StringFlag{
 						Name: "namespace",
 					},
+					&cli.Uint64Flag{
+						Name:  "revision",
+						Usage: "read the version of the key at the revision, requires key history",
+					},
 				},
 				Action: RPCGet,
+			}, {
+				Name:      "history",
+				UsageText: "List the kept versions of a key, newest first",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name: "key",
+					},
+					&cli.StringFlag{
+						Name: "namespace",
+					},
+					&cli.Uint64Flag{
+						Name:  "limit",
+						Usage: "max number of versions, 0 means all kept versions",
+					},
+				},
+				Action: RPCHistory,
 			}, {
 				Name: "prefix-scan",
 				Flags: []cli.Flag{
@@ -201,6 +296,210 @@ func main() {
 					},
 				},
 				Action: RPCWatchPrefix,
+			}, {
+				Name:      "namespace",
+				UsageText: "Manage the namespaces of the key-value store",
+				Subcommands: []*cli.Command{
+					{
+						Name:   "list",
+						Action: NamespaceList,
+					}, {
+						Name: "create",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name: "namespace",
+							},
+						},
+						Action: NamespaceCreate,
+					}, {
+						Name:      "drop",
+						UsageText: "Drop the namespace with all its keys",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name: "namespace",
+							},
+						},
+						Action: NamespaceDrop,
+					}, {
+						Name: "stats",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name: "namespace",
+							},
+						},
+						Action: NamespaceStats,
+					}, {
+						Name: "get-quota",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name: "namespace",
+							},
+						},
+						Action: NamespaceGetQuota,
+					}, {
+						Name:      "set-quota",
+						UsageText: "Replace the quota of the namespace, 0 means unlimited",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name: "namespace",
+							},
+							&cli.Uint64Flag{
+								Name: "max-keys",
+							},
+							&cli.Uint64Flag{
+								Name: "max-bytes",
+							},
+							&cli.UintFlag{
+								Name: "max-value-size",
+							},
+							&cli.UintFlag{
+								Name:  "max-ttl",
+								Usage: "keys that never ttl are rejected once it is set",
+							},
+						},
+						Action: NamespaceSetQuota,
+					},
+				},
+			}, {
+				Name:      "user",
+				UsageText: "Manage the users, their passwords and roles",
+				Subcommands: []*cli.Command{
+					{
+						Name:   "list",
+						Action: UserList,
+					}, {
+						Name: "add",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name: "name",
+							},
+							&cli.StringFlag{
+								Name:  "password",
+								Usage: "password of the basic auth, the user has none when empty",
+							},
+						},
+						Action: UserAdd,
+					}, {
+						Name: "passwd",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name: "name",
+							},
+							&cli.StringFlag{
+								Name:  "password",
+								Usage: "new password of the basic auth, read from stdin when not set",
+							},
+						},
+						Action: UserPasswd,
+					}, {
+						Name: "delete",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name: "name",
+							},
+						},
+						Action: UserDelete,
+					}, {
+						Name: "get",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name: "name",
+							},
+						},
+						Action: UserGet,
+					}, {
+						Name: "grant-role",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name: "name",
+							},
+							&cli.StringFlag{
+								Name: "role",
+							},
+						},
+						Action: UserGrantRole,
+					}, {
+						Name: "revoke-role",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name: "name",
+							},
+							&cli.StringFlag{
+								Name: "role",
+							},
+						},
+						Action: UserRevokeRole,
+					},
+				},
+			}, {
+				Name:      "role",
+				UsageText: "Manage the roles of the rbac",
+				Subcommands: []*cli.Command{
+					{
+						Name:   "list",
+						Action: RoleList,
+					}, {
+						Name: "add",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name: "name",
+							},
+						},
+						Action: RoleAdd,
+					}, {
+						Name:      "delete",
+						UsageText: "Delete the role, the users holding it lose its grants",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name: "name",
+							},
+						},
+						Action: RoleDelete,
+					}, {
+						Name: "get",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name: "name",
+							},
+						},
+						Action: RoleGet,
+					}, {
+						Name:      "grant",
+						UsageText: "Grant permissions on the keys (lock ids) starting with the prefix, it replaces the grant of the same namespace and prefix",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name: "name",
+							},
+							&cli.StringFlag{
+								Name:  "namespace",
+								Usage: "empty is the default namespace, * every namespace",
+							},
+							&cli.StringFlag{
+								Name:  "prefix",
+								Usage: "empty is every key",
+							},
+							&cli.StringSliceFlag{
+								Name:  "permission",
+								Usage: "options: read, write, watch, lock, admin",
+							},
+						},
+						Action: RoleGrant,
+					}, {
+						Name: "revoke",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name: "name",
+							},
+							&cli.StringFlag{
+								Name: "namespace",
+							},
+							&cli.StringFlag{
+								Name: "prefix",
+							},
+						},
+						Action: RoleRevoke,
+					},
+				},
 			},
 		},
 	}
@@ -19,6 +19,14 @@ func main() {
 		return
 	}
 
+	if cfg.Env().Recovering() {
+		if err = rqd.Recover(cfg); err != nil {
+			fmt.Println("[-] Failed to recover cluster, ", err)
+			return
+		}
+		fmt.Println("[+] Recovered as a single node cluster, the other members must be wiped and join it again")
+	}
+
 	server, err := rqd.NewServer(cfg)
 	if err != nil {
 		fmt.Println("[-] Failed to initialize server, ", err)
@@ -32,10 +40,32 @@ func main() {
 	}
 
 	c := make(chan os.Signal, 1)
-	signal.Notify(c, syscall.SIGTERM, syscall.SIGINT)
-	select {
-	case <-c:
-		server.Shutdown()
-		return
+	signal.Notify(c, syscall.SIGTERM, syscall.SIGINT, syscall.SIGHUP)
+	for sig := range c {
+		switch sig {
+		case syscall.SIGHUP:
+			// reload the certificates and the basic auth users
+			reloaded, err := config.New(os.Args...)
+			if err != nil {
+				fmt.Println("[-] Failed reload config, ", err)
+				continue
+			}
+			if err = server.Reload(reloaded); err != nil {
+				fmt.Println("[-] Failed reload server, ", err)
+				continue
+			}
+			fmt.Println("[+] Reloaded")
+		case syscall.SIGTERM:
+			// drain the in-flight requests and hand the leadership off
+			timeout := cfg.Node.ShutdownTimeout
+			if timeout == 0 {
+				timeout = config.DefaultNodeShutdownTimeout
+			}
+			server.GracefulShutdown(timeout)
+			return
+		default:
+			// SIGINT stops at once
+			return
+		}
 	}
 }
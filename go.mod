@@ -16,6 +16,7 @@ require (
 	github.com/pkg/errors v0.9.1
 	github.com/stretchr/testify v1.9.0
 	github.com/urfave/cli/v2 v2.25.7
+	golang.org/x/crypto v0.21.0
 	google.golang.org/grpc v1.61.0
 	google.golang.org/protobuf v1.33.0
 )
@@ -0,0 +1,361 @@
+package rqd
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"time"
+	"unicode/utf8"
+
+	"github.com/RealFax/RedQueen/api/serverpb"
+	"github.com/RealFax/RedQueen/internal/rqd/config"
+	"github.com/RealFax/RedQueen/internal/rqd/store"
+	"github.com/RealFax/RedQueen/pkg/expr"
+	"github.com/RealFax/RedQueen/pkg/fs"
+	"github.com/RealFax/RedQueen/pkg/grpcutil"
+	"github.com/RealFax/RedQueen/pkg/httputil"
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/raft"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// AuditNamespace holds the replicated audit records, keyed by the time and the server id
+const AuditNamespace = "_Audit"
+
+// auditQueueSize bounds the records waiting to be replicated, the later ones are dropped
+const auditQueueSize = 1024
+
+func init() {
+	store.ReserveNamespace(AuditNamespace)
+}
+
+// AuditRecord is a json line of the audit log
+type AuditRecord struct {
+	Time     time.Time `json:"time"`
+	Server   string    `json:"server"`
+	User     string    `json:"user,omitempty"`
+	Remote   string    `json:"remote,omitempty"`
+	Protocol string    `json:"protocol"`
+	Method   string    `json:"method"`
+	// Namespace is nil for the requests outside of the namespaces, e.g. the membership changes
+	Namespace *string `json:"namespace,omitempty"`
+	Key       string  `json:"key,omitempty"`
+	// Result is the grpc code (or the http status) of the response
+	Result string `json:"result"`
+	Error  string `json:"error,omitempty"`
+}
+
+// AuditApplyFunc replicates an audit record keyed by key
+type AuditApplyFunc func(ctx context.Context, key, record []byte) error
+
+// Auditor writes the audit records to a rotating json-lines file and, with an AuditApplyFunc,
+// queues them to be replicated. only the leader replicates its records, the records of the
+// followers stay in their own file
+type Auditor struct {
+	server   string
+	hashKeys bool
+	logger   hclog.Logger
+
+	w       io.WriteCloser
+	applyFC AuditApplyFunc
+	queue   chan *AuditRecord
+}
+
+// Key returns the key as recorded, the sha256 of it with HashKeys
+func (a *Auditor) Key(key []byte) string {
+	switch {
+	case len(key) == 0:
+		return ""
+	case a.hashKeys:
+		sum := sha256.Sum256(key)
+		return "sha256:" + hex.EncodeToString(sum[:])
+	case utf8.Valid(key):
+		return string(key)
+	default:
+		return "base64:" + base64.StdEncoding.EncodeToString(key)
+	}
+}
+
+// Record writes the record, it's queued to be replicated too
+func (a *Auditor) Record(record *AuditRecord) {
+	if record.Time.IsZero() {
+		record.Time = time.Now()
+	}
+	record.Server = a.server
+
+	b, err := json.Marshal(record)
+	if err != nil {
+		a.logger.Warn("failed to marshal audit record", "error", err)
+		return
+	}
+	if _, err = a.w.Write(append(b, '\n')); err != nil {
+		a.logger.Warn("failed to write audit record", "error", err)
+	}
+
+	if a.applyFC == nil {
+		return
+	}
+	select {
+	case a.queue <- record:
+	default:
+		a.logger.Warn("audit replication queue is full, record dropped", "method", record.Method)
+	}
+}
+
+func (a *Auditor) replicate(ctx context.Context, record *AuditRecord) {
+	b, err := json.Marshal(record)
+	if err != nil {
+		return
+	}
+	// the keys sort by time
+	key := fmt.Sprintf("%020d-%s", record.Time.UnixNano(), a.server)
+	if err = a.applyFC(ctx, []byte(key), b); err != nil {
+		a.logger.Debug("failed to replicate audit record", "method", record.Method, "error", err)
+	}
+}
+
+// Run replicates the queued records until ctx is done
+func (a *Auditor) Run(ctx context.Context) {
+	if a.applyFC == nil {
+		return
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case record := <-a.queue:
+			a.replicate(ctx, record)
+		}
+	}
+}
+
+// Flush replicates the records queued so far, until ctx is done
+func (a *Auditor) Flush(ctx context.Context) {
+	if a.applyFC == nil {
+		return
+	}
+	for ctx.Err() == nil {
+		select {
+		case record := <-a.queue:
+			a.replicate(ctx, record)
+		default:
+			return
+		}
+	}
+}
+
+func (a *Auditor) Close() error {
+	return a.w.Close()
+}
+
+// auditRPC returns the namespace and the key of the audited requests
+func auditRPC(req any) (namespace *string, key []byte, ok bool) {
+	switch r := req.(type) {
+	case *serverpb.SetRequest:
+		return expr.If(r.Namespace == nil, expr.Pointer(store.DefaultNamespace), r.Namespace), r.Key, true
+	case *serverpb.DeleteRequest:
+		return expr.If(r.Namespace == nil, expr.Pointer(store.DefaultNamespace), r.Namespace), r.Key, true
+	case *serverpb.LockRequest:
+		return nil, []byte(r.LockId), true
+	case *serverpb.UnlockRequest:
+		return nil, []byte(r.LockId), true
+	case *serverpb.TryLockRequest:
+		return nil, []byte(r.LockId), true
+	case *serverpb.NamespaceCreateRequest:
+		return &r.Namespace, nil, true
+	case *serverpb.NamespaceDropRequest:
+		return &r.Namespace, nil, true
+	case *serverpb.NamespaceSetQuotaRequest:
+		return &r.Namespace, nil, true
+	case *serverpb.AppendClusterRequest:
+		return nil, []byte(r.ServerId), true
+	case *serverpb.RemoveClusterRequest:
+		return nil, []byte(r.ServerId), true
+	case *serverpb.DemoteVoterRequest:
+		return nil, []byte(r.ServerId), true
+	case *serverpb.PromoteLearnerRequest:
+		return nil, []byte(r.ServerId), true
+	case *serverpb.LeadershipTransferRequest:
+		return nil, []byte(r.GetServerId()), true
+	case *serverpb.RaftSnapshotRequest:
+		return nil, nil, true
+	case *serverpb.UserAddRequest:
+		return nil, []byte(r.Name), true
+	case *serverpb.UserDeleteRequest:
+		return nil, []byte(r.Name), true
+	case *serverpb.UserChangePasswordRequest:
+		return nil, []byte(r.Name), true
+	case *serverpb.UserGrantRoleRequest:
+		return nil, []byte(r.User), true
+	case *serverpb.UserRevokeRoleRequest:
+		return nil, []byte(r.User), true
+	case *serverpb.RoleAddRequest:
+		return nil, []byte(r.Name), true
+	case *serverpb.RoleDeleteRequest:
+		return nil, []byte(r.Name), true
+	case *serverpb.RoleGrantPermissionRequest:
+		return nil, []byte(r.Role), true
+	case *serverpb.RoleRevokePermissionRequest:
+		return nil, []byte(r.Role), true
+	default:
+		return nil, nil, false
+	}
+}
+
+// Unary records the audited requests, it runs after the authentication so that the user is known
+// and before the authorization so that the denied requests are recorded too
+func (a *Auditor) Unary(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (any, error) {
+	namespace, key, ok := auditRPC(req)
+	if !ok {
+		return handler(ctx, req)
+	}
+
+	resp, err := handler(ctx, req)
+
+	record := &AuditRecord{
+		Protocol:  "grpc",
+		Method:    info.FullMethod,
+		Namespace: namespace,
+		Key:       a.Key(key),
+		Result:    status.Code(err).String(),
+	}
+	record.User, _ = grpcutil.UserFromContext(ctx)
+	if p, ok := peer.FromContext(ctx); ok {
+		record.Remote = p.Addr.String()
+	}
+	if err != nil {
+		record.Error = status.Convert(err).Message()
+	}
+	a.Record(record)
+	return resp, err
+}
+
+// statusRecorder keeps the status code of a http response
+type statusRecorder struct {
+	http.ResponseWriter
+	code int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	r.code = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+// Handler records the requests of next, scope reads their namespace and key
+func (a *Auditor) Handler(scope httpScope, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var (
+			recorder = &statusRecorder{ResponseWriter: w, code: http.StatusOK}
+			record   = &AuditRecord{
+				Protocol: "http",
+				Method:   r.Method + " " + r.URL.Path,
+				Remote:   r.RemoteAddr,
+			}
+		)
+		record.User, _ = httputil.UserFromContext(r.Context())
+		if scope != nil {
+			namespace, key, err := scope(r)
+			if err == nil {
+				record.Namespace, record.Key = namespace, a.Key(key)
+			}
+		}
+
+		next.ServeHTTP(recorder, r)
+
+		record.Result = http.StatusText(recorder.code)
+		a.Record(record)
+	})
+}
+
+// serverScope reads the server id of a membership change from the json body, the body is kept
+// for the handler
+func serverScope(r *http.Request) (*string, []byte, error) {
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	r.Body = io.NopCloser(bytes.NewReader(b))
+
+	var req struct {
+		ServerId string `json:"server_id"`
+	}
+	if err = httputil.BindJSON(&req, bytes.NewReader(b)); err != nil {
+		return nil, nil, err
+	}
+	return nil, []byte(req.ServerId), nil
+}
+
+// audited records the requests of the route once the audit is enabled
+func (s *v1HttpServer) audited(scope httpScope, next http.Handler) http.Handler {
+	if s.audit == nil {
+		return next
+	}
+	return s.audit.Handler(scope, next)
+}
+
+func NewAuditor(cfg config.Audit, dataDir, server string, logger hclog.Logger, applyFC AuditApplyFunc) (*Auditor, error) {
+	path := expr.OrDefault(cfg.File, config.DefaultAuditFile)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dataDir, path)
+	}
+	w, err := fs.OpenRotateFile(path, cfg.MaxSize<<20, int(cfg.MaxBackups))
+	if err != nil {
+		return nil, err
+	}
+
+	if logger == nil {
+		logger = hclog.Default()
+	}
+	return &Auditor{
+		server:   server,
+		hashKeys: cfg.HashKeys,
+		logger:   logger.Named("audit"),
+		w:        w,
+		applyFC:  applyFC,
+		queue:    make(chan *AuditRecord, auditQueueSize),
+	}, nil
+}
+
+// auditAutopilot records the membership changes made by the autopilot
+func (s *Server) auditAutopilot(action string, id raft.ServerID, err error) {
+	record := &AuditRecord{
+		Protocol: "autopilot",
+		Method:   action,
+		Key:      s.audit.Key([]byte(id)),
+		Result:   status.Code(err).String(),
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+	s.audit.Record(record)
+}
+
+// replicateAudit implements the AuditApplyFunc, only the leader replicates its records. they
+// expire once the retention passes
+func (s *Server) replicateAudit(ctx context.Context, key, record []byte) error {
+	if s.raft.State() != raft.Leader {
+		return raft.ErrNotLeader
+	}
+	retention := expr.OrDefault(s.cfg.Audit.Retention, config.DefaultAuditRetention)
+	return s.applyLog(ctx, &serverpb.RaftLogPayload{
+		Command:   serverpb.RaftLogCommand_SetWithTTL,
+		Key:       key,
+		Value:     record,
+		Ttl:       expr.Pointer(uint32(retention / time.Second)),
+		Namespace: expr.Pointer(AuditNamespace),
+	}, 500*time.Millisecond)
+}
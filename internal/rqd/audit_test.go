@@ -0,0 +1,148 @@
+package rqd_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/RealFax/RedQueen/api/serverpb"
+	red "github.com/RealFax/RedQueen/internal/rqd"
+	"github.com/RealFax/RedQueen/internal/rqd/config"
+	"github.com/RealFax/RedQueen/pkg/grpcutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func readAuditRecords(t *testing.T, path string) []red.AuditRecord {
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var records []red.AuditRecord
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var record red.AuditRecord
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		records = append(records, record)
+	}
+	require.NoError(t, scanner.Err())
+	return records
+}
+
+func TestAuditor_Unary(t *testing.T) {
+	dir := t.TempDir()
+	auditor, err := red.NewAuditor(config.Audit{}, dir, "node-1", nil, nil)
+	require.NoError(t, err)
+
+	var (
+		ctx     = grpcutil.ContextWithUser(context.Background(), "alice")
+		ok      = func(context.Context, any) (any, error) { return "ok", nil }
+		denied  = func(context.Context, any) (any, error) { return nil, status.Error(codes.PermissionDenied, "denied") }
+		setInfo = &grpc.UnaryServerInfo{FullMethod: "/serverpb.KV/Set"}
+	)
+
+	resp, err := auditor.Unary(ctx, &serverpb.SetRequest{Key: []byte("key")}, setInfo, ok)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", resp)
+	_, err = auditor.Unary(ctx, &serverpb.LockRequest{LockId: "lock"}, &grpc.UnaryServerInfo{FullMethod: "/serverpb.Locker/Lock"}, denied)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	// reads aren't audited
+	_, err = auditor.Unary(ctx, &serverpb.GetRequest{Key: []byte("key")}, &grpc.UnaryServerInfo{FullMethod: "/serverpb.KV/Get"}, ok)
+	require.NoError(t, err)
+	require.NoError(t, auditor.Close())
+
+	records := readAuditRecords(t, filepath.Join(dir, config.DefaultAuditFile))
+	require.Len(t, records, 2)
+
+	assert.Equal(t, "node-1", records[0].Server)
+	assert.Equal(t, "alice", records[0].User)
+	assert.Equal(t, "grpc", records[0].Protocol)
+	assert.Equal(t, "/serverpb.KV/Set", records[0].Method)
+	require.NotNil(t, records[0].Namespace)
+	assert.Equal(t, "", *records[0].Namespace)
+	assert.Equal(t, "key", records[0].Key)
+	assert.Equal(t, codes.OK.String(), records[0].Result)
+
+	assert.Nil(t, records[1].Namespace)
+	assert.Equal(t, "lock", records[1].Key)
+	assert.Equal(t, codes.PermissionDenied.String(), records[1].Result)
+	assert.Equal(t, "denied", records[1].Error)
+}
+
+func TestAuditor_Handler(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	auditor, err := red.NewAuditor(config.Audit{File: path, HashKeys: true}, "", "node-1", nil, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "sha256:2c70e12b7a0646f92279f427c7b38e7334d8e5389cff167a1dc30e73f826b683", auditor.Key([]byte("key")))
+
+	namespace := "tenant"
+	handler := auditor.Handler(func(*http.Request) (*string, []byte, error) {
+		return &namespace, []byte("key"), nil
+	}, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPut, "/action/tenant", nil))
+	require.NoError(t, auditor.Close())
+
+	records := readAuditRecords(t, path)
+	require.Len(t, records, 1)
+	assert.Equal(t, "http", records[0].Protocol)
+	assert.Equal(t, "PUT /action/tenant", records[0].Method)
+	assert.Equal(t, "tenant", *records[0].Namespace)
+	assert.Equal(t, auditor.Key([]byte("key")), records[0].Key)
+	assert.Equal(t, http.StatusText(http.StatusForbidden), records[0].Result)
+}
+
+func TestAuditor_Replicate(t *testing.T) {
+	replicated := make(chan red.AuditRecord, 1)
+	auditor, err := red.NewAuditor(config.Audit{}, t.TempDir(), "node-1", nil, func(_ context.Context, key, b []byte) error {
+		var record red.AuditRecord
+		if err := json.Unmarshal(b, &record); err != nil {
+			return err
+		}
+		assert.Contains(t, string(key), "-node-1")
+		replicated <- record
+		return nil
+	})
+	require.NoError(t, err)
+	defer auditor.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go auditor.Run(ctx)
+
+	auditor.Record(&red.AuditRecord{Protocol: "autopilot", Method: "remove", Key: "node-2", Result: codes.OK.String()})
+	record := <-replicated
+	assert.Equal(t, "remove", record.Method)
+	assert.Equal(t, "node-1", record.Server)
+}
+
+func TestAuditor_Flush(t *testing.T) {
+	var replicated []string
+	auditor, err := red.NewAuditor(config.Audit{}, t.TempDir(), "node-1", nil, func(_ context.Context, _, b []byte) error {
+		var record red.AuditRecord
+		if err := json.Unmarshal(b, &record); err != nil {
+			return err
+		}
+		replicated = append(replicated, record.Method)
+		return nil
+	})
+	require.NoError(t, err)
+	defer auditor.Close()
+
+	// the records queued before the shutdown are replicated without Run
+	for _, method := range []string{"promote", "remove"} {
+		auditor.Record(&red.AuditRecord{Protocol: "autopilot", Method: method, Result: codes.OK.String()})
+	}
+	auditor.Flush(context.Background())
+	assert.Equal(t, []string{"promote", "remove"}, replicated)
+}
@@ -0,0 +1,234 @@
+package rqd
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/RealFax/RedQueen/internal/rqd/config"
+	"github.com/RealFax/RedQueen/pkg/expr"
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/raft"
+)
+
+// serverHealth is the autopilot view of a follower
+type serverHealth struct {
+	healthy bool
+	// since is when healthy last changed
+	since time.Time
+	// failedSince is when the follower went out of contact, zero while it is in contact
+	failedSince time.Time
+}
+
+// Autopilot runs on the leader. a follower is healthy while it is in contact and caught up,
+// promotable learners healthy for the stabilization time are promoted and servers out of contact
+// for the grace period are removed (or demoted)
+type Autopilot struct {
+	raft   *Raft
+	logger hclog.Logger
+
+	interval             time.Duration
+	lastContactThreshold time.Duration
+	maxTrailingLogs      uint64
+	stabilizationTime    time.Duration
+	gracePeriod          time.Duration
+	deadServerAction     config.EnumAutopilotDeadServerAction
+	promotion            bool
+
+	mu     sync.RWMutex
+	health map[raft.ServerID]serverHealth
+
+	onChange   func(action string, id raft.ServerID, err error)
+	promotable func(id raft.ServerID) bool
+}
+
+// OnChange calls fc after each membership change attempted by the autopilot, action is promote,
+// remove or demote
+func (a *Autopilot) OnChange(fc func(action string, id raft.ServerID, err error)) {
+	a.onChange = fc
+}
+
+// Promotable sets the filter of the learners to promote, no learner is promoted without it. the
+// learners added on purpose must stay learners
+func (a *Autopilot) Promotable(fc func(id raft.ServerID) bool) {
+	a.promotable = fc
+}
+
+func (a *Autopilot) changed(action string, id raft.ServerID, err error) {
+	if a.onChange != nil {
+		a.onChange(action, id, err)
+	}
+}
+
+// Healthy reports the health of the follower id, ok is false when it isn't tracked, e.g. on a
+// follower or for the leader itself
+func (a *Autopilot) Healthy(id raft.ServerID) (healthy bool, ok bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	h, ok := a.health[id]
+	return h.healthy, ok
+}
+
+func (a *Autopilot) Run(ctx context.Context) {
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if a.raft.State() != raft.Leader {
+				// health is tracked from scratch once the leadership is gained
+				a.mu.Lock()
+				clear(a.health)
+				a.mu.Unlock()
+				continue
+			}
+			a.reconcile(now)
+		}
+	}
+}
+
+func (a *Autopilot) reconcile(now time.Time) {
+	members, err := a.raft.Members()
+	if err != nil {
+		a.logger.Warn("failed to get members", "error", err)
+		return
+	}
+
+	var (
+		lastIndex = a.raft.LastIndex()
+		health    = make(map[raft.ServerID]serverHealth, len(members))
+	)
+	a.mu.RLock()
+	for _, member := range members {
+		if member.ID == a.raft.cfg.LocalID {
+			continue
+		}
+
+		var (
+			inContact = !member.LastContact.IsZero() && now.Sub(member.LastContact) <= a.lastContactThreshold
+			caughtUp  = member.LastLogIndex >= lastIndex || lastIndex-member.LastLogIndex <= a.maxTrailingLogs
+			prev, ok  = a.health[member.ID]
+			h         = prev
+		)
+		if !ok || prev.healthy != (inContact && caughtUp) {
+			h.healthy, h.since = inContact && caughtUp, now
+		}
+		switch {
+		case inContact:
+			h.failedSince = time.Time{}
+		case !ok || prev.failedSince.IsZero():
+			// servers that never answered are counted from when they are first seen
+			h.failedSince = now
+		}
+		health[member.ID] = h
+	}
+	a.mu.RUnlock()
+
+	a.mu.Lock()
+	a.health = health
+	a.mu.Unlock()
+
+	a.promoteLearners(now, members, health)
+	a.handleDeadServers(now, members, health)
+}
+
+func (a *Autopilot) promoteLearners(now time.Time, members []Member, health map[raft.ServerID]serverHealth) {
+	if !a.promotion || a.promotable == nil {
+		return
+	}
+	for _, member := range members {
+		h, ok := health[member.ID]
+		if !ok || member.Suffrage != raft.Nonvoter || !h.healthy || now.Sub(h.since) < a.stabilizationTime {
+			continue
+		}
+		if !a.promotable(member.ID) {
+			continue
+		}
+		err := a.raft.PromoteCluster(member.ID)
+		a.changed("promote", member.ID, err)
+		if err != nil {
+			a.logger.Warn("failed to promote learner", "id", member.ID, "error", err)
+			continue
+		}
+		a.logger.Info("promoted learner", "id", member.ID)
+	}
+}
+
+func (a *Autopilot) handleDeadServers(now time.Time, members []Member, health map[raft.ServerID]serverHealth) {
+	if a.deadServerAction == config.AutopilotDeadServerNone {
+		return
+	}
+
+	var (
+		voters int
+		dead   []Member
+	)
+	for _, member := range members {
+		if member.Suffrage == raft.Voter {
+			voters++
+		}
+		h, ok := health[member.ID]
+		if ok && !h.failedSince.IsZero() && now.Sub(h.failedSince) >= a.gracePeriod {
+			dead = append(dead, member)
+		}
+	}
+
+	var deadVoters int
+	for _, member := range dead {
+		if member.Suffrage == raft.Voter {
+			deadVoters++
+		}
+	}
+	// a partitioned leader sees most voters as dead, leave the voters alone unless the live
+	// ones are still a majority
+	votersSafe := voters-deadVoters > voters/2
+	if deadVoters != 0 && !votersSafe {
+		a.logger.Warn("refusing to handle dead voters, the live voters aren't a majority", "voters", voters, "dead", deadVoters)
+	}
+
+	for _, member := range dead {
+		var (
+			err    error
+			action string
+		)
+		switch {
+		case member.Suffrage == raft.Voter && !votersSafe:
+			continue
+		case a.deadServerAction == config.AutopilotDeadServerRemove:
+			action, err = "remove", a.raft.RemoveCluster(member.ID)
+		case member.Suffrage == raft.Voter:
+			action, err = "demote", a.raft.DemoteCluster(member.ID)
+		default:
+			// dead learners are kept when demoting
+			continue
+		}
+		a.changed(action, member.ID, err)
+		if err != nil {
+			a.logger.Warn("failed to handle dead server", "id", member.ID, "action", a.deadServerAction, "error", err)
+			continue
+		}
+		a.logger.Info("handled dead server", "id", member.ID, "action", a.deadServerAction)
+	}
+}
+
+func NewAutopilot(r *Raft, cfg config.Autopilot) *Autopilot {
+	logger := r.cfg.Logger
+	if logger == nil {
+		logger = hclog.Default()
+	}
+	return &Autopilot{
+		raft:                 r,
+		logger:               logger.Named("autopilot"),
+		interval:             expr.OrDefault(cfg.Interval, config.DefaultAutopilotInterval),
+		lastContactThreshold: expr.OrDefault(cfg.LastContactThreshold, config.DefaultAutopilotLastContactThreshold),
+		maxTrailingLogs:      expr.OrDefault(cfg.MaxTrailingLogs, config.DefaultAutopilotMaxTrailingLogs),
+		stabilizationTime:    expr.OrDefault(cfg.ServerStabilizationTime, config.DefaultAutopilotServerStabilizationTime),
+		gracePeriod:          expr.OrDefault(cfg.DeadServerGracePeriod, config.DefaultAutopilotDeadServerGracePeriod),
+		deadServerAction:     expr.OrDefault(cfg.DeadServerAction, config.EnumAutopilotDeadServerAction(config.DefaultAutopilotDeadServerAction)),
+		promotion:            !cfg.DisablePromotion,
+		health:               make(map[raft.ServerID]serverHealth),
+	}
+}
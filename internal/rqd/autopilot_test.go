@@ -0,0 +1,110 @@
+package rqd_test
+
+import (
+	"context"
+	red "github.com/RealFax/RedQueen/internal/rqd"
+	"github.com/RealFax/RedQueen/internal/rqd/config"
+	"github.com/hashicorp/raft"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func runTestAutopilot(t *testing.T, r *red.Raft, cfg config.Autopilot, promotable func(raft.ServerID) bool) *red.Autopilot {
+	cfg.Enabled = true
+	cfg.Interval = 10 * time.Millisecond
+	cfg.LastContactThreshold = 100 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	autopilot := red.NewAutopilot(r, cfg)
+	autopilot.Promotable(promotable)
+	go autopilot.Run(ctx)
+	return autopilot
+}
+
+func TestAutopilot_PromoteLearner(t *testing.T) {
+	nodes := newTestCluster(t, 2)
+	autopilot := runTestAutopilot(t, nodes[0], config.Autopilot{ServerStabilizationTime: 100 * time.Millisecond}, func(id raft.ServerID) bool {
+		return id == raft.ServerID(nodeID(1))
+	})
+
+	require.NoError(t, nodes[0].AddCluster(raft.ServerID(nodeID(1)), raft.ServerAddress(nodeID(1)), false))
+	require.Eventually(t, func() bool {
+		s, ok := suffrage(t, nodes[0], nodeID(1))
+		return ok && s == raft.Voter
+	}, 5*time.Second, 10*time.Millisecond)
+
+	healthy, ok := autopilot.Healthy(raft.ServerID(nodeID(1)))
+	require.True(t, ok)
+	require.True(t, healthy)
+}
+
+func TestAutopilot_UnmarkedLearner(t *testing.T) {
+	nodes := newTestCluster(t, 2)
+	autopilot := runTestAutopilot(t, nodes[0], config.Autopilot{ServerStabilizationTime: 10 * time.Millisecond}, func(raft.ServerID) bool {
+		return false
+	})
+
+	// the learners added on purpose are never promoted
+	require.NoError(t, nodes[0].AddCluster(raft.ServerID(nodeID(1)), raft.ServerAddress(nodeID(1)), false))
+	require.Eventually(t, func() bool {
+		healthy, _ := autopilot.Healthy(raft.ServerID(nodeID(1)))
+		return healthy
+	}, 5*time.Second, 10*time.Millisecond)
+
+	time.Sleep(100 * time.Millisecond)
+	s, _ := suffrage(t, nodes[0], nodeID(1))
+	require.Equal(t, raft.Nonvoter, s)
+}
+
+func TestAutopilot_DisablePromotion(t *testing.T) {
+	nodes := newTestCluster(t, 2)
+	autopilot := runTestAutopilot(t, nodes[0], config.Autopilot{ServerStabilizationTime: 10 * time.Millisecond, DisablePromotion: true}, nil)
+
+	require.NoError(t, nodes[0].AddCluster(raft.ServerID(nodeID(1)), raft.ServerAddress(nodeID(1)), false))
+	require.Eventually(t, func() bool {
+		healthy, _ := autopilot.Healthy(raft.ServerID(nodeID(1)))
+		return healthy
+	}, 5*time.Second, 10*time.Millisecond)
+
+	time.Sleep(100 * time.Millisecond)
+	s, _ := suffrage(t, nodes[0], nodeID(1))
+	require.Equal(t, raft.Nonvoter, s)
+}
+
+func TestAutopilot_DeadServer(t *testing.T) {
+	for _, tc := range []struct {
+		action config.EnumAutopilotDeadServerAction
+		expect func(s raft.ServerSuffrage, ok bool) bool
+	}{
+		{config.AutopilotDeadServerRemove, func(_ raft.ServerSuffrage, ok bool) bool { return !ok }},
+		{config.AutopilotDeadServerDemote, func(s raft.ServerSuffrage, ok bool) bool { return ok && s == raft.Nonvoter }},
+	} {
+		t.Run(string(tc.action), func(t *testing.T) {
+			nodes := newTestCluster(t, 3)
+			for i := 1; i < len(nodes); i++ {
+				require.NoError(t, nodes[0].AddCluster(raft.ServerID(nodeID(i)), raft.ServerAddress(nodeID(i)), true))
+			}
+			autopilot := runTestAutopilot(t, nodes[0], config.Autopilot{
+				DeadServerGracePeriod: 300 * time.Millisecond,
+				DeadServerAction:      tc.action,
+			}, nil)
+
+			require.NoError(t, nodes[2].Shutdown().Error())
+			require.Eventually(t, func() bool {
+				healthy, ok := autopilot.Healthy(raft.ServerID(nodeID(2)))
+				return ok && !healthy
+			}, 5*time.Second, 10*time.Millisecond)
+			require.Eventually(t, func() bool {
+				return tc.expect(suffrage(t, nodes[0], nodeID(2)))
+			}, 5*time.Second, 10*time.Millisecond)
+
+			// the live voter is kept
+			s, ok := suffrage(t, nodes[0], nodeID(1))
+			require.True(t, ok)
+			require.Equal(t, raft.Voter, s)
+		})
+	}
+}
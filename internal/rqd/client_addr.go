@@ -0,0 +1,73 @@
+package rqd
+
+import (
+	"context"
+	"time"
+
+	"github.com/RealFax/RedQueen/api/serverpb"
+	"github.com/RealFax/RedQueen/internal/rqd/store"
+	"github.com/RealFax/RedQueen/pkg/expr"
+	"github.com/hashicorp/raft"
+	"github.com/pkg/errors"
+)
+
+// ClientAddrNamespace holds the advertised grpc address of each member, keyed by the server id.
+// a member publishes it when it joins and whenever it gains the leadership, it's deleted once the
+// member is removed
+const ClientAddrNamespace = "_ClientAddr"
+
+func init() {
+	store.ReserveNamespace(ClientAddrNamespace)
+}
+
+// LoadClientAddr returns the published grpc address of the server id, empty when unknown
+func LoadClientAddr(s store.Store, id raft.ServerID) (string, error) {
+	actions, err := s.Swap(ClientAddrNamespace)
+	if err != nil {
+		return "", err
+	}
+
+	val, err := actions.Get([]byte(id))
+	if err != nil {
+		if errors.Is(err, store.ErrKeyNotFound) {
+			return "", nil
+		}
+		return "", err
+	}
+	return string(val.Data), nil
+}
+
+// publishClientAddr replicates the grpc address of the server id, unchanged addresses are skipped
+func (s *Server) publishClientAddr(ctx context.Context, id raft.ServerID, addr string) error {
+	if prev, err := LoadClientAddr(s.store, id); err == nil && prev == addr {
+		return nil
+	}
+	return s.applyLog(ctx, &serverpb.RaftLogPayload{
+		Command:   serverpb.RaftLogCommand_Set,
+		Key:       []byte(id),
+		Value:     []byte(addr),
+		Namespace: expr.Pointer(ClientAddrNamespace),
+	}, 500*time.Millisecond)
+}
+
+// forgetClientAddr deletes the grpc address of the removed server id
+func (s *Server) forgetClientAddr(ctx context.Context, id raft.ServerID) error {
+	if prev, err := LoadClientAddr(s.store, id); err == nil && prev == "" {
+		return nil
+	}
+	return s.applyLog(ctx, &serverpb.RaftLogPayload{
+		Command:   serverpb.RaftLogCommand_Del,
+		Key:       []byte(id),
+		Namespace: expr.Pointer(ClientAddrNamespace),
+	}, 500*time.Millisecond)
+}
+
+// publishLeaderClientAddr publishes the local grpc address once the leadership is gained
+func (s *Server) publishLeaderClientAddr() {
+	if err := s.raft.Barrier(membershipTimeout).Error(); err != nil {
+		return
+	}
+	if err := s.publishClientAddr(s.ctx, raft.ServerID(s.cfg.Node.ID), s.cfg.Node.ClientAddr()); err != nil {
+		s.raft.cfg.Logger.Warn("failed to publish client address", "error", err)
+	}
+}
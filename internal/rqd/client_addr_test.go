@@ -0,0 +1,25 @@
+package rqd_test
+
+import (
+	red "github.com/RealFax/RedQueen/internal/rqd"
+	"github.com/RealFax/RedQueen/internal/rqd/store"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestLoadClientAddr(t *testing.T) {
+	db := newTestStore(t)
+	require.True(t, store.IsReservedNamespace(red.ClientAddrNamespace))
+
+	addr, err := red.LoadClientAddr(db, "node-1")
+	require.NoError(t, err)
+	require.Empty(t, addr)
+
+	actions, err := db.Swap(red.ClientAddrNamespace)
+	require.NoError(t, err)
+	require.NoError(t, actions.Set([]byte("node-1"), []byte("10.0.0.1:5230")))
+
+	addr, err = red.LoadClientAddr(db, "node-1")
+	require.NoError(t, err)
+	require.Equal(t, "10.0.0.1:5230", addr)
+}
@@ -8,11 +8,16 @@ import (
 	"github.com/pkg/errors"
 	"os"
 	"path"
+	"time"
 )
 
 type ServerEnv interface {
 	FirstRun() bool
 	ConfigFile() string
+	// Recovering reports whether the node forces a new cluster before it starts (recover subcommand)
+	Recovering() bool
+	// RecoverSnapshot is the snapshot file the recovered state is read from, empty means the data dir
+	RecoverSnapshot() string
 }
 
 type env struct {
@@ -20,6 +25,9 @@ type env struct {
 	initLockFile string
 
 	configFile string
+
+	recovering      bool
+	recoverSnapshot string
 }
 
 func (r *env) FirstRun() bool {
@@ -30,21 +38,71 @@ func (r *env) ConfigFile() string {
 	return r.configFile
 }
 
+func (r *env) Recovering() bool {
+	return r.recovering
+}
+
+func (r *env) RecoverSnapshot() string {
+	return r.recoverSnapshot
+}
+
 type NodeTLS struct {
 	Auto     bool   `toml:"auto"`
 	CertFile string `toml:"cert-file"`
 	KeyFile  string `toml:"key-file"`
+	// ClientCAFile is the ca bundle the client certificates are verified with
+	ClientCAFile string `toml:"client-ca-file"`
+	// ClientAuth is how client certificates are asked for, a verified certificate authenticates
+	// the client by its common name (or its first SAN)
+	ClientAuth EnumClientAuth `toml:"client-auth"`
+}
+
+// NodePeerTLS secures the raft transport with mutual tls. each peer presents a certificate signed
+// by the ca that carries its node id as a DNS SAN
+type NodePeerTLS struct {
+	CertFile string `toml:"cert-file"`
+	KeyFile  string `toml:"key-file"`
+	CAFile   string `toml:"ca-file"`
+}
+
+// Enabled reports whether the raft transport uses mutual tls
+func (t NodePeerTLS) Enabled() bool {
+	return t.CertFile != ""
 }
 
 type Node struct {
-	ID               string  `toml:"id"`
-	DataDir          string  `toml:"data-dir"`
-	ListenPeerAddr   string  `toml:"listen-peer-addr"`
-	ListenClientAddr string  `toml:"listen-client-addr"`
-	ListenHttpAddr   string  `toml:"listen-http-addr"`
-	MaxSnapshots     uint32  `toml:"max-snapshots"`
-	RequestsMerged   bool    `toml:"requests-merged"`
-	TLS              NodeTLS `toml:"tls"`
+	ID               string `toml:"id"`
+	DataDir          string `toml:"data-dir"`
+	ListenPeerAddr   string `toml:"listen-peer-addr"`
+	ListenClientAddr string `toml:"listen-client-addr"`
+	ListenHttpAddr   string `toml:"listen-http-addr"`
+	// AdvertisePeerAddr is the raft address other members reach this node at, when it differs
+	// from the bind address (e.g. behind NAT)
+	AdvertisePeerAddr string `toml:"advertise-peer-addr"`
+	// AdvertiseClientAddr is the grpc address clients reach this node at
+	AdvertiseClientAddr string `toml:"advertise-client-addr"`
+	MaxSnapshots        uint32 `toml:"max-snapshots"`
+	RequestsMerged      bool   `toml:"requests-merged"`
+	// ShutdownTimeout bounds the draining of the in-flight requests on SIGTERM
+	ShutdownTimeout time.Duration `toml:"shutdown-timeout"`
+	TLS             NodeTLS       `toml:"tls"`
+	PeerTLS         NodePeerTLS   `toml:"peer-tls"`
+}
+
+// PeerAddr returns the advertised raft address
+func (n Node) PeerAddr() string {
+	if n.AdvertisePeerAddr != "" {
+		return n.AdvertisePeerAddr
+	}
+	return n.ListenPeerAddr
+}
+
+// ClientAddr returns the advertised grpc address
+func (n Node) ClientAddr() string {
+	if n.AdvertiseClientAddr != "" {
+		return n.AdvertiseClientAddr
+	}
+	return n.ListenClientAddr
 }
 
 type StoreNuts struct {
@@ -54,9 +112,61 @@ type StoreNuts struct {
 	RWMode     EnumNutsRWMode `toml:"rw-mode"`
 }
 
+type StoreHistory struct {
+	MaxVersions uint32            `toml:"max-versions"`
+	Namespaces  map[string]uint32 `toml:"namespaces"`
+}
+
+// StoreEncryption seals the stored values and the snapshots with AES-GCM, every member needs
+// the keys the others seal with
+type StoreEncryption struct {
+	// KeyFile holds a base64 key
+	KeyFile string `toml:"key-file"`
+	// Keyring holds one base64 key per line, the first one seals and the others only open the
+	// values sealed before a rotation
+	Keyring string `toml:"keyring"`
+}
+
 type Store struct {
 	Backend EnumStoreBackend `toml:"backend"`
-	Nuts    StoreNuts        `toml:"nuts"`
+	// StrictSnapshot rejects the snapshots without manifest of older releases, they are
+	// installed without checksums and with a warning otherwise
+	StrictSnapshot bool            `toml:"strict-snapshot"`
+	Nuts           StoreNuts       `toml:"nuts"`
+	History        StoreHistory    `toml:"history"`
+	Encryption     StoreEncryption `toml:"encryption"`
+}
+
+// Raft tunes the raft node, 0 means the raft default
+type Raft struct {
+	HeartbeatTimeout   time.Duration    `toml:"heartbeat-timeout"`
+	ElectionTimeout    time.Duration    `toml:"election-timeout"`
+	LeaderLeaseTimeout time.Duration    `toml:"leader-lease-timeout"`
+	CommitTimeout      time.Duration    `toml:"commit-timeout"`
+	SnapshotInterval   time.Duration    `toml:"snapshot-interval"`
+	SnapshotThreshold  uint64           `toml:"snapshot-threshold"`
+	TrailingLogs       uint64           `toml:"trailing-logs"`
+	MaxAppendEntries   uint32           `toml:"max-append-entries"`
+	TransportMaxPool   uint32           `toml:"transport-max-pool"`
+	TransportTimeout   time.Duration    `toml:"transport-timeout"`
+	LogLevel           EnumRaftLogLevel `toml:"log-level"`
+}
+
+// Autopilot watches the followers on the leader, it removes (or demotes) failed servers and
+// promotes caught up learners. 0 means the default
+type Autopilot struct {
+	Enabled  bool          `toml:"enabled"`
+	Interval time.Duration `toml:"interval"`
+	// LastContactThreshold is how long a follower may go without contact before it is unhealthy
+	LastContactThreshold time.Duration `toml:"last-contact-threshold"`
+	// MaxTrailingLogs is how far a follower may lag behind the leader before it is unhealthy
+	MaxTrailingLogs uint64 `toml:"max-trailing-logs"`
+	// ServerStabilizationTime is how long a learner stays healthy before it is promoted
+	ServerStabilizationTime time.Duration `toml:"server-stabilization-time"`
+	// DeadServerGracePeriod is how long a server stays out of contact before the DeadServerAction
+	DeadServerGracePeriod time.Duration                 `toml:"dead-server-grace-period"`
+	DeadServerAction      EnumAutopilotDeadServerAction `toml:"dead-server-action"`
+	DisablePromotion      bool                          `toml:"disable-promotion"`
 }
 
 type ClusterBootstrap struct {
@@ -66,6 +176,16 @@ type ClusterBootstrap struct {
 
 type Cluster struct {
 	Bootstrap []ClusterBootstrap `toml:"bootstrap"`
+	// Join lists the client endpoints of existing members, a node that isn't a member yet asks
+	// them to add it until it is admitted
+	Join          []string `toml:"join"`
+	JoinAsLearner bool     `toml:"join-as-learner"`
+	// JoinAuth is the username:password used to join a cluster with basic auth
+	JoinAuth string `toml:"join-auth"`
+	// JoinCAFile is the ca bundle the members are verified with when joining, the client ca
+	// file of the node is used when it's empty
+	JoinCAFile        string        `toml:"join-ca-file"`
+	JoinRetryInterval time.Duration `toml:"join-retry-interval"`
 }
 
 type Misc struct {
@@ -74,13 +194,72 @@ type Misc struct {
 
 type BasicAuth map[string]string
 
+type Reserved struct {
+	// Admins are the basic-auth users allowed to access reserved namespaces
+	Admins []string `toml:"admins"`
+}
+
+// Rbac checks the permissions of the authenticated users against the grants of their roles, the
+// users and roles are replicated through raft and managed with the Auth service
+type Rbac struct {
+	Enabled bool `toml:"enabled"`
+	// Admins are the users holding every permission, e.g. to create the first roles
+	Admins []string `toml:"admins"`
+}
+
+// Token is the bearer token the Authenticate rpc exchanges the credentials for, it's signed with
+// a key replicated through raft and accepted by every member until it expires
+type Token struct {
+	TTL time.Duration `toml:"ttl"`
+}
+
+// Audit records who did what and when for the writes, locks, membership changes, snapshots and
+// auth changes, as json lines in a rotating file and optionally in a replicated namespace
+type Audit struct {
+	Enabled bool `toml:"enabled"`
+	// File is the json-lines file, relative to the data dir unless absolute
+	File string `toml:"file"`
+	// MaxSize is the size in MB the file is rotated at, 0 never rotates it
+	MaxSize    int64 `toml:"max-size"`
+	MaxBackups int64 `toml:"max-backups"`
+	// HashKeys records the sha256 of the keys (lock ids) instead of the keys
+	HashKeys bool `toml:"hash-keys"`
+	// Replicate appends the records of the leader to the reserved audit namespace too. the
+	// records of the followers are only written to their file
+	Replicate bool `toml:"replicate"`
+	// Retention is how long the replicated records are kept
+	Retention time.Duration `toml:"retention"`
+}
+
+// RateLimit throttles the clients with a token bucket per user, namespace or ip. the reads, writes,
+// watches and locks have their own rate (requests per second, 0 is unlimited) and burst (0 is the
+// rate)
+type RateLimit struct {
+	Key        EnumRateLimitKey `toml:"key"`
+	Read       int64            `toml:"read"`
+	ReadBurst  int64            `toml:"read-burst"`
+	Write      int64            `toml:"write"`
+	WriteBurst int64            `toml:"write-burst"`
+	Watch      int64            `toml:"watch"`
+	WatchBurst int64            `toml:"watch-burst"`
+	Lock       int64            `toml:"lock"`
+	LockBurst  int64            `toml:"lock-burst"`
+}
+
 type Config struct {
 	*env
 	Node      `toml:"node"`
 	Store     `toml:"store"`
+	Raft      `toml:"raft"`
+	Autopilot `toml:"autopilot"`
 	Cluster   `toml:"cluster"`
 	Misc      `toml:"misc"`
 	BasicAuth `toml:"basic-auth"`
+	Reserved  `toml:"reserved"`
+	Rbac      `toml:"rbac"`
+	Token     `toml:"token"`
+	Audit     `toml:"audit"`
+	RateLimit `toml:"rate-limit"`
 }
 
 func (c *Config) setupEnv() {
@@ -113,11 +292,24 @@ func bindServerFromArgs(cfg *Config, args ...string) error {
 	if len(args) < 1 {
 		return errors.New("invalid program args")
 	}
+	return newServerFlagSet(cfg, "server", serverUsage).Parse(args)
+}
+
+// bindRecoverFromArgs binds the options of the server, the recovered node starts as a server.
+// the args can be empty, the config is then read from the env or the config file
+func bindRecoverFromArgs(cfg *Config, args ...string) error {
+	f := newServerFlagSet(cfg, "recover", recoverUsage)
+	f.StringVar(&cfg.env.recoverSnapshot, "snapshot", "", "snapshot file to recover from, it requires a data dir without raft state. if it is empty, the data dir is recovered")
+	cfg.env.recovering = true
 
-	f := flag.NewFlagSet("server", flag.ExitOnError)
+	return f.Parse(args)
+}
+
+func newServerFlagSet(cfg *Config, name, usage string) *flag.FlagSet {
+	f := flag.NewFlagSet(name, flag.ExitOnError)
 
 	f.Usage = func() {
-		fmt.Fprint(f.Output(), serverUsage)
+		fmt.Fprint(f.Output(), usage)
 		f.PrintDefaults()
 	}
 
@@ -129,16 +321,27 @@ func bindServerFromArgs(cfg *Config, args ...string) error {
 	f.StringVar(&cfg.Node.ListenPeerAddr, "listen-peer-addr", DefaultNodeListenPeerAddr, "address to raft listen")
 	f.StringVar(&cfg.Node.ListenClientAddr, "listen-client-addr", DefaultNodeListenClientAddr, "address to grpc listen")
 	f.StringVar(&cfg.Node.ListenHttpAddr, "listen-http-addr", "", "address to http listen, if it is empty, it means that http server is not used.")
+	f.StringVar(&cfg.Node.AdvertisePeerAddr, "advertise-peer-addr", "", "raft address advertised to other members, defaults to listen-peer-addr")
+	f.StringVar(&cfg.Node.AdvertiseClientAddr, "advertise-client-addr", "", "grpc address advertised to clients, defaults to listen-client-addr")
 	f.Var(newUInt32Value(DefaultNodeMaxSnapshots, &cfg.Node.MaxSnapshots), "max-snapshots", "max number to snapshots(raft)")
 	f.BoolVar(&cfg.Node.RequestsMerged, "requests-merged", DefaultNodeRequestsMerged, "enable raft apply log requests merged")
+	f.DurationVar(&cfg.Node.ShutdownTimeout, "shutdown-timeout", DefaultNodeShutdownTimeout, "time to drain the in-flight requests on SIGTERM before closing them")
 
 	// main config::node::tls
 	f.BoolVar(&cfg.Node.TLS.Auto, "auto-tls", false, "auto generator tls")
 	f.StringVar(&cfg.Node.TLS.CertFile, "tls-cert-file", "", "tls certificate file")
 	f.StringVar(&cfg.Node.TLS.KeyFile, "tls-key-file", "", "tls key file")
+	f.StringVar(&cfg.Node.TLS.ClientCAFile, "tls-client-ca-file", "", "ca bundle the client certificates are verified with")
+	f.Var(newValidatorStringValue[EnumClientAuth](DefaultNodeTLSClientAuth, &cfg.Node.TLS.ClientAuth), "tls-client-auth", "client certificate mode, options: none, request, require, verify")
+
+	// main config::node::peer-tls
+	f.StringVar(&cfg.Node.PeerTLS.CertFile, "peer-tls-cert-file", "", "raft peer tls certificate file, its DNS SAN must be the node id")
+	f.StringVar(&cfg.Node.PeerTLS.KeyFile, "peer-tls-key-file", "", "raft peer tls key file")
+	f.StringVar(&cfg.Node.PeerTLS.CAFile, "peer-tls-ca-file", "", "ca bundle the raft peer certificates are verified with")
 
 	// main config::store
 	f.Var(newValidatorStringValue[EnumStoreBackend](DefaultStoreBackend, &cfg.Store.Backend), "store-backend", "")
+	f.BoolVar(&cfg.Store.StrictSnapshot, "store-strict-snapshot", false, "reject snapshots without manifest, they are installed without checksums otherwise")
 
 	// main config::store::nuts
 	f.Int64Var(&cfg.Store.Nuts.NodeNum, "nuts-node-num", DefaultStoreNutsNodeNum, "node-id in the system")
@@ -146,17 +349,84 @@ func bindServerFromArgs(cfg *Config, args ...string) error {
 	f.BoolVar(&cfg.Store.Nuts.StrictMode, "nuts-strict-mode", DefaultStoreNutsStrictMode, "enable strict mode")
 	f.Var(newValidatorStringValue[EnumNutsRWMode](DefaultStoreNutsRWMode, &cfg.Store.Nuts.RWMode), "nuts-rw-mode", "select read & write mode, options: fileio, mmap")
 
+	// main config::store::history
+	f.Var(newUInt32Value(DefaultStoreHistoryMaxVersions, &cfg.Store.History.MaxVersions), "history-max-versions", "number of versions kept for each key, 0 means disable history")
+
+	// main config::store::encryption
+	f.StringVar(&cfg.Store.Encryption.KeyFile, "store-encryption-key-file", "", "file of the base64 key the values and snapshots are encrypted with")
+	f.StringVar(&cfg.Store.Encryption.Keyring, "store-encryption-keyring", "", "file of base64 keys, one per line, the first one encrypts and the others decrypt")
+
+	// main config::raft
+	f.DurationVar(&cfg.Raft.HeartbeatTimeout, "raft-heartbeat-timeout", DefaultRaftHeartbeatTimeout, "time in follower state without contact from a leader before attempting an election")
+	f.DurationVar(&cfg.Raft.ElectionTimeout, "raft-election-timeout", DefaultRaftElectionTimeout, "time in candidate state without contact from a leader before attempting an election")
+	f.DurationVar(&cfg.Raft.LeaderLeaseTimeout, "raft-leader-lease-timeout", DefaultRaftLeaderLeaseTimeout, "time a leader stays leader without being able to contact a quorum")
+	f.DurationVar(&cfg.Raft.CommitTimeout, "raft-commit-timeout", DefaultRaftCommitTimeout, "time without an apply before sending an AppendEntry heartbeat")
+	f.DurationVar(&cfg.Raft.SnapshotInterval, "raft-snapshot-interval", DefaultRaftSnapshotInterval, "interval to check whether a snapshot should be taken")
+	f.Var(newUInt64Value(DefaultRaftSnapshotThreshold, &cfg.Raft.SnapshotThreshold), "raft-snapshot-threshold", "outstanding logs required to take a snapshot")
+	f.Var(newUInt64Value(DefaultRaftTrailingLogs, &cfg.Raft.TrailingLogs), "raft-trailing-logs", "logs kept after a snapshot for followers to catch up")
+	f.Var(newUInt32Value(DefaultRaftMaxAppendEntries, &cfg.Raft.MaxAppendEntries), "raft-max-append-entries", "max number of logs sent in one AppendEntries request")
+	f.Var(newUInt32Value(DefaultRaftTransportMaxPool, &cfg.Raft.TransportMaxPool), "raft-transport-max-pool", "max number of pooled connections per peer")
+	f.DurationVar(&cfg.Raft.TransportTimeout, "raft-transport-timeout", DefaultRaftTransportTimeout, "io deadline of the raft transport")
+	f.Var(newValidatorStringValue[EnumRaftLogLevel](DefaultRaftLogLevel, &cfg.Raft.LogLevel), "raft-log-level", "raft log level, options: trace, debug, info, warn, error, off")
+
+	// main config::autopilot
+	f.BoolVar(&cfg.Autopilot.Enabled, "autopilot", false, "enable autopilot on the leader")
+	f.DurationVar(&cfg.Autopilot.Interval, "autopilot-interval", DefaultAutopilotInterval, "interval of the autopilot checks")
+	f.DurationVar(&cfg.Autopilot.LastContactThreshold, "autopilot-last-contact-threshold", DefaultAutopilotLastContactThreshold, "time without contact before a follower is unhealthy")
+	f.Var(newUInt64Value(DefaultAutopilotMaxTrailingLogs, &cfg.Autopilot.MaxTrailingLogs), "autopilot-max-trailing-logs", "logs a follower may lag behind before it is unhealthy")
+	f.DurationVar(&cfg.Autopilot.ServerStabilizationTime, "autopilot-server-stabilization-time", DefaultAutopilotServerStabilizationTime, "time a learner stays healthy before it is promoted")
+	f.DurationVar(&cfg.Autopilot.DeadServerGracePeriod, "autopilot-dead-server-grace-period", DefaultAutopilotDeadServerGracePeriod, "time a server stays out of contact before the dead server action")
+	f.Var(newValidatorStringValue[EnumAutopilotDeadServerAction](DefaultAutopilotDeadServerAction, &cfg.Autopilot.DeadServerAction), "autopilot-dead-server-action", "action on dead servers, options: remove, demote, none")
+	f.BoolVar(&cfg.Autopilot.DisablePromotion, "autopilot-disable-promotion", false, "never promote learners")
+
 	// main config::cluster::bootstrap(s)
 	// in cli: node-1@peer_addr,node-2@peer_addr
 	f.Var(newClusterBootstrapsValue("", &cfg.Cluster.Bootstrap), "cluster-bootstrap", "bootstrap at cluster startup, e.g. : node-1@peer_addr,node-2@peer_addr")
 
+	// main config::cluster::join
+	f.Var(newStringSliceValue("", &cfg.Cluster.Join), "cluster-join", "client endpoints of existing members to join, e.g. : 127.0.0.1:5230,127.0.0.1:4230")
+	f.BoolVar(&cfg.Cluster.JoinAsLearner, "cluster-join-as-learner", false, "join the cluster as a learner")
+	f.StringVar(&cfg.Cluster.JoinAuth, "cluster-join-auth", "", "basic auth used to join the cluster, e.g. : root:toor")
+	f.StringVar(&cfg.Cluster.JoinCAFile, "cluster-join-ca-file", "", "ca bundle the members are verified with when joining")
+	f.DurationVar(&cfg.Cluster.JoinRetryInterval, "cluster-join-retry-interval", DefaultClusterJoinRetryInterval, "interval between join attempts")
+
 	// main config::misc
 	f.BoolVar(&cfg.Misc.PPROF, "d-pprof", false, "")
 
 	// main config::basic-auth
 	f.Var(newStringMap("", (*map[string]string)(&cfg.BasicAuth)), "basic-auth", "grpc, http api endpoint basic auth map, e.g. : root:toor,admin:123456")
 
-	return f.Parse(args)
+	// main config::reserved
+	f.Var(newStringSliceValue("", &cfg.Reserved.Admins), "reserved-admins", "basic auth users allowed to access reserved namespaces, e.g. : root,admin")
+
+	// main config::rbac
+	f.BoolVar(&cfg.Rbac.Enabled, "rbac", false, "check the permissions of the users against their roles")
+	f.Var(newStringSliceValue("", &cfg.Rbac.Admins), "rbac-admins", "users holding every permission, e.g. : root,admin")
+
+	// main config::token
+	f.DurationVar(&cfg.Token.TTL, "token-ttl", DefaultTokenTTL, "lifetime of the bearer tokens issued by Authenticate")
+
+	// main config::audit
+	f.BoolVar(&cfg.Audit.Enabled, "audit", false, "record the writes, locks, membership changes, snapshots and auth changes")
+	f.StringVar(&cfg.Audit.File, "audit-file", DefaultAuditFile, "json-lines audit file, relative to the data dir unless absolute")
+	f.Int64Var(&cfg.Audit.MaxSize, "audit-max-size", DefaultAuditMaxSize, "size in MB the audit file is rotated at, 0 never rotates it")
+	f.Int64Var(&cfg.Audit.MaxBackups, "audit-max-backups", DefaultAuditMaxBackups, "rotated audit files kept")
+	f.BoolVar(&cfg.Audit.HashKeys, "audit-hash-keys", false, "record the sha256 of the keys instead of the keys")
+	f.BoolVar(&cfg.Audit.Replicate, "audit-replicate", false, "append the audit records of the leader to the replicated audit namespace, the followers only write their file")
+	f.DurationVar(&cfg.Audit.Retention, "audit-retention", DefaultAuditRetention, "how long the replicated audit records are kept")
+
+	// main config::rate-limit
+	f.Var(newValidatorStringValue[EnumRateLimitKey](DefaultRateLimitKey, &cfg.RateLimit.Key), "rate-limit-key", "what the rate limits are kept for, options: user, namespace, ip")
+	f.Int64Var(&cfg.RateLimit.Read, "rate-limit-read", 0, "reads per second, 0 is unlimited")
+	f.Int64Var(&cfg.RateLimit.ReadBurst, "rate-limit-read-burst", 0, "reads allowed at once, 0 is the rate")
+	f.Int64Var(&cfg.RateLimit.Write, "rate-limit-write", 0, "writes per second, 0 is unlimited")
+	f.Int64Var(&cfg.RateLimit.WriteBurst, "rate-limit-write-burst", 0, "writes allowed at once, 0 is the rate")
+	f.Int64Var(&cfg.RateLimit.Watch, "rate-limit-watch", 0, "watches per second, 0 is unlimited")
+	f.Int64Var(&cfg.RateLimit.WatchBurst, "rate-limit-watch-burst", 0, "watches allowed at once, 0 is the rate")
+	f.Int64Var(&cfg.RateLimit.Lock, "rate-limit-lock", 0, "lock requests per second, 0 is unlimited")
+	f.Int64Var(&cfg.RateLimit.LockBurst, "rate-limit-lock-burst", 0, "lock requests allowed at once, 0 is the rate")
+
+	return f
 }
 
 func bindServerFromEnv(cfg *Config) {
@@ -168,16 +438,27 @@ func bindServerFromEnv(cfg *Config) {
 	EnvStringVar(&cfg.Node.ListenPeerAddr, "RQ_LISTEN_PEER_ADDR", DefaultNodeListenPeerAddr)
 	EnvStringVar(&cfg.Node.ListenClientAddr, "RQ_LISTEN_CLIENT_ADDR", DefaultNodeListenClientAddr)
 	EnvStringVar(&cfg.Node.ListenHttpAddr, "RQ_LISTEN_HTTP_ADDR", "")
+	EnvStringVar(&cfg.Node.AdvertisePeerAddr, "RQ_ADVERTISE_PEER_ADDR", "")
+	EnvStringVar(&cfg.Node.AdvertiseClientAddr, "RQ_ADVERTISE_CLIENT_ADDR", "")
 	BindEnvVar(newUInt32Value(DefaultNodeMaxSnapshots, &cfg.Node.MaxSnapshots), "RQ_MAX_SNAPSHOTS")
 	EnvBoolVar(&cfg.Node.RequestsMerged, "RQ_REQUESTS_MERGED", DefaultNodeRequestsMerged)
+	EnvDurationVar(&cfg.Node.ShutdownTimeout, "RQ_SHUTDOWN_TIMEOUT", DefaultNodeShutdownTimeout)
 
 	// main config::node::tls
 	EnvBoolVar(&cfg.Node.TLS.Auto, "RQ_AUTO_TLS", false)
 	EnvStringVar(&cfg.Node.TLS.CertFile, "RQ_TLS_CERT_FILE", "")
 	EnvStringVar(&cfg.Node.TLS.KeyFile, "RQ_TLS_KEY_FILE", "")
+	EnvStringVar(&cfg.Node.TLS.ClientCAFile, "RQ_TLS_CLIENT_CA_FILE", "")
+	BindEnvVar(newValidatorStringValue[EnumClientAuth](DefaultNodeTLSClientAuth, &cfg.Node.TLS.ClientAuth), "RQ_TLS_CLIENT_AUTH")
+
+	// main config::node::peer-tls
+	EnvStringVar(&cfg.Node.PeerTLS.CertFile, "RQ_PEER_TLS_CERT_FILE", "")
+	EnvStringVar(&cfg.Node.PeerTLS.KeyFile, "RQ_PEER_TLS_KEY_FILE", "")
+	EnvStringVar(&cfg.Node.PeerTLS.CAFile, "RQ_PEER_TLS_CA_FILE", "")
 
 	// main config::store
 	BindEnvVar(newValidatorStringValue[EnumStoreBackend](DefaultStoreBackend, &cfg.Store.Backend), "RQ_STORE_BACKEND")
+	EnvBoolVar(&cfg.Store.StrictSnapshot, "RQ_STORE_STRICT_SNAPSHOT", false)
 
 	// main config::store::nuts
 	EnvInt64Var(&cfg.Store.Nuts.NodeNum, "RQ_NUTS_NODE_NUM", DefaultStoreNutsNodeNum)
@@ -185,14 +466,81 @@ func bindServerFromEnv(cfg *Config) {
 	EnvBoolVar(&cfg.Store.Nuts.StrictMode, "RQ_NUTS_STRICT_MODE", DefaultStoreNutsStrictMode)
 	BindEnvVar(newValidatorStringValue[EnumNutsRWMode](DefaultStoreNutsRWMode, &cfg.Store.Nuts.RWMode), "RQ_NUTS_RW_MODE")
 
+	// main config::store::history
+	BindEnvVar(newUInt32Value(DefaultStoreHistoryMaxVersions, &cfg.Store.History.MaxVersions), "RQ_HISTORY_MAX_VERSIONS")
+
+	// main config::store::encryption
+	EnvStringVar(&cfg.Store.Encryption.KeyFile, "RQ_STORE_ENCRYPTION_KEY_FILE", "")
+	EnvStringVar(&cfg.Store.Encryption.Keyring, "RQ_STORE_ENCRYPTION_KEYRING", "")
+
+	// main config::raft
+	EnvDurationVar(&cfg.Raft.HeartbeatTimeout, "RQ_RAFT_HEARTBEAT_TIMEOUT", DefaultRaftHeartbeatTimeout)
+	EnvDurationVar(&cfg.Raft.ElectionTimeout, "RQ_RAFT_ELECTION_TIMEOUT", DefaultRaftElectionTimeout)
+	EnvDurationVar(&cfg.Raft.LeaderLeaseTimeout, "RQ_RAFT_LEADER_LEASE_TIMEOUT", DefaultRaftLeaderLeaseTimeout)
+	EnvDurationVar(&cfg.Raft.CommitTimeout, "RQ_RAFT_COMMIT_TIMEOUT", DefaultRaftCommitTimeout)
+	EnvDurationVar(&cfg.Raft.SnapshotInterval, "RQ_RAFT_SNAPSHOT_INTERVAL", DefaultRaftSnapshotInterval)
+	BindEnvVar(newUInt64Value(DefaultRaftSnapshotThreshold, &cfg.Raft.SnapshotThreshold), "RQ_RAFT_SNAPSHOT_THRESHOLD")
+	BindEnvVar(newUInt64Value(DefaultRaftTrailingLogs, &cfg.Raft.TrailingLogs), "RQ_RAFT_TRAILING_LOGS")
+	BindEnvVar(newUInt32Value(DefaultRaftMaxAppendEntries, &cfg.Raft.MaxAppendEntries), "RQ_RAFT_MAX_APPEND_ENTRIES")
+	BindEnvVar(newUInt32Value(DefaultRaftTransportMaxPool, &cfg.Raft.TransportMaxPool), "RQ_RAFT_TRANSPORT_MAX_POOL")
+	EnvDurationVar(&cfg.Raft.TransportTimeout, "RQ_RAFT_TRANSPORT_TIMEOUT", DefaultRaftTransportTimeout)
+	BindEnvVar(newValidatorStringValue[EnumRaftLogLevel](DefaultRaftLogLevel, &cfg.Raft.LogLevel), "RQ_RAFT_LOG_LEVEL")
+
+	// main config::autopilot
+	EnvBoolVar(&cfg.Autopilot.Enabled, "RQ_AUTOPILOT", false)
+	EnvDurationVar(&cfg.Autopilot.Interval, "RQ_AUTOPILOT_INTERVAL", DefaultAutopilotInterval)
+	EnvDurationVar(&cfg.Autopilot.LastContactThreshold, "RQ_AUTOPILOT_LAST_CONTACT_THRESHOLD", DefaultAutopilotLastContactThreshold)
+	BindEnvVar(newUInt64Value(DefaultAutopilotMaxTrailingLogs, &cfg.Autopilot.MaxTrailingLogs), "RQ_AUTOPILOT_MAX_TRAILING_LOGS")
+	EnvDurationVar(&cfg.Autopilot.ServerStabilizationTime, "RQ_AUTOPILOT_SERVER_STABILIZATION_TIME", DefaultAutopilotServerStabilizationTime)
+	EnvDurationVar(&cfg.Autopilot.DeadServerGracePeriod, "RQ_AUTOPILOT_DEAD_SERVER_GRACE_PERIOD", DefaultAutopilotDeadServerGracePeriod)
+	BindEnvVar(newValidatorStringValue[EnumAutopilotDeadServerAction](DefaultAutopilotDeadServerAction, &cfg.Autopilot.DeadServerAction), "RQ_AUTOPILOT_DEAD_SERVER_ACTION")
+	EnvBoolVar(&cfg.Autopilot.DisablePromotion, "RQ_AUTOPILOT_DISABLE_PROMOTION", false)
+
 	// main config::cluster::bootstrap(s)
 	BindEnvVar(newClusterBootstrapsValue("", &cfg.Cluster.Bootstrap), "RQ_CLUSTER_BOOTSTRAP")
 
+	// main config::cluster::join
+	BindEnvVar(newStringSliceValue("", &cfg.Cluster.Join), "RQ_CLUSTER_JOIN")
+	EnvBoolVar(&cfg.Cluster.JoinAsLearner, "RQ_CLUSTER_JOIN_AS_LEARNER", false)
+	EnvStringVar(&cfg.Cluster.JoinAuth, "RQ_CLUSTER_JOIN_AUTH", "")
+	EnvStringVar(&cfg.Cluster.JoinCAFile, "RQ_CLUSTER_JOIN_CA_FILE", "")
+	EnvDurationVar(&cfg.Cluster.JoinRetryInterval, "RQ_CLUSTER_JOIN_RETRY_INTERVAL", DefaultClusterJoinRetryInterval)
+
 	// main config::misc
 	EnvBoolVar(&cfg.Misc.PPROF, "RQ_DEBUG_PPROF", false)
 
 	// main config::basic-auth
 	BindEnvVar(newStringMap("", (*map[string]string)(&cfg.BasicAuth)), "RQ_BASIC_AUTH")
+
+	// main config::reserved
+	BindEnvVar(newStringSliceValue("", &cfg.Reserved.Admins), "RQ_RESERVED_ADMINS")
+
+	// main config::rbac
+	EnvBoolVar(&cfg.Rbac.Enabled, "RQ_RBAC", false)
+	BindEnvVar(newStringSliceValue("", &cfg.Rbac.Admins), "RQ_RBAC_ADMINS")
+
+	// main config::token
+	EnvDurationVar(&cfg.Token.TTL, "RQ_TOKEN_TTL", DefaultTokenTTL)
+
+	// main config::audit
+	EnvBoolVar(&cfg.Audit.Enabled, "RQ_AUDIT", false)
+	EnvStringVar(&cfg.Audit.File, "RQ_AUDIT_FILE", DefaultAuditFile)
+	EnvInt64Var(&cfg.Audit.MaxSize, "RQ_AUDIT_MAX_SIZE", DefaultAuditMaxSize)
+	EnvInt64Var(&cfg.Audit.MaxBackups, "RQ_AUDIT_MAX_BACKUPS", DefaultAuditMaxBackups)
+	EnvBoolVar(&cfg.Audit.HashKeys, "RQ_AUDIT_HASH_KEYS", false)
+	EnvBoolVar(&cfg.Audit.Replicate, "RQ_AUDIT_REPLICATE", false)
+	EnvDurationVar(&cfg.Audit.Retention, "RQ_AUDIT_RETENTION", DefaultAuditRetention)
+
+	// main config::rate-limit
+	BindEnvVar(newValidatorStringValue[EnumRateLimitKey](DefaultRateLimitKey, &cfg.RateLimit.Key), "RQ_RATE_LIMIT_KEY")
+	EnvInt64Var(&cfg.RateLimit.Read, "RQ_RATE_LIMIT_READ", 0)
+	EnvInt64Var(&cfg.RateLimit.ReadBurst, "RQ_RATE_LIMIT_READ_BURST", 0)
+	EnvInt64Var(&cfg.RateLimit.Write, "RQ_RATE_LIMIT_WRITE", 0)
+	EnvInt64Var(&cfg.RateLimit.WriteBurst, "RQ_RATE_LIMIT_WRITE_BURST", 0)
+	EnvInt64Var(&cfg.RateLimit.Watch, "RQ_RATE_LIMIT_WATCH", 0)
+	EnvInt64Var(&cfg.RateLimit.WatchBurst, "RQ_RATE_LIMIT_WATCH_BURST", 0)
+	EnvInt64Var(&cfg.RateLimit.Lock, "RQ_RATE_LIMIT_LOCK", 0)
+	EnvInt64Var(&cfg.RateLimit.LockBurst, "RQ_RATE_LIMIT_LOCK_BURST", 0)
 }
 
 func bindFromConfigFile(cfg *Config, path string) error {
@@ -219,6 +567,10 @@ func ReadFromArgs(args ...string) (*Config, error) {
 		if err := bindServerFromArgs(cfg, args[1:]...); err != nil {
 			return nil, err
 		}
+	case "recover":
+		if err := bindRecoverFromArgs(cfg, args[1:]...); err != nil {
+			return nil, err
+		}
 	default:
 		fmt.Fprint(os.Stderr, usage)
 		return nil, errors.New("unknown subcommand")
@@ -251,34 +603,59 @@ func ReadFromEnv() *Config {
 
 func New(args ...string) (cfg *Config, err error) {
 	defer func() {
+		if err == nil {
+			err = cfg.Valid()
+		}
 		if err == nil {
 			cfg.setupEnv()
 		}
 	}()
 
-	configPath := DefaultConfigPath
+	if len(args) > 1 && args[1] == "recover" {
+		return readRecover(args...)
+	}
+
+	return resolve(func() (*Config, error) {
+		return ReadFromArgs(args...)
+	})
+}
 
-	cfg = ReadFromEnv()
-	if cfg.Node.ID != "" {
-		if cfg.env.configFile != "" {
-			configPath = cfg.env.configFile
-			goto HandleConfigFile
+// resolve returns the config of the env when it sets the node id, the config of fromArgs
+// otherwise. the config file they set replaces them, the default config file is read when
+// neither sets the node id
+func resolve(fromArgs func() (*Config, error)) (*Config, error) {
+	cfg := ReadFromEnv()
+	if cfg.Node.ID == "" {
+		var err error
+		if cfg, err = fromArgs(); err != nil {
+			return nil, err
 		}
-		return
 	}
 
-	if cfg, err = ReadFromArgs(args...); err != nil {
-		return
+	switch {
+	case cfg.Node.ID == "":
+		return ReadFromPath(DefaultConfigPath)
+	case cfg.env.configFile != "":
+		return ReadFromPath(cfg.env.configFile)
+	default:
+		return cfg, nil
 	}
-	if cfg.Node.ID != "" {
-		if cfg.env.configFile != "" {
-			configPath = cfg.env.configFile
-			goto HandleConfigFile
-		}
-		return
+}
+
+// readRecover reads the config of the recover subcommand the way New reads the server one. the
+// recover options are only read from the args
+func readRecover(args ...string) (*Config, error) {
+	recovering, err := ReadFromArgs(args...)
+	if err != nil {
+		return nil, err
 	}
 
-HandleConfigFile:
-	cfg, err = ReadFromPath(configPath)
-	return
+	cfg, err := resolve(func() (*Config, error) {
+		return recovering, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	cfg.env.recovering, cfg.env.recoverSnapshot = true, recovering.env.recoverSnapshot
+	return cfg, nil
 }
@@ -0,0 +1,47 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/RealFax/RedQueen/internal/rqd/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_Recover(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "config.toml")
+	require.NoError(t, os.WriteFile(configFile, []byte(`
+[node]
+id = "node-file"
+data-dir = "`+dir+`"
+listen-peer-addr = "127.0.0.1:5290"
+`), 0600))
+
+	// the env is read first, the recover options still come from the args
+	t.Setenv("RQ_NODE_ID", "node-env")
+	t.Setenv("RQ_DATA_DIR", dir)
+	t.Setenv("RQ_LISTEN_PEER_ADDR", "127.0.0.1:5290")
+	cfg, err := config.New("rqd", "recover", "-snapshot", "snap.db")
+	require.NoError(t, err)
+	assert.Equal(t, "node-env", cfg.Node.ID)
+	assert.True(t, cfg.Env().Recovering())
+	assert.Equal(t, "snap.db", cfg.Env().RecoverSnapshot())
+
+	// the config file of the env replaces it
+	t.Setenv("RQ_CONFIG_FILE", configFile)
+	cfg, err = config.New("rqd", "recover")
+	require.NoError(t, err)
+	assert.Equal(t, "node-file", cfg.Node.ID)
+	assert.True(t, cfg.Env().Recovering())
+
+	// the config file of the args is read without -node-id
+	t.Setenv("RQ_NODE_ID", "")
+	t.Setenv("RQ_CONFIG_FILE", "")
+	cfg, err = config.New("rqd", "recover", "-config-file", configFile, "-snapshot", "snap.db")
+	require.NoError(t, err)
+	assert.Equal(t, "node-file", cfg.Node.ID)
+	assert.Equal(t, "snap.db", cfg.Env().RecoverSnapshot())
+}
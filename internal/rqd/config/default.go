@@ -1,5 +1,7 @@
 package config
 
+import "time"
+
 const DefaultConfigPath string = "./config.toml"
 
 // -- node default value
@@ -10,6 +12,10 @@ const (
 	DefaultNodeListenClientAddr string = "127.0.0.1:5230"
 	DefaultNodeMaxSnapshots     uint32 = 5
 	DefaultNodeRequestsMerged   bool   = false
+
+	DefaultNodeShutdownTimeout = 30 * time.Second
+
+	DefaultNodeTLSClientAuth = string(ClientAuthNone)
 )
 
 // -- store default value
@@ -21,4 +27,58 @@ const (
 	DefaultStoreNutsSync       bool   = false
 	DefaultStoreNutsStrictMode bool   = false
 	DefaultStoreNutsRWMode     string = "fileio"
+
+	DefaultStoreHistoryMaxVersions uint32 = 0
+)
+
+// -- raft default value
+
+const (
+	DefaultRaftHeartbeatTimeout   = 1000 * time.Millisecond
+	DefaultRaftElectionTimeout    = 1000 * time.Millisecond
+	DefaultRaftLeaderLeaseTimeout = 500 * time.Millisecond
+	DefaultRaftCommitTimeout      = 50 * time.Millisecond
+	DefaultRaftSnapshotInterval   = 120 * time.Second
+	DefaultRaftTransportTimeout   = 10 * time.Second
+
+	DefaultRaftSnapshotThreshold uint64 = 8192
+	DefaultRaftTrailingLogs      uint64 = 10240
+	DefaultRaftMaxAppendEntries  uint32 = 64
+	DefaultRaftTransportMaxPool  uint32 = 32
+
+	DefaultRaftLogLevel = string(RaftLogLevelInfo)
+)
+
+// -- autopilot default value
+
+const (
+	DefaultAutopilotInterval                = 2 * time.Second
+	DefaultAutopilotLastContactThreshold    = 5 * time.Second
+	DefaultAutopilotServerStabilizationTime = 10 * time.Second
+	DefaultAutopilotDeadServerGracePeriod   = 5 * time.Minute
+
+	DefaultAutopilotMaxTrailingLogs uint64 = 250
+
+	DefaultAutopilotDeadServerAction = string(AutopilotDeadServerRemove)
 )
+
+// -- cluster default value
+
+const DefaultClusterJoinRetryInterval = 3 * time.Second
+
+// -- token default value
+
+const DefaultTokenTTL = 15 * time.Minute
+
+// -- audit default value
+
+const (
+	DefaultAuditFile             = "audit.log"
+	DefaultAuditMaxSize    int64 = 100
+	DefaultAuditMaxBackups int64 = 5
+	DefaultAuditRetention        = 30 * 24 * time.Hour
+)
+
+// -- rate limit default value
+
+const DefaultRateLimitKey = string(RateLimitKeyUser)
@@ -4,6 +4,7 @@ import (
 	"flag"
 	"strings"
 	"syscall"
+	"time"
 )
 
 func BindEnvVar(value flag.Value, name string) {
@@ -27,3 +28,7 @@ func EnvInt64Var(p *int64, name string, value int64) {
 func EnvBoolVar(p *bool, name string, value bool) {
 	BindEnvVar(newBoolValue(value, p), name)
 }
+
+func EnvDurationVar(p *time.Duration, name string, value time.Duration) {
+	BindEnvVar(newDurationValue(value, p), name)
+}
@@ -4,6 +4,7 @@ import (
 	"net/netip"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -12,7 +13,7 @@ const (
 	usage = `
 Usage of RedQueen:
 	
-	method: server
+	method: server, recover
 	format: 
 		./RedQueen [method] <options>
 `
@@ -20,6 +21,15 @@ Usage of RedQueen:
 Usage of RedQueen(server):
 
 	example: ./RedQueen server -config-file ./config.toml
+`
+	recoverUsage = `
+Usage of RedQueen(recover):
+
+	forces a new single node cluster that keeps the data, when the quorum is permanently lost.
+	the other members must be wiped and join the recovered node again.
+
+	example: ./RedQueen recover -config-file ./config.toml
+	         ./RedQueen recover -config-file ./config.toml -snapshot ./state.bin
 `
 )
 
@@ -96,6 +106,44 @@ func (u *uin32Value) Set(s string) error {
 
 func (u *uin32Value) String() string { return strconv.FormatUint(uint64(*u), 10) }
 
+// -- uint64 value --
+type uint64Value uint64
+
+func newUInt64Value(val uint64, p *uint64) *uint64Value {
+	*p = val
+	return (*uint64Value)(p)
+}
+
+func (u *uint64Value) Set(s string) error {
+	val, err := strconv.ParseUint(s, 0, 64)
+	if err != nil {
+		return err
+	}
+	*u = uint64Value(val)
+	return nil
+}
+
+func (u *uint64Value) String() string { return strconv.FormatUint(uint64(*u), 10) }
+
+// -- time.Duration value --
+type durationValue time.Duration
+
+func newDurationValue(val time.Duration, p *time.Duration) *durationValue {
+	*p = val
+	return (*durationValue)(p)
+}
+
+func (d *durationValue) Set(s string) error {
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = durationValue(v)
+	return nil
+}
+
+func (d *durationValue) String() string { return time.Duration(*d).String() }
+
 // -- stringValidator value --
 
 type validatorStringValue[T stringValidator] struct{ ptr *T }
@@ -240,3 +288,33 @@ func newStringMap(val string, p *map[string]string) *stringMapValue {
 	*p, _ = decodeStringMap(val)
 	return (*stringMapValue)(p)
 }
+
+// -- []string value --
+
+func decodeStringSlice(s string) []string {
+	if s == "" {
+		return nil
+	}
+	entries := strings.Split(s, ",")
+	values := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			values = append(values, entry)
+		}
+	}
+	return values
+}
+
+type stringSliceValue []string
+
+func newStringSliceValue(val string, p *[]string) *stringSliceValue {
+	*p = decodeStringSlice(val)
+	return (*stringSliceValue)(p)
+}
+
+func (v *stringSliceValue) Set(s string) error {
+	*v = decodeStringSlice(s)
+	return nil
+}
+
+func (v *stringSliceValue) String() string { return strings.Join(*v, ",") }
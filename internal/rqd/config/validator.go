@@ -1,8 +1,14 @@
 package config
 
 import (
+	"math"
+	"net"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/RealFax/RedQueen/pkg/expr"
 	"github.com/pkg/errors"
 )
 
@@ -51,6 +57,298 @@ func (p FilePath) Valid() error {
 	return errors.New("file/dir not found")
 }
 
+type EnumRaftLogLevel string
+
+const (
+	RaftLogLevelTrace EnumRaftLogLevel = "trace"
+	RaftLogLevelDebug EnumRaftLogLevel = "debug"
+	RaftLogLevelInfo  EnumRaftLogLevel = "info"
+	RaftLogLevelWarn  EnumRaftLogLevel = "warn"
+	RaftLogLevelError EnumRaftLogLevel = "error"
+	RaftLogLevelOff   EnumRaftLogLevel = "off"
+)
+
+func (l EnumRaftLogLevel) Valid() error {
+	switch l {
+	case RaftLogLevelTrace, RaftLogLevelDebug, RaftLogLevelInfo, RaftLogLevelWarn, RaftLogLevelError, RaftLogLevelOff:
+		return nil
+	default:
+		return errors.New("unknown raft log level")
+	}
+}
+
+// Valid checks the raft options with the same bounds as raft.ValidateConfig, unset options
+// are checked with their default
+func (r Raft) Valid() error {
+	var (
+		heartbeat = expr.OrDefault(r.HeartbeatTimeout, DefaultRaftHeartbeatTimeout)
+		election  = expr.OrDefault(r.ElectionTimeout, DefaultRaftElectionTimeout)
+		lease     = expr.OrDefault(r.LeaderLeaseTimeout, DefaultRaftLeaderLeaseTimeout)
+		commit    = expr.OrDefault(r.CommitTimeout, DefaultRaftCommitTimeout)
+		snapshot  = expr.OrDefault(r.SnapshotInterval, DefaultRaftSnapshotInterval)
+	)
+	switch {
+	case heartbeat < 5*time.Millisecond:
+		return errors.New("raft heartbeat timeout is too low")
+	case election < 5*time.Millisecond:
+		return errors.New("raft election timeout is too low")
+	case lease < 5*time.Millisecond:
+		return errors.New("raft leader lease timeout is too low")
+	case commit < time.Millisecond:
+		return errors.New("raft commit timeout is too low")
+	case snapshot < 5*time.Millisecond:
+		return errors.New("raft snapshot interval is too low")
+	case lease > heartbeat:
+		return errors.New("raft leader lease timeout can't be larger than heartbeat timeout")
+	case election < heartbeat:
+		return errors.New("raft election timeout must be equal or greater than heartbeat timeout")
+	case r.MaxAppendEntries > 1024:
+		return errors.New("raft max append entries is too large")
+	case r.TransportTimeout < 0:
+		return errors.New("raft transport timeout can't be negative")
+	}
+	if r.LogLevel != "" {
+		return r.LogLevel.Valid()
+	}
+	return nil
+}
+
+type EnumAutopilotDeadServerAction string
+
+const (
+	AutopilotDeadServerRemove EnumAutopilotDeadServerAction = "remove"
+	AutopilotDeadServerDemote EnumAutopilotDeadServerAction = "demote"
+	AutopilotDeadServerNone   EnumAutopilotDeadServerAction = "none"
+)
+
+func (a EnumAutopilotDeadServerAction) Valid() error {
+	switch a {
+	case AutopilotDeadServerRemove, AutopilotDeadServerDemote, AutopilotDeadServerNone:
+		return nil
+	default:
+		return errors.New("unknown autopilot dead server action")
+	}
+}
+
+// EnumClientAuth is how the grpc and http apis ask for client certificates
+type EnumClientAuth string
+
+const (
+	// ClientAuthNone doesn't ask for a certificate
+	ClientAuthNone EnumClientAuth = "none"
+	// ClientAuthRequest asks for a certificate, clients without a verified one use the basic auth
+	ClientAuthRequest EnumClientAuth = "request"
+	// ClientAuthRequire requires a certificate, clients without a verified one use the basic auth
+	ClientAuthRequire EnumClientAuth = "require"
+	// ClientAuthVerify requires a verified certificate
+	ClientAuthVerify EnumClientAuth = "verify"
+)
+
+func (a EnumClientAuth) Valid() error {
+	switch a {
+	case ClientAuthNone, ClientAuthRequest, ClientAuthRequire, ClientAuthVerify:
+		return nil
+	default:
+		return errors.New("unknown tls client auth")
+	}
+}
+
+// Valid checks that the client certificates can be verified
+func (t NodeTLS) Valid() error {
+	if t.ClientAuth == "" || t.ClientAuth == ClientAuthNone {
+		return nil
+	}
+	if err := t.ClientAuth.Valid(); err != nil {
+		return err
+	}
+	if !t.Auto && (t.CertFile == "" || t.KeyFile == "") {
+		return errors.New("tls client auth requires the tls")
+	}
+	if t.ClientCAFile == "" {
+		return errors.New("tls client auth requires client-ca-file")
+	}
+	return errors.Wrap(FilePath(t.ClientCAFile).Valid(), "tls client-ca-file")
+}
+
+// Valid checks the autopilot options, unset options are checked with their default
+func (a Autopilot) Valid() error {
+	switch {
+	case a.Interval < 0, a.LastContactThreshold < 0, a.ServerStabilizationTime < 0, a.DeadServerGracePeriod < 0:
+		return errors.New("autopilot durations can't be negative")
+	case expr.OrDefault(a.DeadServerGracePeriod, DefaultAutopilotDeadServerGracePeriod) < expr.OrDefault(a.LastContactThreshold, DefaultAutopilotLastContactThreshold):
+		return errors.New("autopilot dead server grace period can't be less than last contact threshold")
+	}
+	if a.DeadServerAction != "" {
+		return a.DeadServerAction.Valid()
+	}
+	return nil
+}
+
+// advertisable checks that addr is a host:port other nodes can dial
+func advertisable(addr string) error {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+	if host == "" {
+		return errors.New("missing host")
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsUnspecified() {
+		return errors.New("unspecified address is not advertisable")
+	}
+	if n, err := strconv.ParseUint(port, 10, 16); err != nil || n == 0 {
+		return errors.New("invalid port")
+	}
+	return nil
+}
+
+// Valid checks the advertised addresses, binding the peer listener to an unspecified address
+// requires advertise-peer-addr
+func (n Node) Valid() error {
+	if n.AdvertisePeerAddr != "" {
+		if err := advertisable(n.AdvertisePeerAddr); err != nil {
+			return errors.Wrap(err, "invalid advertise-peer-addr")
+		}
+	} else if err := advertisable(n.ListenPeerAddr); err != nil {
+		return errors.Wrap(err, "listen-peer-addr is not advertisable, set advertise-peer-addr")
+	}
+	if n.AdvertiseClientAddr != "" {
+		if err := advertisable(n.AdvertiseClientAddr); err != nil {
+			return errors.Wrap(err, "invalid advertise-client-addr")
+		}
+	}
+	if n.ShutdownTimeout < 0 {
+		return errors.New("shutdown timeout can't be negative")
+	}
+	if err := n.TLS.Valid(); err != nil {
+		return err
+	}
+	return n.PeerTLS.Valid()
+}
+
+// Valid checks that the peer tls files are set together and exist
+func (t NodePeerTLS) Valid() error {
+	if t.CertFile == "" && t.KeyFile == "" && t.CAFile == "" {
+		return nil
+	}
+	for name, file := range map[string]string{
+		"peer-tls cert-file": t.CertFile,
+		"peer-tls key-file":  t.KeyFile,
+		"peer-tls ca-file":   t.CAFile,
+	} {
+		if file == "" {
+			return errors.Errorf("%s is required by the peer tls", name)
+		}
+		if err := FilePath(file).Valid(); err != nil {
+			return errors.Wrap(err, name)
+		}
+	}
+	return nil
+}
+
+// Valid checks that a node either bootstraps or joins a cluster
+func (c Cluster) Valid() error {
+	if len(c.Join) == 0 {
+		return nil
+	}
+	if len(c.Bootstrap) != 0 {
+		return errors.New("cluster bootstrap and join can't be used together")
+	}
+	for _, endpoint := range c.Join {
+		if _, _, err := net.SplitHostPort(endpoint); err != nil {
+			return errors.Wrapf(err, "invalid cluster join endpoint %s", endpoint)
+		}
+	}
+	if c.JoinAuth != "" && !strings.Contains(c.JoinAuth, ":") {
+		return errors.New("cluster join auth must be username:password")
+	}
+	if c.JoinCAFile != "" {
+		if err := FilePath(c.JoinCAFile).Valid(); err != nil {
+			return errors.Wrap(err, "cluster join ca-file")
+		}
+	}
+	if c.JoinRetryInterval < 0 {
+		return errors.New("cluster join retry interval can't be negative")
+	}
+	return nil
+}
+
+// Valid checks that either the key file or the keyring is set, and that it exists
+func (e StoreEncryption) Valid() error {
+	switch {
+	case e.KeyFile != "" && e.Keyring != "":
+		return errors.New("store encryption key-file and keyring can't be used together")
+	case e.KeyFile != "":
+		return errors.Wrap(FilePath(e.KeyFile).Valid(), "store encryption key-file")
+	case e.Keyring != "":
+		return errors.Wrap(FilePath(e.Keyring).Valid(), "store encryption keyring")
+	}
+	return nil
+}
+
+// EnumRateLimitKey is what the rate limits are kept for
+type EnumRateLimitKey string
+
+const (
+	// RateLimitKeyUser keeps a limit per authenticated user, the unauthenticated clients per ip
+	RateLimitKeyUser EnumRateLimitKey = "user"
+	// RateLimitKeyNamespace keeps a limit per namespace, the locks share the default namespace
+	RateLimitKeyNamespace EnumRateLimitKey = "namespace"
+	// RateLimitKeyIP keeps a limit per client ip
+	RateLimitKeyIP EnumRateLimitKey = "ip"
+)
+
+func (k EnumRateLimitKey) Valid() error {
+	switch k {
+	case RateLimitKeyUser, RateLimitKeyNamespace, RateLimitKeyIP:
+		return nil
+	default:
+		return errors.New("unknown rate limit key")
+	}
+}
+
+// Valid checks that the retention is a ttl of the store, an unset retention is the default one
+func (a Audit) Valid() error {
+	if a.Retention < 0 {
+		return errors.New("audit retention can't be negative")
+	}
+	if a.Retention/time.Second > math.MaxUint32 {
+		return errors.New("audit retention is too long")
+	}
+	return nil
+}
+
+// Valid checks the rate limit options, an unset key is the user
+func (r RateLimit) Valid() error {
+	for _, v := range []int64{r.Read, r.ReadBurst, r.Write, r.WriteBurst, r.Watch, r.WatchBurst, r.Lock, r.LockBurst} {
+		if v < 0 {
+			return errors.New("rate limits can't be negative")
+		}
+	}
+	if r.Key != "" {
+		return r.Key.Valid()
+	}
+	return nil
+}
+
+// Valid checks every section of the config, the first invalid section is returned
+func (c *Config) Valid() error {
+	for _, section := range []Validator{
+		c.Node,
+		c.Raft,
+		c.Autopilot,
+		c.Cluster,
+		c.Audit,
+		c.RateLimit,
+		c.Store.Encryption,
+	} {
+		if err := section.Valid(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 type stringValidator interface {
-	EnumStoreBackend | EnumNutsRWMode | FilePath
+	EnumStoreBackend | EnumNutsRWMode | EnumRaftLogLevel | EnumAutopilotDeadServerAction | EnumClientAuth | EnumRateLimitKey | FilePath
 }
@@ -2,7 +2,11 @@ package config_test
 
 import (
 	"github.com/RealFax/RedQueen/internal/rqd/config"
+	"math"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 )
 
 func unexpected(t *testing.T, validator config.Validator) {
@@ -17,6 +21,24 @@ func expected(t *testing.T, validator config.Validator) {
 	}
 }
 
+// invalid fails the test when any of vals is valid
+func invalid[T config.Validator](t *testing.T, vals []T) {
+	for _, val := range vals {
+		if err := val.Valid(); err == nil {
+			t.Fatalf("expected error: %+v", val)
+		}
+	}
+}
+
+// tempFile returns an empty file removed with the test
+func tempFile(t *testing.T) string {
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
 func TestEnumStoreBackend_Valid(t *testing.T) {
 	unexpected(t, config.StoreBackendNuts)
 
@@ -37,3 +59,174 @@ func TestEnumNutsRWMode_Valid(t *testing.T) {
 func TestFilePath_Valid(t *testing.T) {
 	unexpected(t, config.FilePath("./"))
 }
+
+func TestEnumRaftLogLevel_Valid(t *testing.T) {
+	for _, val := range []config.EnumRaftLogLevel{
+		config.RaftLogLevelTrace,
+		config.RaftLogLevelDebug,
+		config.RaftLogLevelInfo,
+		config.RaftLogLevelWarn,
+		config.RaftLogLevelError,
+		config.RaftLogLevelOff,
+	} {
+		unexpected(t, val)
+	}
+
+	expected(t, config.EnumRaftLogLevel("verbose"))
+}
+
+func TestRaft_Valid(t *testing.T) {
+	// unset options fall back to the raft defaults
+	unexpected(t, config.Raft{})
+	unexpected(t, config.Raft{HeartbeatTimeout: 200 * time.Millisecond, ElectionTimeout: 200 * time.Millisecond, LeaderLeaseTimeout: 100 * time.Millisecond})
+
+	invalid(t, []config.Raft{
+		{HeartbeatTimeout: time.Millisecond},
+		{LeaderLeaseTimeout: 2 * time.Second},
+		{HeartbeatTimeout: 2 * time.Second},
+		{MaxAppendEntries: 2048},
+		{LogLevel: "verbose"},
+	})
+}
+
+func TestEnumAutopilotDeadServerAction_Valid(t *testing.T) {
+	for _, val := range []config.EnumAutopilotDeadServerAction{
+		config.AutopilotDeadServerRemove,
+		config.AutopilotDeadServerDemote,
+		config.AutopilotDeadServerNone,
+	} {
+		unexpected(t, val)
+	}
+
+	expected(t, config.EnumAutopilotDeadServerAction("kill"))
+}
+
+func TestAutopilot_Valid(t *testing.T) {
+	unexpected(t, config.Autopilot{})
+	unexpected(t, config.Autopilot{Enabled: true, DeadServerGracePeriod: time.Minute, DeadServerAction: config.AutopilotDeadServerDemote})
+
+	invalid(t, []config.Autopilot{
+		{Interval: -time.Second},
+		{DeadServerGracePeriod: time.Second},
+		{DeadServerAction: "kill"},
+	})
+}
+
+func TestCluster_Valid(t *testing.T) {
+	unexpected(t, config.Cluster{})
+	unexpected(t, config.Cluster{Join: []string{"127.0.0.1:5230", "node-2:5230"}, JoinAuth: "root:toor"})
+
+	invalid(t, []config.Cluster{
+		{Join: []string{"127.0.0.1:5230"}, Bootstrap: []config.ClusterBootstrap{{Name: "node-1", PeerAddr: "127.0.0.1:5290"}}},
+		{Join: []string{"127.0.0.1"}},
+		{Join: []string{"127.0.0.1:5230"}, JoinAuth: "root"},
+		{Join: []string{"127.0.0.1:5230"}, JoinRetryInterval: -time.Second},
+		{Join: []string{"127.0.0.1:5230"}, JoinCAFile: "/non-existing/ca.pem"},
+	})
+}
+
+func TestNode_Valid(t *testing.T) {
+	unexpected(t, config.Node{ListenPeerAddr: "127.0.0.1:5290"})
+	unexpected(t, config.Node{ListenPeerAddr: "0.0.0.0:5290", AdvertisePeerAddr: "node-1.redqueen:5290"})
+	unexpected(t, config.Node{ListenPeerAddr: "[::]:5290", AdvertisePeerAddr: "10.0.0.1:5290", AdvertiseClientAddr: "10.0.0.1:5230"})
+
+	invalid(t, []config.Node{
+		{ListenPeerAddr: "0.0.0.0:5290"},
+		{ListenPeerAddr: "0.0.0.0:5290", AdvertisePeerAddr: "0.0.0.0:5290"},
+		{ListenPeerAddr: "127.0.0.1:5290", AdvertisePeerAddr: "10.0.0.1"},
+		{ListenPeerAddr: "127.0.0.1:5290", AdvertisePeerAddr: ":5290"},
+		{ListenPeerAddr: "127.0.0.1:5290", AdvertiseClientAddr: "10.0.0.1:0"},
+	})
+
+	node := config.Node{ListenPeerAddr: "0.0.0.0:5290", ListenClientAddr: "0.0.0.0:5230", AdvertisePeerAddr: "10.0.0.1:5290"}
+	if node.PeerAddr() != "10.0.0.1:5290" || node.ClientAddr() != "0.0.0.0:5230" {
+		t.Fatalf("unexpected advertised addresses: %s, %s", node.PeerAddr(), node.ClientAddr())
+	}
+}
+
+func TestNodePeerTLS_Valid(t *testing.T) {
+	file := tempFile(t)
+
+	unexpected(t, config.NodePeerTLS{})
+	unexpected(t, config.NodePeerTLS{CertFile: file, KeyFile: file, CAFile: file})
+
+	invalid(t, []config.NodePeerTLS{
+		{CertFile: file, KeyFile: file},
+		{CAFile: file},
+		{CertFile: file, KeyFile: file, CAFile: file + ".missing"},
+	})
+}
+
+func TestEnumClientAuth_Valid(t *testing.T) {
+	for _, val := range []config.EnumClientAuth{
+		config.ClientAuthNone,
+		config.ClientAuthRequest,
+		config.ClientAuthRequire,
+		config.ClientAuthVerify,
+	} {
+		unexpected(t, val)
+	}
+
+	expected(t, config.EnumClientAuth("optional"))
+}
+
+func TestNodeTLS_Valid(t *testing.T) {
+	file := tempFile(t)
+
+	unexpected(t, config.NodeTLS{})
+	unexpected(t, config.NodeTLS{Auto: true, ClientAuth: config.ClientAuthNone})
+	unexpected(t, config.NodeTLS{Auto: true, ClientCAFile: file, ClientAuth: config.ClientAuthVerify})
+	unexpected(t, config.NodeTLS{CertFile: file, KeyFile: file, ClientCAFile: file, ClientAuth: config.ClientAuthRequest})
+
+	invalid(t, []config.NodeTLS{
+		{ClientCAFile: file, ClientAuth: config.ClientAuthVerify},
+		{Auto: true, ClientAuth: config.ClientAuthRequire},
+		{Auto: true, ClientCAFile: file + ".missing", ClientAuth: config.ClientAuthVerify},
+		{Auto: true, ClientCAFile: file, ClientAuth: "optional"},
+	})
+}
+
+func TestAudit_Valid(t *testing.T) {
+	unexpected(t, config.Audit{})
+	unexpected(t, config.Audit{Replicate: true, Retention: 24 * time.Hour})
+
+	invalid(t, []config.Audit{
+		{Retention: -time.Hour},
+		{Retention: (math.MaxUint32 + 1) * time.Second},
+	})
+}
+
+func TestRateLimit_Valid(t *testing.T) {
+	unexpected(t, config.RateLimit{})
+	unexpected(t, config.RateLimit{Key: config.RateLimitKeyNamespace, Write: 100, WriteBurst: 200})
+
+	expected(t, config.RateLimit{Key: "method"})
+	expected(t, config.RateLimit{Read: -1})
+	expected(t, config.RateLimit{LockBurst: -1})
+}
+
+func TestStoreEncryption_Valid(t *testing.T) {
+	file := tempFile(t)
+
+	unexpected(t, config.StoreEncryption{})
+	unexpected(t, config.StoreEncryption{KeyFile: file})
+	unexpected(t, config.StoreEncryption{Keyring: file})
+
+	invalid(t, []config.StoreEncryption{
+		{KeyFile: file, Keyring: file},
+		{Keyring: file + ".missing"},
+	})
+}
+
+func TestConfig_Valid(t *testing.T) {
+	cfg := &config.Config{Node: config.Node{ListenPeerAddr: "127.0.0.1:5290"}}
+	unexpected(t, cfg)
+
+	// every section is checked
+	invalid(t, []*config.Config{
+		{Node: config.Node{ListenPeerAddr: "0.0.0.0:5290"}},
+		{Node: cfg.Node, Autopilot: config.Autopilot{Interval: -time.Second}},
+		{Node: cfg.Node, RateLimit: config.RateLimit{Read: -1}},
+		{Node: cfg.Node, Store: config.Store{Encryption: config.StoreEncryption{KeyFile: "/nonexistent"}}},
+	})
+}
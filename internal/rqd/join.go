@@ -0,0 +1,135 @@
+package rqd
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/RealFax/RedQueen/api/serverpb"
+	"github.com/RealFax/RedQueen/internal/rqd/config"
+	"github.com/RealFax/RedQueen/pkg/expr"
+	"github.com/RealFax/RedQueen/pkg/grpcutil"
+	"github.com/RealFax/RedQueen/pkg/tlsutil"
+	"github.com/hashicorp/raft"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// joinTimeout bounds a single join request, the leader waits up to membershipTimeout itself
+const joinTimeout = membershipTimeout + 5*time.Second
+
+// member reports whether the local node is in the latest raft configuration
+func (s *Server) member() bool {
+	future := s.raft.GetConfiguration()
+	if future.Error() != nil {
+		return false
+	}
+	for _, server := range future.Configuration().Servers {
+		if server.ID == raft.ServerID(s.cfg.Node.ID) {
+			return true
+		}
+	}
+	return false
+}
+
+// joinTLSConfig returns the tls config the join requests are sent with, nil without tls. the
+// members are verified with the join ca file, or the client ca file of the node, and the system
+// roots otherwise. the self-signed certificates of auto tls are only trusted without any of them
+func (s *Server) joinTLSConfig() (*tls.Config, error) {
+	if s.tlsConfig == nil {
+		return nil, nil
+	}
+
+	cfg := &tls.Config{}
+	switch caFile := expr.OrDefault(s.cfg.Cluster.JoinCAFile, s.cfg.Node.TLS.ClientCAFile); {
+	case caFile != "":
+		var err error
+		if cfg.RootCAs, err = tlsutil.LoadCertPool(caFile); err != nil {
+			return nil, errors.Wrap(err, "cluster join ca")
+		}
+	case s.cfg.Node.TLS.Auto:
+		cfg.InsecureSkipVerify = true
+	}
+	if s.clientAuthEnabled() {
+		// the members ask for a client certificate too
+		cfg.Certificates = s.tlsConfig.Certificates
+		if s.certReloader != nil {
+			cfg.GetClientCertificate = s.certReloader.GetClientCertificate
+		}
+	}
+	return cfg, nil
+}
+
+// joinDialOptions returns the dial options of a join request, the certificate of the member
+// must be issued for the host of endpoint
+func (s *Server) joinDialOptions(tlsConfig *tls.Config, endpoint string) []grpc.DialOption {
+	opts := make([]grpc.DialOption, 0, 3)
+	if tlsConfig != nil {
+		cfg := tlsConfig.Clone()
+		if host, _, err := net.SplitHostPort(endpoint); err == nil {
+			cfg.ServerName = host
+		}
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(cfg)))
+	} else {
+		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	}
+
+	if username, password, ok := strings.Cut(s.cfg.Cluster.JoinAuth, ":"); ok {
+		auth := grpcutil.NewBasicAuthClient(username, password)
+		opts = append(opts, grpc.WithUnaryInterceptor(auth.Unary), grpc.WithStreamInterceptor(auth.Stream))
+	}
+	return opts
+}
+
+// requestJoin asks the member at endpoint to add the local node, only the leader accepts it
+func (s *Server) requestJoin(tlsConfig *tls.Config, endpoint string) error {
+	ctx, cancel := context.WithTimeout(s.ctx, joinTimeout)
+	defer cancel()
+
+	conn, err := grpc.DialContext(ctx, endpoint, s.joinDialOptions(tlsConfig, endpoint)...)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = serverpb.NewRedQueenClient(conn).AppendCluster(ctx, &serverpb.AppendClusterRequest{
+		ServerId:   s.cfg.Node.ID,
+		PeerAddr:   s.cfg.Node.PeerAddr(),
+		ClientAddr: expr.Pointer(s.cfg.Node.ClientAddr()),
+		Voter:      !s.cfg.Cluster.JoinAsLearner,
+	})
+	return err
+}
+
+// joinCluster asks the join endpoints to add the local node until it is a member
+func (s *Server) joinCluster(tlsConfig *tls.Config) {
+	var (
+		logger   = s.raft.cfg.Logger.Named("join")
+		interval = expr.OrDefault(s.cfg.Cluster.JoinRetryInterval, config.DefaultClusterJoinRetryInterval)
+	)
+	for {
+		if s.member() {
+			return
+		}
+
+		var err error
+		for _, endpoint := range s.cfg.Cluster.Join {
+			if err = s.requestJoin(tlsConfig, endpoint); err == nil {
+				logger.Info("joined the cluster", "endpoint", endpoint, "learner", s.cfg.Cluster.JoinAsLearner)
+				return
+			}
+			logger.Debug("join request rejected", "endpoint", endpoint, "error", err)
+		}
+		logger.Warn("failed to join the cluster, retrying", "error", errors.Wrap(err, "last join request"), "interval", interval)
+
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
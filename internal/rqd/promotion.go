@@ -0,0 +1,94 @@
+package rqd
+
+import (
+	"context"
+	"time"
+
+	"github.com/RealFax/RedQueen/api/serverpb"
+	"github.com/RealFax/RedQueen/internal/rqd/store"
+	"github.com/RealFax/RedQueen/pkg/expr"
+	"github.com/hashicorp/raft"
+	"github.com/pkg/errors"
+)
+
+// PromotionNamespace marks the learners the autopilot promotes, keyed by the server id. the
+// learners added on purpose are never marked
+const PromotionNamespace = "_Promotion"
+
+func init() {
+	store.ReserveNamespace(PromotionNamespace)
+}
+
+// LoadPromotion reports whether the server id is marked for promotion
+func LoadPromotion(s store.Store, id raft.ServerID) (bool, error) {
+	actions, err := s.Swap(PromotionNamespace)
+	if err != nil {
+		return false, err
+	}
+
+	if _, err = actions.Get([]byte(id)); err != nil {
+		if errors.Is(err, store.ErrKeyNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// markPromotion replicates the promotion mark of the server id, marked is false to clear it
+func (s *Server) markPromotion(ctx context.Context, id raft.ServerID, marked bool) error {
+	if prev, err := LoadPromotion(s.store, id); err == nil && prev == marked {
+		return nil
+	}
+	payload := &serverpb.RaftLogPayload{
+		Command:   serverpb.RaftLogCommand_Del,
+		Key:       []byte(id),
+		Namespace: expr.Pointer(PromotionNamespace),
+	}
+	if marked {
+		payload.Command, payload.Value = serverpb.RaftLogCommand_Set, []byte("1")
+	}
+	return s.applyLog(ctx, payload, 500*time.Millisecond)
+}
+
+// stagedJoin reports whether the voters join as learners marked for promotion, so that they only
+// vote once the autopilot sees them caught up
+func (s *Server) stagedJoin() bool {
+	return s.autopilot != nil && s.autopilot.promotion
+}
+
+// autopilotChanged keeps the promotion marks of the servers changed by the autopilot: the
+// promoted ones are no longer marked, the demoted voters are promoted again once they recover.
+// the removed ones lose their mark and client address
+func (s *Server) autopilotChanged(action string, id raft.ServerID, err error) {
+	if s.audit != nil {
+		s.auditAutopilot(action, id, err)
+	}
+	if err != nil {
+		return
+	}
+
+	var mErr error
+	switch action {
+	case "promote":
+		mErr = s.markPromotion(s.ctx, id, false)
+	case "remove":
+		if mErr = s.markPromotion(s.ctx, id, false); mErr == nil {
+			mErr = s.forgetClientAddr(s.ctx, id)
+		}
+	case "demote":
+		mErr = s.markPromotion(s.ctx, id, true)
+	}
+	if mErr != nil {
+		s.raft.cfg.Logger.Warn("failed to update the server changed by autopilot", "id", id, "error", mErr)
+	}
+}
+
+// promotable is the autopilot filter of the learners, only the marked ones are promoted
+func (s *Server) promotable(id raft.ServerID) bool {
+	marked, err := LoadPromotion(s.store, id)
+	if err != nil {
+		s.raft.cfg.Logger.Warn("failed to load the promotion mark", "id", id, "error", err)
+	}
+	return marked
+}
@@ -0,0 +1,119 @@
+package rqd
+
+import (
+	"github.com/RealFax/RedQueen/api/serverpb"
+	"github.com/RealFax/RedQueen/internal/rqd/store"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
+)
+
+// QuotaNamespace holds the quota of each namespace, keyed by the namespace
+const QuotaNamespace = "_Quota"
+
+var ErrQuotaExceeded = errors.New("namespace quota exceeded")
+
+func init() {
+	store.ReserveNamespace(QuotaNamespace)
+}
+
+func quotaEmpty(q *serverpb.Quota) bool {
+	return q == nil || (q.MaxKeys == 0 && q.MaxBytes == 0 && q.MaxValueSize == 0 && q.MaxTtl == 0)
+}
+
+// LoadQuota returns the quota of namespace, nil means the namespace has no quota
+func LoadQuota(s store.Store, namespace string) (*serverpb.Quota, error) {
+	actions, err := s.Swap(QuotaNamespace)
+	if err != nil {
+		return nil, err
+	}
+
+	val, err := actions.Get([]byte(namespace))
+	if err != nil {
+		if errors.Is(err, store.ErrKeyNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	quota := &serverpb.Quota{}
+	if err = proto.Unmarshal(val.Data, quota); err != nil {
+		return nil, errors.Wrap(err, "unmarshal quota error")
+	}
+	return quota, nil
+}
+
+// StoreQuota replaces the quota of namespace, an empty quota removes it
+func StoreQuota(s store.Store, namespace string, quota *serverpb.Quota) error {
+	actions, err := s.Swap(QuotaNamespace)
+	if err != nil {
+		return err
+	}
+
+	if quotaEmpty(quota) {
+		if _, err = actions.Get([]byte(namespace)); err != nil {
+			if errors.Is(err, store.ErrKeyNotFound) {
+				return nil
+			}
+			return err
+		}
+		return actions.Del([]byte(namespace))
+	}
+
+	b, err := proto.Marshal(quota)
+	if err != nil {
+		return errors.Wrap(err, "marshal quota error")
+	}
+	return actions.Set([]byte(namespace), b)
+}
+
+// CheckQuota reports whether writing key keeps namespace within quota.
+//
+// the usage of the namespace is only read when max keys or max bytes is set, the store keeps it
+// up to date on its writes
+func CheckQuota(s store.Store, quota *serverpb.Quota, namespace string, key, value []byte, ttl uint32) error {
+	if quotaEmpty(quota) {
+		return nil
+	}
+
+	if quota.MaxValueSize != 0 && uint64(len(value)) > uint64(quota.MaxValueSize) {
+		return errors.Wrapf(ErrQuotaExceeded, "value size %d exceeds %d", len(value), quota.MaxValueSize)
+	}
+
+	if quota.MaxTtl != 0 && (ttl == 0 || ttl > quota.MaxTtl) {
+		return errors.Wrapf(ErrQuotaExceeded, "ttl %d exceeds %d", ttl, quota.MaxTtl)
+	}
+
+	if quota.MaxKeys == 0 && quota.MaxBytes == 0 {
+		return nil
+	}
+
+	stats, err := s.NamespaceStats(namespace)
+	if err != nil {
+		if !errors.Is(err, store.ErrNamespaceNotFound) {
+			return err
+		}
+		stats = &store.NamespaceStats{Namespace: namespace}
+	}
+
+	var (
+		keys  = stats.Keys + 1
+		bytes = stats.Bytes + uint64(len(key)+len(value))
+	)
+	// overwriting a key replaces its usage
+	prev, found, err := s.KeyUsage(namespace, key)
+	if err != nil {
+		return err
+	}
+	if found {
+		keys--
+		bytes -= prev
+	}
+
+	if quota.MaxKeys != 0 && keys > quota.MaxKeys {
+		return errors.Wrapf(ErrQuotaExceeded, "keys %d exceeds %d", keys, quota.MaxKeys)
+	}
+	if quota.MaxBytes != 0 && bytes > quota.MaxBytes {
+		return errors.Wrapf(ErrQuotaExceeded, "bytes %d exceeds %d", bytes, quota.MaxBytes)
+	}
+	return nil
+}
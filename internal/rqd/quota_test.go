@@ -0,0 +1,80 @@
+package rqd_test
+
+import (
+	"github.com/RealFax/RedQueen/api/serverpb"
+	red "github.com/RealFax/RedQueen/internal/rqd"
+	"github.com/RealFax/RedQueen/internal/rqd/store"
+	"github.com/RealFax/RedQueen/internal/rqd/store/nuts"
+	"github.com/RealFax/RedQueen/pkg/expr"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+	"testing"
+)
+
+func newTestStore(t *testing.T) store.Store {
+	db, err := nuts.New(nuts.Config{
+		NodeNum: 1,
+		DataDir: t.TempDir(),
+		RWMode:  nuts.FileIO,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestFSMHandlers_NamespaceQuota(t *testing.T) {
+	var (
+		db        = newTestStore(t)
+		handlers  = red.NewFSMHandlers(db)
+		namespace = expr.Pointer("tenant")
+	)
+
+	quota, err := proto.Marshal(&serverpb.Quota{MaxKeys: 2, MaxBytes: 64, MaxValueSize: 16, MaxTtl: 60})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, handlers[serverpb.RaftLogCommand_NamespaceQuota](&serverpb.RaftLogPayload{
+		Command:   serverpb.RaftLogCommand_NamespaceQuota,
+		Value:     quota,
+		Namespace: namespace,
+	}))
+
+	set := func(key string, value []byte, ttl uint32) error {
+		return handlers[serverpb.RaftLogCommand_SetWithTTL](&serverpb.RaftLogPayload{
+			Command:   serverpb.RaftLogCommand_SetWithTTL,
+			Key:       []byte(key),
+			Value:     value,
+			Ttl:       &ttl,
+			Namespace: namespace,
+		})
+	}
+
+	assert.ErrorIs(t, set("k1", make([]byte, 17), 10), red.ErrQuotaExceeded)
+	assert.ErrorIs(t, set("k1", []byte("v"), 0), red.ErrQuotaExceeded)
+	assert.ErrorIs(t, set("k1", []byte("v"), 61), red.ErrQuotaExceeded)
+
+	assert.NoError(t, set("k1", []byte("v"), 10))
+	assert.NoError(t, set("k2", []byte("v"), 10))
+	assert.ErrorIs(t, set("k3", []byte("v"), 10), red.ErrQuotaExceeded)
+	// overwriting a key keeps the key count
+	assert.NoError(t, set("k2", []byte("value"), 10))
+
+	// other namespaces are not limited
+	assert.NoError(t, handlers[serverpb.RaftLogCommand_Set](&serverpb.RaftLogPayload{
+		Command: serverpb.RaftLogCommand_Set,
+		Key:     []byte("k3"),
+		Value:   make([]byte, 32),
+	}))
+
+	// an empty quota removes the limits
+	assert.NoError(t, handlers[serverpb.RaftLogCommand_NamespaceQuota](&serverpb.RaftLogPayload{
+		Command:   serverpb.RaftLogCommand_NamespaceQuota,
+		Namespace: namespace,
+	}))
+	q, err := red.LoadQuota(db, *namespace)
+	assert.NoError(t, err)
+	assert.Nil(t, q)
+	assert.NoError(t, set("k3", make([]byte, 32), 0))
+}
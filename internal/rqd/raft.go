@@ -2,6 +2,8 @@ package rqd
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"github.com/RealFax/RedQueen/internal/rqd/store"
 	"io"
 	"net"
@@ -25,13 +27,109 @@ type Raft struct {
 	stableStore   raft.StableStore
 	snapshotStore raft.SnapshotStore
 	transport     raft.Transport
+	tracker       *trackedTransport
 	ctx           context.Context
+	// keyring opens the encrypted snapshot a recovery is seeded with
+	keyring *store.Keyring
+	// legacySnapshot accepts seeding a recovery with a snapshot without manifest
+	legacySnapshot bool
+	// ready is set once the raft is created, the transport may call back before
+	ready atomic.Bool
 
 	*raft.Raft
 }
 
-func (r *Raft) AddCluster(id raft.ServerID, addr raft.ServerAddress) error {
-	return r.AddVoter(id, addr, 0, 30*time.Second).Error()
+// membershipTimeout bounds how long a membership change waits to be enqueued
+const membershipTimeout = 30 * time.Second
+
+var (
+	ErrServerNotFound = errors.New("server not found in the raft configuration")
+	ErrServerNotVoter = errors.New("server is not a voter")
+	ErrServerIsVoter  = errors.New("server is already a voter")
+)
+
+// servers returns the latest raft configuration, empty until the raft is created
+func (r *Raft) servers() []raft.Server {
+	if !r.ready.Load() {
+		return nil
+	}
+	future := r.GetConfiguration()
+	if future.Error() != nil {
+		return nil
+	}
+	return future.Configuration().Servers
+}
+
+// server returns the server of the latest raft configuration by id
+func (r *Raft) server(id raft.ServerID) (raft.Server, error) {
+	future := r.GetConfiguration()
+	if err := future.Error(); err != nil {
+		return raft.Server{}, err
+	}
+	for _, server := range future.Configuration().Servers {
+		if server.ID == id {
+			return server, nil
+		}
+	}
+	return raft.Server{}, errors.Wrap(ErrServerNotFound, string(id))
+}
+
+// AddCluster adds a server to the cluster, as a voter or as a learner (nonvoter) that only
+// replicates the log
+func (r *Raft) AddCluster(id raft.ServerID, addr raft.ServerAddress, voter bool) error {
+	if voter {
+		return r.AddVoter(id, addr, 0, membershipTimeout).Error()
+	}
+	return r.AddNonvoter(id, addr, 0, membershipTimeout).Error()
+}
+
+// RemoveCluster removes a server from the cluster, this is how a failed node is replaced
+func (r *Raft) RemoveCluster(id raft.ServerID) error {
+	if _, err := r.server(id); err != nil {
+		return err
+	}
+	return r.RemoveServer(id, 0, membershipTimeout).Error()
+}
+
+// DemoteCluster turns a voter into a learner
+func (r *Raft) DemoteCluster(id raft.ServerID) error {
+	server, err := r.server(id)
+	if err != nil {
+		return err
+	}
+	if server.Suffrage != raft.Voter {
+		return errors.Wrap(ErrServerNotVoter, string(id))
+	}
+	return r.DemoteVoter(id, 0, membershipTimeout).Error()
+}
+
+// PromoteCluster turns a learner into a voter
+func (r *Raft) PromoteCluster(id raft.ServerID) error {
+	server, err := r.server(id)
+	if err != nil {
+		return err
+	}
+	if server.Suffrage == raft.Voter {
+		return errors.Wrap(ErrServerIsVoter, string(id))
+	}
+	return r.AddVoter(id, server.Address, 0, membershipTimeout).Error()
+}
+
+// TransferLeadership hands the leadership over to the voter id, or to the most up to date
+// voter when id is empty
+func (r *Raft) TransferLeadership(id raft.ServerID) error {
+	if id == "" {
+		return r.LeadershipTransfer().Error()
+	}
+
+	server, err := r.server(id)
+	if err != nil {
+		return err
+	}
+	if server.Suffrage != raft.Voter {
+		return errors.Wrap(ErrServerNotVoter, string(id))
+	}
+	return r.LeadershipTransferToServer(server.ID, server.Address).Error()
 }
 
 func (r *Raft) Term() uint64 {
@@ -72,35 +170,68 @@ func RaftWithConfig(cfg *raft.Config) RaftServerOption {
 
 func RaftWithStdFSM(store store.Store) RaftServerOption {
 	return func(r *Raft) error {
+		handlers := newFSMHandlers(store)
 		r.fsm = &FSM{
 			Term:     &r.term,
-			Handlers: NewFSMHandlers(store),
+			Handlers: handlers.funcs(),
 			Store:    store,
+			Restored: handlers.restored,
 		}
 		return nil
 	}
 }
 
-func RaftWithBoltLogStore(path string) RaftServerOption {
-	return func(r *Raft) (err error) {
-		r.logStore, err = raftboltdb.NewBoltStore(path)
+func RaftWithKeyring(keyring *store.Keyring) RaftServerOption {
+	return func(r *Raft) error {
+		r.keyring = keyring
+		return nil
+	}
+}
+
+func RaftWithLegacySnapshot(legacy bool) RaftServerOption {
+	return func(r *Raft) error {
+		r.legacySnapshot = legacy
+		return nil
+	}
+}
+
+// RaftWithBoltLogStore keeps the raft log in a local bolt file, the data of the command logs is
+// sealed when keyring is not nil
+func RaftWithBoltLogStore(path string, keyring *store.Keyring) RaftServerOption {
+	return func(r *Raft) error {
+		logStore, err := raftboltdb.NewBoltStore(path)
 		if err != nil {
 			return errors.Wrap(err, "bolt-log-actions")
 		}
-		return
+		r.logStore = NewSealedLogStore(logStore, keyring)
+		return nil
 	}
 }
 
-func RaftWithStdStableStore(store store.Store) RaftServerOption {
+// RaftWithBoltStableStore keeps the raft term and vote in a local bolt file, which is never
+// included in snapshots. the state kept by the legacy stable store of legacy is migrated when
+// legacy is not nil
+func RaftWithBoltStableStore(path string, legacy store.Store) RaftServerOption {
 	return func(r *Raft) (err error) {
-		r.stableStore, err = NewStableStore(store)
-		if err != nil {
-			return errors.Wrap(err, "std-stable-actions")
-		}
+		r.stableStore, err = newBoltStableStore(path, legacy)
 		return
 	}
 }
 
+func newBoltStableStore(path string, legacy store.Store) (*raftboltdb.BoltStore, error) {
+	stableStore, err := raftboltdb.NewBoltStore(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "bolt-stable-actions")
+	}
+	if legacy != nil {
+		if err = MigrateStableStore(legacy, stableStore); err != nil {
+			_ = stableStore.Close()
+			return nil, errors.Wrap(err, "migrate-stable-actions")
+		}
+	}
+	return stableStore, nil
+}
+
 func RaftWithFileSnapshotStore(path string, retain int, logOut io.Writer) RaftServerOption {
 	return func(r *Raft) (err error) {
 		r.snapshotStore, err = raft.NewFileSnapshotStore(path, retain, logOut)
@@ -111,9 +242,11 @@ func RaftWithFileSnapshotStore(path string, retain int, logOut io.Writer) RaftSe
 	}
 }
 
-func RaftWithTCPTransport(addr string, maxPool int, timeout time.Duration, logOut io.Writer) RaftServerOption {
+// RaftWithTCPTransport binds the transport to addr, other members reach it at advertise which is
+// resolved once at startup
+func RaftWithTCPTransport(addr, advertise string, maxPool int, timeout time.Duration, logOut io.Writer) RaftServerOption {
 	return func(r *Raft) error {
-		tcpAddr, err := net.ResolveTCPAddr("tcp", addr)
+		tcpAddr, err := net.ResolveTCPAddr("tcp", advertise)
 		if err != nil {
 			return errors.Wrap(err, "resolve-tcp-addr")
 		}
@@ -124,6 +257,54 @@ func RaftWithTCPTransport(addr string, maxPool int, timeout time.Duration, logOu
 	}
 }
 
+// RaftWithMutualTLSTransport is RaftWithTCPTransport over mutual tls, the peers are verified
+// against the raft configuration
+func RaftWithMutualTLSTransport(
+	addr, advertise string,
+	maxPool int,
+	timeout time.Duration,
+	logOut io.Writer,
+	keyPair func() *tls.Certificate,
+	ca *x509.CertPool,
+) RaftServerOption {
+	return func(r *Raft) error {
+		tcpAddr, err := net.ResolveTCPAddr("tcp", advertise)
+		if err != nil {
+			return errors.Wrap(err, "resolve-tcp-addr")
+		}
+		if r.transport, err = NewMutualTLSTransport(addr, tcpAddr, maxPool, timeout, logOut, keyPair, ca, r.servers); err != nil {
+			return errors.Wrap(err, "mutual-tls-transport")
+		}
+		return nil
+	}
+}
+
+// RaftWithTransport uses a transport created by the caller, e.g. a raft.InmemTransport
+func RaftWithTransport(transport raft.Transport) RaftServerOption {
+	return func(r *Raft) error {
+		r.transport = transport
+		return nil
+	}
+}
+
+// RaftWithInmemStore keeps the raft log, stable state and snapshots in memory, the node
+// forgets everything on restart
+func RaftWithInmemStore() RaftServerOption {
+	return func(r *Raft) error {
+		inmem := raft.NewInmemStore()
+		r.logStore, r.stableStore, r.snapshotStore = inmem, inmem, raft.NewInmemSnapshotStore()
+		return nil
+	}
+}
+
+// RaftWithStores uses the raft log, stable state and snapshot stores created by the caller
+func RaftWithStores(logStore raft.LogStore, stableStore raft.StableStore, snapshotStore raft.SnapshotStore) RaftServerOption {
+	return func(r *Raft) error {
+		r.logStore, r.stableStore, r.snapshotStore = logStore, stableStore, snapshotStore
+		return nil
+	}
+}
+
 func NewRaftWithOptions(opts ...RaftServerOption) (*Raft, error) {
 	var (
 		err error
@@ -136,9 +317,15 @@ func NewRaftWithOptions(opts ...RaftServerOption) (*Raft, error) {
 		}
 	}
 
-	if r.Raft, err = raft.NewRaft(r.cfg, r.fsm, r.logStore, r.stableStore, r.snapshotStore, r.transport); err != nil {
+	if fsm, ok := r.fsm.(*FSM); ok && fsm.Logger == nil && r.cfg.Logger != nil {
+		fsm.Logger = r.cfg.Logger.Named("fsm")
+	}
+
+	r.tracker = newTrackedTransport(r.transport)
+	if r.Raft, err = raft.NewRaft(r.cfg, r.fsm, r.logStore, r.stableStore, r.snapshotStore, r.tracker); err != nil {
 		return nil, err
 	}
+	r.ready.Store(true)
 
 	if r.bootstrap {
 		if fErr := r.BootstrapCluster(raft.Configuration{
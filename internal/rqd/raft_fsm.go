@@ -6,6 +6,7 @@ import (
 	"io"
 	"sync/atomic"
 
+	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/raft"
 	"github.com/pkg/errors"
 
@@ -13,36 +14,57 @@ import (
 )
 
 type FSMHandleFunc func(*serverpb.RaftLogPayload) error
+
+// FSMApplyResponse is the result of each message of an applied log, in the order they were packed
+type FSMApplyResponse []error
+
 type FSM struct {
 	Term     *uint64
 	Handlers map[serverpb.RaftLogCommand]FSMHandleFunc
 	Store    store.Store
+	// Restored is called once the store is restored from a snapshot, nil is ignored
+	Restored func()
+	// Logger warns about the legacy snapshots, nil is ignored
+	Logger hclog.Logger
+
+	// index of the last applied log, raft never calls Apply and Snapshot concurrently
+	index uint64
 }
 
 func (f *FSM) Apply(log *raft.Log) any {
 	atomic.StoreUint64(f.Term, log.Term)
+	f.index = log.Index
 	switch log.Type {
 	case raft.LogCommand:
+		// the usage is accounted at the time the leader appended the log, so that every member
+		// accepts the same writes whatever its clock
+		if !log.AppendedAt.IsZero() {
+			f.Store.SetApplyTime(log.AppendedAt)
+		}
 		messages, err := UnpackLog(bytes.NewReader(log.Data))
 		if err != nil {
 			return err
 		}
-		for _, message := range messages {
+		// a failed message must not stop the other messages merged into the same log
+		resp := make(FSMApplyResponse, len(messages))
+		for i, message := range messages {
 			handle, ok := f.Handlers[message.Command]
 			if !ok {
-				return errors.Errorf("unimplemented command %s handler", message.Command.String())
-			}
-			if err = handle(message); err != nil {
-				return err
+				resp[i] = errors.Errorf("unimplemented command %s handler", message.Command.String())
+				continue
 			}
+			resp[i] = handle(message)
 		}
-		return nil
+		return resp
 	}
 	return nil
 }
 
 func (f *FSM) Snapshot() (raft.FSMSnapshot, error) {
-	snapshot, err := f.Store.Snapshot()
+	snapshot, err := f.Store.Snapshot(store.SnapshotMeta{
+		Index: f.index,
+		Term:  atomic.LoadUint64(f.Term),
+	})
 	if err != nil {
 		return nil, errors.Wrap(err, "create snapshot fail")
 	}
@@ -51,5 +73,22 @@ func (f *FSM) Snapshot() (raft.FSMSnapshot, error) {
 
 func (f *FSM) Restore(rc io.ReadCloser) error {
 	defer rc.Close()
-	return f.Store.Restore(rc)
+	// a failed restore may have installed the snapshot as well
+	if f.Restored != nil {
+		defer f.Restored()
+	}
+	meta, err := f.Store.Restore(rc)
+	if err != nil {
+		return err
+	}
+	if meta.Legacy && f.Logger != nil {
+		f.Logger.Warn("restored a legacy snapshot without manifest, it was installed without checksums")
+	}
+	// the snapshots taken before the next log record the position of the restored one, legacy
+	// snapshots don't know it
+	if meta.Index != 0 {
+		f.index = meta.Index
+		atomic.StoreUint64(f.Term, meta.Term)
+	}
+	return nil
 }
@@ -1,14 +1,21 @@
 package rqd
 
 import (
+	"slices"
+
 	"github.com/RealFax/RedQueen/internal/rqd/store"
 	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/RealFax/RedQueen/api/serverpb"
 )
 
 type FSMHandlers struct {
 	store store.Store
+
+	// quotas caches the quota of each namespace, nil means the namespace has no quota. it is
+	// kept by the NamespaceQuota logs and dropped once the store is restored
+	quotas map[string]*serverpb.Quota
 }
 
 func (h *FSMHandlers) swap(namespace *string) (store.Actions, error) {
@@ -22,6 +29,31 @@ func (h *FSMHandlers) swap(namespace *string) (store.Actions, error) {
 	return actions, nil
 }
 
+// quota returns the quota of namespace, it is only loaded from the store once
+func (h *FSMHandlers) quota(namespace string) (*serverpb.Quota, error) {
+	if quota, ok := h.quotas[namespace]; ok {
+		return quota, nil
+	}
+	quota, err := LoadQuota(h.store, namespace)
+	if err != nil {
+		return nil, err
+	}
+	h.quotas[namespace] = quota
+	return quota, nil
+}
+
+func (h *FSMHandlers) checkQuota(namespace *string, key, value []byte, ttl uint32) error {
+	name := store.DefaultNamespace
+	if namespace != nil {
+		name = *namespace
+	}
+	quota, err := h.quota(name)
+	if err != nil {
+		return err
+	}
+	return CheckQuota(h.store, quota, name, key, value, ttl)
+}
+
 func (h *FSMHandlers) SetWithTTL(payload *serverpb.RaftLogPayload) error {
 	if payload.Ttl == nil || payload.Key == nil || payload.Value == nil {
 		return errors.New("invalid SetWithTTl args")
@@ -32,6 +64,10 @@ func (h *FSMHandlers) SetWithTTL(payload *serverpb.RaftLogPayload) error {
 		return err
 	}
 
+	if err = h.checkQuota(payload.Namespace, payload.Key, payload.Value, *payload.Ttl); err != nil {
+		return err
+	}
+
 	return dest.SetWithTTL(payload.Key, payload.Value, *payload.Ttl)
 }
 
@@ -45,6 +81,10 @@ func (h *FSMHandlers) TrySetWithTTL(payload *serverpb.RaftLogPayload) error {
 		return err
 	}
 
+	if err = h.checkQuota(payload.Namespace, payload.Key, payload.Value, *payload.Ttl); err != nil {
+		return err
+	}
+
 	return dest.TrySetWithTTL(payload.Key, payload.Value, *payload.Ttl)
 }
 
@@ -58,6 +98,10 @@ func (h *FSMHandlers) Set(payload *serverpb.RaftLogPayload) error {
 		return err
 	}
 
+	if err = h.checkQuota(payload.Namespace, payload.Key, payload.Value, 0); err != nil {
+		return err
+	}
+
 	return dest.Set(payload.Key, payload.Value)
 }
 
@@ -71,6 +115,10 @@ func (h *FSMHandlers) TrySet(payload *serverpb.RaftLogPayload) error {
 		return err
 	}
 
+	if err = h.checkQuota(payload.Namespace, payload.Key, payload.Value, 0); err != nil {
+		return err
+	}
+
 	return dest.TrySet(payload.Key, payload.Value)
 }
 
@@ -87,14 +135,187 @@ func (h *FSMHandlers) Del(payload *serverpb.RaftLogPayload) error {
 	return dest.Del(payload.Key)
 }
 
+func (h *FSMHandlers) NamespaceCreate(payload *serverpb.RaftLogPayload) error {
+	if payload.Namespace == nil {
+		return errors.New("invalid NamespaceCreate args")
+	}
+	return h.store.CreateNamespace(*payload.Namespace)
+}
+
+func (h *FSMHandlers) NamespaceDrop(payload *serverpb.RaftLogPayload) error {
+	if payload.Namespace == nil {
+		return errors.New("invalid NamespaceDrop args")
+	}
+	return h.store.DropNamespace(*payload.Namespace)
+}
+
+func (h *FSMHandlers) NamespaceQuota(payload *serverpb.RaftLogPayload) error {
+	if payload.Namespace == nil {
+		return errors.New("invalid NamespaceQuota args")
+	}
+	quota := &serverpb.Quota{}
+	if err := proto.Unmarshal(payload.Value, quota); err != nil {
+		return errors.Wrap(err, "unmarshal quota error")
+	}
+	if err := StoreQuota(h.store, *payload.Namespace, quota); err != nil {
+		return err
+	}
+	if quotaEmpty(quota) {
+		quota = nil
+	}
+	h.quotas[*payload.Namespace] = quota
+	return nil
+}
+
+func (h *FSMHandlers) AuthUser(payload *serverpb.RaftLogPayload) error {
+	if payload.Key == nil {
+		return errors.New("invalid AuthUser args")
+	}
+	return storeAuthRecord(h.store, AuthUserNamespace, string(payload.Key), payload.Value)
+}
+
+func (h *FSMHandlers) AuthRole(payload *serverpb.RaftLogPayload) error {
+	if payload.Key == nil {
+		return errors.New("invalid AuthRole args")
+	}
+	return storeAuthRecord(h.store, AuthRoleNamespace, string(payload.Key), payload.Value)
+}
+
+// AuthUserCreate stores the user, ErrUserAlreadyExists when it exists at apply time
+func (h *FSMHandlers) AuthUserCreate(payload *serverpb.RaftLogPayload) error {
+	if payload.Key == nil || payload.Value == nil {
+		return errors.New("invalid AuthUserCreate args")
+	}
+	if _, err := LoadUser(h.store, string(payload.Key)); err == nil {
+		return ErrUserAlreadyExists
+	} else if !errors.Is(err, ErrUserNotFound) {
+		return err
+	}
+	return storeAuthRecord(h.store, AuthUserNamespace, string(payload.Key), payload.Value)
+}
+
+// AuthRoleCreate stores the role, ErrRoleAlreadyExists when it exists at apply time
+func (h *FSMHandlers) AuthRoleCreate(payload *serverpb.RaftLogPayload) error {
+	if payload.Key == nil || payload.Value == nil {
+		return errors.New("invalid AuthRoleCreate args")
+	}
+	if _, err := LoadRole(h.store, string(payload.Key)); err == nil {
+		return ErrRoleAlreadyExists
+	} else if !errors.Is(err, ErrRoleNotFound) {
+		return err
+	}
+	return storeAuthRecord(h.store, AuthRoleNamespace, string(payload.Key), payload.Value)
+}
+
+func (h *FSMHandlers) AuthUserGrantRole(payload *serverpb.RaftLogPayload) error {
+	if payload.Key == nil || payload.Value == nil {
+		return errors.New("invalid AuthUserGrantRole args")
+	}
+	role := string(payload.Value)
+	if _, err := LoadRole(h.store, role); err != nil {
+		return err
+	}
+	return updateUser(h.store, string(payload.Key), func(user *serverpb.User) error {
+		if !slices.Contains(user.Roles, role) {
+			user.Roles = append(user.Roles, role)
+		}
+		return nil
+	})
+}
+
+func (h *FSMHandlers) AuthUserRevokeRole(payload *serverpb.RaftLogPayload) error {
+	if payload.Key == nil || payload.Value == nil {
+		return errors.New("invalid AuthUserRevokeRole args")
+	}
+	return updateUser(h.store, string(payload.Key), func(user *serverpb.User) error {
+		user.Roles = slices.DeleteFunc(user.Roles, func(role string) bool { return role == string(payload.Value) })
+		return nil
+	})
+}
+
+func (h *FSMHandlers) AuthUserPassword(payload *serverpb.RaftLogPayload) error {
+	if payload.Key == nil {
+		return errors.New("invalid AuthUserPassword args")
+	}
+	credentials := &serverpb.User{}
+	if err := proto.Unmarshal(payload.Value, credentials); err != nil {
+		return errors.Wrap(err, "unmarshal user error")
+	}
+	return updateUser(h.store, string(payload.Key), func(user *serverpb.User) error {
+		user.PasswordHash, user.CredentialsChangedAt = credentials.PasswordHash, credentials.CredentialsChangedAt
+		return nil
+	})
+}
+
+func (h *FSMHandlers) AuthRoleGrantPermission(payload *serverpb.RaftLogPayload) error {
+	if payload.Key == nil {
+		return errors.New("invalid AuthRoleGrantPermission args")
+	}
+	grant := &serverpb.Grant{}
+	if err := proto.Unmarshal(payload.Value, grant); err != nil {
+		return errors.Wrap(err, "unmarshal grant error")
+	}
+	return updateRole(h.store, string(payload.Key), func(role *serverpb.Role) error {
+		if idx := grantIndex(role.Grants, grant.Namespace, grant.Prefix); idx != -1 {
+			role.Grants[idx] = grant
+		} else {
+			role.Grants = append(role.Grants, grant)
+		}
+		return nil
+	})
+}
+
+func (h *FSMHandlers) AuthRoleRevokePermission(payload *serverpb.RaftLogPayload) error {
+	if payload.Key == nil {
+		return errors.New("invalid AuthRoleRevokePermission args")
+	}
+	grant := &serverpb.Grant{}
+	if err := proto.Unmarshal(payload.Value, grant); err != nil {
+		return errors.Wrap(err, "unmarshal grant error")
+	}
+	return updateRole(h.store, string(payload.Key), func(role *serverpb.Role) error {
+		idx := grantIndex(role.Grants, grant.Namespace, grant.Prefix)
+		if idx == -1 {
+			return ErrGrantNotFound
+		}
+		role.Grants = slices.Delete(role.Grants, idx, idx+1)
+		return nil
+	})
+}
+
+// restored drops the state cached from the store, once the store is restored from a snapshot
+func (h *FSMHandlers) restored() {
+	h.quotas = make(map[string]*serverpb.Quota)
+}
+
+func newFSMHandlers(s store.Store) *FSMHandlers {
+	return &FSMHandlers{store: s, quotas: make(map[string]*serverpb.Quota)}
+}
+
 func NewFSMHandlers(s store.Store) map[serverpb.RaftLogCommand]FSMHandleFunc {
-	handlers := &FSMHandlers{store: s}
+	return newFSMHandlers(s).funcs()
+}
 
+func (h *FSMHandlers) funcs() map[serverpb.RaftLogCommand]FSMHandleFunc {
 	return map[serverpb.RaftLogCommand]FSMHandleFunc{
-		serverpb.RaftLogCommand_SetWithTTL:    handlers.SetWithTTL,
-		serverpb.RaftLogCommand_TrySetWithTTL: handlers.TrySetWithTTL,
-		serverpb.RaftLogCommand_Set:           handlers.Set,
-		serverpb.RaftLogCommand_TrySet:        handlers.TrySet,
-		serverpb.RaftLogCommand_Del:           handlers.Del,
+		serverpb.RaftLogCommand_SetWithTTL:    h.SetWithTTL,
+		serverpb.RaftLogCommand_TrySetWithTTL: h.TrySetWithTTL,
+		serverpb.RaftLogCommand_Set:           h.Set,
+		serverpb.RaftLogCommand_TrySet:        h.TrySet,
+		serverpb.RaftLogCommand_Del:           h.Del,
+
+		serverpb.RaftLogCommand_NamespaceCreate: h.NamespaceCreate,
+		serverpb.RaftLogCommand_NamespaceDrop:   h.NamespaceDrop,
+		serverpb.RaftLogCommand_NamespaceQuota:  h.NamespaceQuota,
+
+		serverpb.RaftLogCommand_AuthUser:                 h.AuthUser,
+		serverpb.RaftLogCommand_AuthRole:                 h.AuthRole,
+		serverpb.RaftLogCommand_AuthUserCreate:           h.AuthUserCreate,
+		serverpb.RaftLogCommand_AuthRoleCreate:           h.AuthRoleCreate,
+		serverpb.RaftLogCommand_AuthUserGrantRole:        h.AuthUserGrantRole,
+		serverpb.RaftLogCommand_AuthUserRevokeRole:       h.AuthUserRevokeRole,
+		serverpb.RaftLogCommand_AuthUserPassword:         h.AuthUserPassword,
+		serverpb.RaftLogCommand_AuthRoleGrantPermission:  h.AuthRoleGrantPermission,
+		serverpb.RaftLogCommand_AuthRoleRevokePermission: h.AuthRoleRevokePermission,
 	}
 }
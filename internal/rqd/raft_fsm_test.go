@@ -0,0 +1,29 @@
+package rqd_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/RealFax/RedQueen/api/serverpb"
+	red "github.com/RealFax/RedQueen/internal/rqd"
+	"github.com/RealFax/RedQueen/internal/rqd/store"
+	"github.com/RealFax/RedQueen/pkg/expr"
+	"github.com/hashicorp/raft"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFSM_ApplyResponse(t *testing.T) {
+	db := newTestStore(t)
+	fsm := &red.FSM{Term: new(uint64), Handlers: red.NewFSMHandlers(db), Store: db}
+
+	// the errors of the handlers reach the caller of the log
+	applyer := red.NewRaftSingeLogApply(func(cmd []byte, _ time.Duration) raft.ApplyFuture {
+		return &future{response: fsm.Apply(&raft.Log{Index: 1, Type: raft.LogCommand, Data: cmd})}
+	})
+	drop := &serverpb.RaftLogPayload{Command: serverpb.RaftLogCommand_NamespaceDrop, Namespace: expr.Pointer("missing")}
+	assert.ErrorIs(t, applyer.Apply(nil, drop, time.Second), store.ErrNamespaceNotFound)
+
+	require.NoError(t, db.CreateNamespace("missing"))
+	assert.ErrorIs(t, applyer.Apply(nil, drop, time.Second), red.ErrApplyLogDone)
+}
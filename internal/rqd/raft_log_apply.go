@@ -22,6 +22,7 @@ import (
 var (
 	ErrApplyLogTimeTravelDone = errors.New("raft apply log time-travel done")
 	ErrApplyLogDone           = errors.New("raft apply log done")
+	ErrApplyLogClosed         = errors.New("raft log applier closed")
 )
 
 func RaftLogPayloadKey(m *serverpb.RaftLogPayload) uint64 {
@@ -44,17 +45,39 @@ func RaftLogPayloadKey(m *serverpb.RaftLogPayload) uint64 {
 	return x.Sum64()
 }
 
+// applyResponseError returns the error of the i-th message of an applied log
+func applyResponseError(resp raft.ApplyFuture, i int) error {
+	if err := resp.Error(); err != nil {
+		return err
+	}
+	switch r := resp.Response().(type) {
+	case error:
+		return r
+	case FSMApplyResponse:
+		if i < len(r) {
+			return r[i]
+		}
+	}
+	return nil
+}
+
 type (
 	ApplyFunc func(cmd []byte, timeout time.Duration) raft.ApplyFuture
 	RaftApply interface {
 		Apply(ctx *context.Context, m *serverpb.RaftLogPayload, timeout time.Duration) error
+		// Close rejects further logs, the pending ones are applied before it returns
+		Close() error
 	}
 	raftSingleLogApplyer struct {
-		apply ApplyFunc
+		closed atomic.Bool
+		apply  ApplyFunc
 	}
 )
 
 func (a *raftSingleLogApplyer) Apply(_ *context.Context, m *serverpb.RaftLogPayload, timeout time.Duration) error {
+	if a.closed.Load() {
+		return ErrApplyLogClosed
+	}
 	b := LogPackHeader(SingleLogPack)
 	cmd, err := proto.Marshal(m)
 	if err != nil {
@@ -62,13 +85,18 @@ func (a *raftSingleLogApplyer) Apply(_ *context.Context, m *serverpb.RaftLogPayl
 	}
 	b = append(b, cmd...)
 
-	resp := a.apply(b, timeout)
-	if resp.Error() != nil {
-		return resp.Error()
+	if err = applyResponseError(a.apply(b, timeout), 0); err != nil {
+		return err
 	}
 	return ErrApplyLogDone
 }
 
+// Close only rejects further logs, the logs are applied synchronously
+func (a *raftSingleLogApplyer) Close() error {
+	a.closed.Store(true)
+	return nil
+}
+
 func NewRaftSingeLogApply(fc ApplyFunc) RaftApply {
 	return &raftSingleLogApplyer{apply: fc}
 }
@@ -86,19 +114,16 @@ type raftMultipleLogApply struct {
 	maxLimit               int32
 	deadline, applyTimeout time.Duration
 	ctx                    context.Context
+	cancel                 context.CancelFunc
 	applyFunc              ApplyFunc
 	onMerge                chan struct{}
+	listening              chan struct{}
+	merging                sync.WaitGroup
+	closed                 bool // [rwm]
 	rwm                    sync.RWMutex
 	filter                 orderMap.Map[uint64, *logApplyEntry]
 }
 
-func (a *raftMultipleLogApply) reset() {
-	a.rwm.Lock()
-	a.counter.Store(0)
-	a.filter = orderMap.New[uint64, *logApplyEntry]()
-	a.rwm.Unlock()
-}
-
 func (a *raftMultipleLogApply) merge() {
 	a.rwm.Lock() // stop recv apply request
 	var (
@@ -113,10 +138,15 @@ func (a *raftMultipleLogApply) merge() {
 		off++
 		return true
 	})
+	// reset state with start recv apply request, under the same lock so that no request
+	// stored after the range is dropped
+	a.counter.Store(0)
+	a.filter = orderMap.New[uint64, *logApplyEntry]()
 	a.rwm.Unlock()
 
-	// reset state with start recv apply request
-	a.reset()
+	if size == 0 {
+		return
+	}
 
 	buf := bufferPool.Get().(*bytes.Buffer)
 	defer bufferPool.Put(buf)
@@ -126,23 +156,24 @@ func (a *raftMultipleLogApply) merge() {
 	w.Encode(buf)
 
 	// apply log to followers
-	var (
-		err  = ErrApplyLogDone
-		resp = a.applyFunc(buf.Bytes(), a.applyTimeout)
-	)
-
-	if resp.Error() != nil {
-		err = resp.Error()
-	}
+	resp := a.applyFunc(buf.Bytes(), a.applyTimeout)
 
 	// response
-	for _, causeFunc := range notify {
-		causeFunc(err)
+	for i, causeFunc := range notify {
+		if err := applyResponseError(resp, i); err != nil {
+			causeFunc(err)
+			continue
+		}
+		causeFunc(ErrApplyLogDone)
 	}
 }
 
+// runMerge never blocks, a pending merge request covers this one as well
 func (a *raftMultipleLogApply) runMerge() {
-	a.onMerge <- struct{}{}
+	select {
+	case a.onMerge <- struct{}{}:
+	default:
+	}
 }
 
 func (a *raftMultipleLogApply) fullCounter() {
@@ -155,6 +186,7 @@ func (a *raftMultipleLogApply) fullCounter() {
 func (a *raftMultipleLogApply) listen() {
 	ticker := time.NewTicker(a.deadline)
 	defer ticker.Stop()
+	defer close(a.listening)
 	for {
 		select {
 		case <-a.ctx.Done():
@@ -165,7 +197,11 @@ func (a *raftMultipleLogApply) listen() {
 			if a.counter.Load() == 0 {
 				continue
 			}
-			go a.merge()
+			a.merging.Add(1)
+			go func() {
+				defer a.merging.Done()
+				a.merge()
+			}()
 		}
 	}
 }
@@ -182,6 +218,10 @@ func (a *raftMultipleLogApply) Apply(ctx *context.Context, m *serverpb.RaftLogPa
 	key := RaftLogPayloadKey(m)
 
 	a.rwm.RLock()
+	if a.closed {
+		a.rwm.RUnlock()
+		return ErrApplyLogClosed
+	}
 	val, ok := a.filter.Load(key)
 	if ok {
 		a.filter.Delete(key)
@@ -197,6 +237,25 @@ func (a *raftMultipleLogApply) Apply(ctx *context.Context, m *serverpb.RaftLogPa
 	return nil
 }
 
+// Close stops merging and applies the pending logs as the last batch
+func (a *raftMultipleLogApply) Close() error {
+	a.rwm.Lock()
+	if a.closed {
+		a.rwm.Unlock()
+		return ErrApplyLogClosed
+	}
+	a.closed = true
+	a.rwm.Unlock()
+
+	a.cancel()
+	<-a.listening
+	a.merging.Wait()
+	if a.counter.Load() != 0 {
+		a.merge()
+	}
+	return nil
+}
+
 func NewRaftMultipleLogApply(
 	ctx context.Context,
 	maxLimit int32,
@@ -208,12 +267,13 @@ func NewRaftMultipleLogApply(
 		maxLimit:     maxLimit,
 		deadline:     deadline,
 		applyTimeout: applyTimeout,
-		ctx:          ctx,
 		applyFunc:    af,
 		onMerge:      make(chan struct{}, 1),
+		listening:    make(chan struct{}),
 		rwm:          sync.RWMutex{},
 		filter:       orderMap.New[uint64, *logApplyEntry](),
 	}
+	m.ctx, m.cancel = context.WithCancel(ctx)
 
 	go m.listen()
 
@@ -4,9 +4,11 @@ import (
 	"context"
 	"github.com/RealFax/RedQueen/api/serverpb"
 	red "github.com/RealFax/RedQueen/internal/rqd"
+	"github.com/RealFax/RedQueen/internal/rqd/store"
 	"github.com/RealFax/RedQueen/pkg/expr"
 	"github.com/hashicorp/raft"
 	"github.com/stretchr/testify/assert"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -41,6 +43,11 @@ func TestRaftSingleLogApplyer_Apply(t *testing.T) {
 		return &future{}
 	})
 	assert.Equal(t, applyer.Apply(nil, &serverpb.RaftLogPayload{}, 1*time.Second), red.ErrApplyLogDone)
+
+	applyer = red.NewRaftSingeLogApply(func(cmd []byte, timeout time.Duration) raft.ApplyFuture {
+		return &future{response: red.FSMApplyResponse{store.ErrKeyAlreadyExists}}
+	})
+	assert.ErrorIs(t, applyer.Apply(nil, &serverpb.RaftLogPayload{}, 1*time.Second), store.ErrKeyAlreadyExists)
 }
 
 func TestRaftMultipleLogApply_Apply(t *testing.T) {
@@ -57,6 +64,41 @@ func TestRaftMultipleLogApply_Apply(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestRaftSingleLogApplyer_Close(t *testing.T) {
+	applyer := red.NewRaftSingeLogApply(func(cmd []byte, timeout time.Duration) raft.ApplyFuture {
+		return &future{}
+	})
+	assert.NoError(t, applyer.Close())
+	assert.ErrorIs(t, applyer.Apply(nil, &serverpb.RaftLogPayload{}, time.Second), red.ErrApplyLogClosed)
+}
+
+func TestRaftMultipleLogApply_Close(t *testing.T) {
+	var applied atomic.Int32
+	raftApply := red.NewRaftMultipleLogApply(
+		context.Background(),
+		10,
+		time.Hour, // never merged by the deadline
+		2*time.Second,
+		func(data []byte, timeout time.Duration) raft.ApplyFuture {
+			applied.Add(1)
+			return &future{}
+		},
+	)
+
+	ctx := context.Background()
+	assert.NoError(t, raftApply.Apply(&ctx, &serverpb.RaftLogPayload{Key: []byte("pending")}, time.Second))
+
+	// the pending log is applied as the last batch
+	assert.NoError(t, raftApply.Close())
+	assert.Equal(t, int32(1), applied.Load())
+	<-ctx.Done()
+	assert.ErrorIs(t, context.Cause(ctx), red.ErrApplyLogDone)
+
+	ctx = context.Background()
+	assert.ErrorIs(t, raftApply.Apply(&ctx, &serverpb.RaftLogPayload{}, time.Second), red.ErrApplyLogClosed)
+	assert.ErrorIs(t, raftApply.Close(), red.ErrApplyLogClosed)
+}
+
 func BenchmarkRaftLogPayloadKey(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		red.RaftLogPayloadKey(raftLogPayloadMessage)
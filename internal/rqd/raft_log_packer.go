@@ -45,22 +45,20 @@ func unpackMultipleLog(r io.Reader) ([]*serverpb.RaftLogPayload, error) {
 	if err != nil {
 		return nil, err
 	}
-	var (
-		logs []*serverpb.RaftLogPayload
-		m    serverpb.RaftLogPayload
-	)
+	var logs []*serverpb.RaftLogPayload
 	for {
 		b, rErr := cr.Next()
 		if rErr != nil {
 			if rErr == io.EOF {
 				return logs, nil
 			}
-			return nil, err
+			return nil, rErr
 		}
-		if err = proto.Unmarshal(b, &m); err != nil {
+		m := &serverpb.RaftLogPayload{}
+		if err = proto.Unmarshal(b, m); err != nil {
 			return nil, errors.Wrap(err, "unmarshal raft log error")
 		}
-		logs = append(logs, &m)
+		logs = append(logs, m)
 	}
 }
 
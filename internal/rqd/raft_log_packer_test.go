@@ -2,8 +2,11 @@ package rqd_test
 
 import (
 	"bytes"
+	"github.com/RealFax/RedQueen/api/serverpb"
 	red "github.com/RealFax/RedQueen/internal/rqd"
+	"github.com/RealFax/RedQueen/pkg/collapsar"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
 	"testing"
 )
 
@@ -24,3 +27,24 @@ func TestGetLogPackHeader(t *testing.T) {
 	typ = red.GetLogPackHeader(buf)
 	assert.Equal(t, uint32(0x01), typ)
 }
+
+func TestUnpackLog(t *testing.T) {
+	w := collapsar.NewWriter(2)
+	for _, key := range []string{"key1", "key2"} {
+		b, err := proto.Marshal(&serverpb.RaftLogPayload{Command: serverpb.RaftLogCommand_Del, Key: []byte(key)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Add(b)
+	}
+
+	buf := bytes.NewBuffer(red.LogPackHeader(red.MultipleLogPack))
+	assert.NoError(t, w.Encode(buf))
+
+	messages, err := red.UnpackLog(buf)
+	assert.NoError(t, err)
+	if assert.Len(t, messages, 2) {
+		assert.Equal(t, []byte("key1"), messages[0].Key)
+		assert.Equal(t, []byte("key2"), messages[1].Key)
+	}
+}
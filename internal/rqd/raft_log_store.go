@@ -0,0 +1,78 @@
+package rqd
+
+import (
+	"bytes"
+
+	"github.com/RealFax/RedQueen/internal/rqd/store"
+	"github.com/hashicorp/raft"
+	"github.com/pkg/errors"
+)
+
+// logSealedExtension marks the logs sealed by a sealedLogStore. the extensions are only set by
+// the server, the data of a client can't fake the mark
+var logSealedExtension = []byte("rq-sealed")
+
+// sealedLogStore seals the data of the command logs before they reach the log store, the logs
+// are opened when they are read back. the logs stored before the encryption was enabled are
+// read as is
+type sealedLogStore struct {
+	raft.LogStore
+	keyring *store.Keyring
+}
+
+// NewSealedLogStore wraps logs with keyring, logs is returned as is when keyring is nil
+func NewSealedLogStore(logs raft.LogStore, keyring *store.Keyring) raft.LogStore {
+	if keyring == nil {
+		return logs
+	}
+	return &sealedLogStore{LogStore: logs, keyring: keyring}
+}
+
+// seal returns a sealed copy of log, raft keeps using the log it stores
+func (s *sealedLogStore) seal(log *raft.Log) (*raft.Log, error) {
+	if log.Type != raft.LogCommand {
+		return log, nil
+	}
+	data, err := s.keyring.Seal(log.Data)
+	if err != nil {
+		return nil, errors.Wrap(err, "seal raft log")
+	}
+	sealed := *log
+	sealed.Data = data
+	sealed.Extensions = append(bytes.Clone(logSealedExtension), log.Extensions...)
+	return &sealed, nil
+}
+
+func (s *sealedLogStore) GetLog(index uint64, log *raft.Log) error {
+	if err := s.LogStore.GetLog(index, log); err != nil {
+		return err
+	}
+	if !bytes.HasPrefix(log.Extensions, logSealedExtension) {
+		return nil
+	}
+	data, err := s.keyring.Open(log.Data)
+	if err != nil {
+		return errors.Wrap(err, "open raft log")
+	}
+	log.Data = data
+	log.Extensions = log.Extensions[len(logSealedExtension):]
+	if len(log.Extensions) == 0 {
+		log.Extensions = nil
+	}
+	return nil
+}
+
+func (s *sealedLogStore) StoreLog(log *raft.Log) error {
+	return s.StoreLogs([]*raft.Log{log})
+}
+
+func (s *sealedLogStore) StoreLogs(logs []*raft.Log) error {
+	sealed := make([]*raft.Log, len(logs))
+	for i, log := range logs {
+		var err error
+		if sealed[i], err = s.seal(log); err != nil {
+			return err
+		}
+	}
+	return s.LogStore.StoreLogs(sealed)
+}
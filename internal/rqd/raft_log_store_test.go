@@ -0,0 +1,97 @@
+package rqd_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/RealFax/RedQueen/api/serverpb"
+	red "github.com/RealFax/RedQueen/internal/rqd"
+	"github.com/RealFax/RedQueen/internal/rqd/store"
+	"github.com/RealFax/RedQueen/internal/rqd/store/nuts"
+	"github.com/hashicorp/raft"
+	raftboltdb "github.com/hashicorp/raft-boltdb/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSealedLogStore(t *testing.T) {
+	var (
+		dir    = t.TempDir()
+		secret = []byte("customer-token-0123456789")
+	)
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	keyring, err := store.NewKeyring(key)
+	require.NoError(t, err)
+
+	db, err := nuts.New(nuts.Config{
+		NodeNum: 1,
+		DataDir: filepath.Join(dir, red.StoreSuffix),
+		RWMode:  nuts.FileIO,
+		History: nuts.History{MaxVersions: 2},
+		Keyring: keyring,
+	})
+	require.NoError(t, err)
+	defer db.Close()
+
+	bolt, err := raftboltdb.NewBoltStore(filepath.Join(dir, red.RaftLog))
+	require.NoError(t, err)
+	defer bolt.Close()
+	logs := red.NewSealedLogStore(bolt, keyring)
+
+	node := &recoverNode{db: db, snapshot: raft.NewInmemSnapshotStore()}
+	r, err := red.NewRaftWithOptions(append(node.options(
+		red.RaftWithBootstrap(),
+		recoverClusters(),
+	), red.RaftWithStores(logs, bolt, node.snapshot))...)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return r.State() == raft.Leader
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.ErrorIs(t, red.NewRaftSingeLogApply(r.Apply).Apply(nil, &serverpb.RaftLogPayload{
+		Command: serverpb.RaftLogCommand_Set,
+		Key:     []byte("token/1"),
+		Value:   secret,
+	}, time.Second), red.ErrApplyLogDone)
+	require.NoError(t, r.Shutdown().Error())
+
+	// the logs are read back in plaintext
+	last, err := logs.LastIndex()
+	require.NoError(t, err)
+	var log raft.Log
+	require.NoError(t, logs.GetLog(last, &log))
+	assert.True(t, bytes.Contains(log.Data, secret))
+	assert.Empty(t, log.Extensions)
+	require.NoError(t, bolt.GetLog(last, &log))
+	assert.False(t, bytes.Contains(log.Data, secret))
+
+	require.NoError(t, db.Close())
+	require.NoError(t, bolt.Close())
+
+	// neither the store nor the raft log keep the plaintext
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		assert.False(t, bytes.Contains(b, secret), "plaintext in %s", path)
+		return nil
+	})
+	assert.NoError(t, err)
+
+	// the logs stored before the encryption was enabled are read as is
+	plain := raft.NewInmemStore()
+	require.NoError(t, plain.StoreLog(&raft.Log{Index: 1, Type: raft.LogCommand, Data: secret}))
+	require.NoError(t, red.NewSealedLogStore(plain, keyring).GetLog(1, &log))
+	assert.Equal(t, secret, log.Data)
+}
@@ -0,0 +1,152 @@
+package rqd
+
+import (
+	"maps"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/raft"
+)
+
+// peerContact is what the leader learns about a follower from its AppendEntries responses
+type peerContact struct {
+	lastContact time.Time
+	// lastLog is the last log index reported by the follower
+	lastLog uint64
+	// commitIndex is the commit index the follower has been told about, bounded by lastLog
+	commitIndex uint64
+}
+
+// trackedTransport records the AppendEntries responses of the followers. heartbeats are always
+// sent through AppendEntries, so the records stay fresh while the replication is pipelined.
+// the records are dropped when a new leadership starts, a leader only reports its own
+type trackedTransport struct {
+	raft.Transport
+
+	mu    sync.RWMutex
+	term  uint64
+	peers map[raft.ServerID]peerContact
+}
+
+func (t *trackedTransport) AppendEntries(
+	id raft.ServerID,
+	target raft.ServerAddress,
+	args *raft.AppendEntriesRequest,
+	resp *raft.AppendEntriesResponse,
+) error {
+	if err := t.Transport.AppendEntries(id, target, args, resp); err != nil {
+		return err
+	}
+
+	contact := peerContact{
+		lastContact: time.Now(),
+		lastLog:     resp.LastLog,
+		commitIndex: min(args.LeaderCommitIndex, resp.LastLog),
+	}
+	t.mu.Lock()
+	switch {
+	case args.Term > t.term:
+		t.term = args.Term
+		clear(t.peers)
+		fallthrough
+	case args.Term == t.term:
+		t.peers[id] = contact
+	}
+	t.mu.Unlock()
+	return nil
+}
+
+func (t *trackedTransport) contact(id raft.ServerID) (peerContact, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	contact, ok := t.peers[id]
+	return contact, ok
+}
+
+// retain drops the records of the servers that left the configuration
+func (t *trackedTransport) retain(servers []raft.Server) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	maps.DeleteFunc(t.peers, func(id raft.ServerID, _ peerContact) bool {
+		return !slices.ContainsFunc(servers, func(server raft.Server) bool { return server.ID == id })
+	})
+}
+
+// Close implements raft.WithClose, so that the wrapped transport is still closed on shutdown
+func (t *trackedTransport) Close() error {
+	if closer, ok := t.Transport.(raft.WithClose); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+func newTrackedTransport(transport raft.Transport) *trackedTransport {
+	return &trackedTransport{
+		Transport: transport,
+		peers:     make(map[raft.ServerID]peerContact),
+	}
+}
+
+// Member is a server of the latest raft configuration
+type Member struct {
+	ID       raft.ServerID
+	Address  raft.ServerAddress
+	Suffrage raft.ServerSuffrage
+	Leader   bool
+	// LastContact is the last time the server was heard from, zero when unknown. the leader
+	// knows it for every follower, a follower only for itself (the last contact with the leader)
+	LastContact time.Time
+	// AppliedIndex is only known for the local server
+	AppliedIndex uint64
+	CommitIndex  uint64
+	LastLogIndex uint64
+}
+
+// Members returns the servers of the latest raft configuration. the replication state of the
+// other servers is only tracked by the leader
+func (r *Raft) Members() ([]Member, error) {
+	future := r.GetConfiguration()
+	if err := future.Error(); err != nil {
+		return nil, err
+	}
+
+	var (
+		stats       = r.Stats()
+		_, leaderID = r.LeaderWithID()
+		isLeader    = r.State() == raft.Leader
+		members     = make([]Member, 0, len(future.Configuration().Servers))
+	)
+	if isLeader && r.tracker != nil {
+		r.tracker.retain(future.Configuration().Servers)
+	}
+	for _, server := range future.Configuration().Servers {
+		member := Member{
+			ID:       server.ID,
+			Address:  server.Address,
+			Suffrage: server.Suffrage,
+			Leader:   server.ID == leaderID,
+		}
+
+		switch {
+		case server.ID == r.cfg.LocalID:
+			member.AppliedIndex = r.AppliedIndex()
+			member.CommitIndex, _ = strconv.ParseUint(stats["commit_index"], 10, 64)
+			member.LastLogIndex = r.LastIndex()
+			if isLeader {
+				member.LastContact = time.Now()
+			} else {
+				member.LastContact = r.LastContact()
+			}
+		case isLeader && r.tracker != nil:
+			if contact, ok := r.tracker.contact(server.ID); ok {
+				member.LastContact = contact.lastContact
+				member.CommitIndex = contact.commitIndex
+				member.LastLogIndex = contact.lastLog
+			}
+		}
+		members = append(members, member)
+	}
+	return members, nil
+}
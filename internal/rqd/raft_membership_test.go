@@ -0,0 +1,161 @@
+package rqd_test
+
+import (
+	red "github.com/RealFax/RedQueen/internal/rqd"
+	"github.com/hashicorp/raft"
+	"github.com/stretchr/testify/require"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// newTestCluster starts n in-memory raft nodes, only the first one is bootstrapped
+func newTestCluster(t *testing.T, n int) []*red.Raft {
+	var (
+		nodes      = make([]*red.Raft, n)
+		transports = make([]*raft.InmemTransport, n)
+	)
+	for i := range transports {
+		_, transports[i] = raft.NewInmemTransport(raft.ServerAddress(nodeID(i)))
+	}
+	for i := range transports {
+		for j := range transports {
+			if i != j {
+				transports[i].Connect(transports[j].LocalAddr(), transports[j])
+			}
+		}
+	}
+
+	for i := range nodes {
+		cfg := raft.DefaultConfig()
+		cfg.LocalID = raft.ServerID(nodeID(i))
+		cfg.HeartbeatTimeout = 50 * time.Millisecond
+		cfg.ElectionTimeout = 50 * time.Millisecond
+		cfg.LeaderLeaseTimeout = 50 * time.Millisecond
+		cfg.CommitTimeout = 5 * time.Millisecond
+
+		opts := []red.RaftServerOption{
+			red.RaftWithConfig(cfg),
+			red.RaftWithStdFSM(newTestStore(t)),
+			red.RaftWithInmemStore(),
+			red.RaftWithTransport(transports[i]),
+		}
+		if i == 0 {
+			opts = append(opts, red.RaftWithBootstrap(), red.RaftWithClusters([]raft.Server{{
+				ID:      cfg.LocalID,
+				Address: transports[i].LocalAddr(),
+			}}))
+		}
+
+		r, err := red.NewRaftWithOptions(opts...)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = r.Shutdown().Error() })
+		nodes[i] = r
+	}
+
+	require.Eventually(t, func() bool {
+		return nodes[0].State() == raft.Leader
+	}, 5*time.Second, 10*time.Millisecond)
+	return nodes
+}
+
+func nodeID(i int) string {
+	return "node-" + strconv.Itoa(i)
+}
+
+func suffrage(t *testing.T, r *red.Raft, id string) (raft.ServerSuffrage, bool) {
+	future := r.GetConfiguration()
+	require.NoError(t, future.Error())
+	for _, server := range future.Configuration().Servers {
+		if server.ID == raft.ServerID(id) {
+			return server.Suffrage, true
+		}
+	}
+	return 0, false
+}
+
+func TestRaft_Membership(t *testing.T) {
+	nodes := newTestCluster(t, 3)
+	leader := nodes[0]
+
+	require.NoError(t, leader.AddCluster(raft.ServerID(nodeID(1)), raft.ServerAddress(nodeID(1)), true))
+	require.NoError(t, leader.AddCluster(raft.ServerID(nodeID(2)), raft.ServerAddress(nodeID(2)), false))
+
+	s, ok := suffrage(t, leader, nodeID(2))
+	require.True(t, ok)
+	require.Equal(t, raft.Nonvoter, s)
+
+	// learner
+	require.ErrorIs(t, leader.DemoteCluster(raft.ServerID(nodeID(2))), red.ErrServerNotVoter)
+	require.ErrorIs(t, leader.TransferLeadership(raft.ServerID(nodeID(2))), red.ErrServerNotVoter)
+	require.NoError(t, leader.PromoteCluster(raft.ServerID(nodeID(2))))
+	s, _ = suffrage(t, leader, nodeID(2))
+	require.Equal(t, raft.Voter, s)
+	require.ErrorIs(t, leader.PromoteCluster(raft.ServerID(nodeID(2))), red.ErrServerIsVoter)
+
+	// voter
+	require.NoError(t, leader.DemoteCluster(raft.ServerID(nodeID(1))))
+	s, _ = suffrage(t, leader, nodeID(1))
+	require.Equal(t, raft.Nonvoter, s)
+
+	require.NoError(t, leader.RemoveCluster(raft.ServerID(nodeID(1))))
+	_, ok = suffrage(t, leader, nodeID(1))
+	require.False(t, ok)
+
+	require.ErrorIs(t, leader.RemoveCluster("unknown"), red.ErrServerNotFound)
+}
+
+func TestRaft_TransferLeadership(t *testing.T) {
+	nodes := newTestCluster(t, 3)
+	for i := 1; i < len(nodes); i++ {
+		require.NoError(t, nodes[0].AddCluster(raft.ServerID(nodeID(i)), raft.ServerAddress(nodeID(i)), true))
+	}
+
+	require.NoError(t, nodes[0].TransferLeadership(raft.ServerID(nodeID(2))))
+	require.Eventually(t, func() bool {
+		return nodes[2].State() == raft.Leader
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// any voter
+	require.NoError(t, nodes[2].TransferLeadership(""))
+	require.Eventually(t, func() bool {
+		return nodes[2].State() != raft.Leader
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestRaft_Members(t *testing.T) {
+	nodes := newTestCluster(t, 3)
+	require.NoError(t, nodes[0].AddCluster(raft.ServerID(nodeID(1)), raft.ServerAddress(nodeID(1)), true))
+	require.NoError(t, nodes[0].AddCluster(raft.ServerID(nodeID(2)), raft.ServerAddress(nodeID(2)), false))
+	require.NoError(t, nodes[0].Barrier(time.Second).Error())
+
+	// followers are tracked once their heartbeats are answered
+	require.Eventually(t, func() bool {
+		members, err := nodes[0].Members()
+		require.NoError(t, err)
+		for _, member := range members {
+			if member.LastContact.IsZero() || member.LastLogIndex != nodes[0].LastIndex() {
+				return false
+			}
+		}
+		return true
+	}, 5*time.Second, 10*time.Millisecond)
+
+	members, err := nodes[0].Members()
+	require.NoError(t, err)
+	require.Len(t, members, 3)
+	require.True(t, members[0].Leader)
+	require.Equal(t, nodes[0].AppliedIndex(), members[0].AppliedIndex)
+	require.Equal(t, raft.Voter, members[1].Suffrage)
+	require.False(t, members[1].Leader)
+	require.Equal(t, raft.Nonvoter, members[2].Suffrage)
+
+	// a follower only knows about itself
+	members, err = nodes[1].Members()
+	require.NoError(t, err)
+	require.Len(t, members, 3)
+	require.True(t, members[0].Leader)
+	require.True(t, members[0].LastContact.IsZero())
+	require.False(t, members[1].LastContact.IsZero())
+	require.True(t, members[2].LastContact.IsZero())
+}
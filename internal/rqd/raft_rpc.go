@@ -2,6 +2,7 @@ package rqd
 
 import (
 	"crypto/tls"
+	"crypto/x509"
 	"crypto/x509/pkix"
 	"github.com/RealFax/RedQueen/pkg/tlsutil"
 	"github.com/hashicorp/raft"
@@ -9,6 +10,7 @@ import (
 	"io"
 	"net"
 	"net/netip"
+	"slices"
 	"time"
 )
 
@@ -17,13 +19,52 @@ type RaftTLStreamLayer struct {
 	config   *tls.Config
 	addr     net.Addr
 	listener net.Listener
+	// servers returns the raft configuration, the peers are verified against it when it's set
+	servers func() []raft.Server
 }
 
 func (r RaftTLStreamLayer) Accept() (net.Conn, error) { return r.listener.Accept() }
 func (r RaftTLStreamLayer) Close() error              { return r.listener.Close() }
-func (r RaftTLStreamLayer) Addr() net.Addr            { return r.listener.Addr() }
+
+// Addr returns the advertised address, which falls back to the listener address
+func (r RaftTLStreamLayer) Addr() net.Addr {
+	if r.addr != nil {
+		return r.addr
+	}
+	return r.listener.Addr()
+}
 func (r RaftTLStreamLayer) Dial(addr raft.ServerAddress, timeout time.Duration) (net.Conn, error) {
-	return tls.DialWithDialer(&net.Dialer{Timeout: timeout}, "tcp", string(addr), r.config)
+	config := r.config
+	if r.servers != nil {
+		// the peer certificate must be issued to the server id behind addr
+		servers := r.servers()
+		idx := slices.IndexFunc(servers, func(server raft.Server) bool { return server.Address == addr })
+		if idx == -1 {
+			return nil, errors.Wrapf(ErrServerNotFound, "peer address %s", addr)
+		}
+		config = r.config.Clone()
+		config.ServerName = string(servers[idx].ID)
+		config.VerifyConnection = nil
+	}
+	return tls.DialWithDialer(&net.Dialer{Timeout: timeout}, "tcp", string(addr), config)
+}
+
+// verifyPeer checks that the client certificate of an accepted peer is issued to a server of the
+// raft configuration. any verified peer is accepted while the configuration is empty, e.g. when
+// the local node is being joined
+func verifyPeer(servers []raft.Server, cs tls.ConnectionState) error {
+	if len(servers) == 0 {
+		return nil
+	}
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("peer certificate is required")
+	}
+	for _, server := range servers {
+		if slices.Contains(cs.PeerCertificates[0].DNSNames, string(server.ID)) {
+			return nil
+		}
+	}
+	return errors.Errorf("peer certificate %s isn't issued to a raft server", cs.PeerCertificates[0].Subject)
 }
 
 func newTLSTransport(
@@ -31,6 +72,7 @@ func newTLSTransport(
 	addr string,
 	advertise net.Addr,
 	cfg *tls.Config,
+	servers func() []raft.Server,
 	transportCreator func(layer raft.StreamLayer) *raft.NetworkTransport,
 ) (*raft.NetworkTransport, error) {
 	inet, err := netip.ParseAddrPort(addr)
@@ -39,10 +81,12 @@ func newTLSTransport(
 	}
 
 	switch {
-	case err != nil:
-		return nil, err
 	case !inet.IsValid():
 		return nil, errors.New("invalid transport listener address")
+	case advertise != nil:
+		if tcpAddr, ok := advertise.(*net.TCPAddr); ok && (tcpAddr.IP == nil || tcpAddr.IP.IsUnspecified()) {
+			return nil, errors.New("advertise address is not advertisable")
+		}
 	case inet.Addr().IsUnspecified():
 		return nil, errors.New("local bind address is not advertisable")
 	}
@@ -61,6 +105,7 @@ func newTLSTransport(
 		config:   cfg,
 		addr:     advertise,
 		listener: listener,
+		servers:  servers,
 	}), nil
 }
 
@@ -82,7 +127,7 @@ func NewTLSTransportWithGenerator(
 	return newTLSTransport(true, addr, advertise, &tls.Config{
 		Certificates:       []tls.Certificate{cert},
 		InsecureSkipVerify: true,
-	}, func(layer raft.StreamLayer) *raft.NetworkTransport {
+	}, nil, func(layer raft.StreamLayer) *raft.NetworkTransport {
 		config.Stream = layer
 		return raft.NewNetworkTransportWithConfig(config)
 	})
@@ -96,7 +141,41 @@ func NewTLSTransport(
 	logOutput io.Writer,
 	cfg *tls.Config,
 ) (*raft.NetworkTransport, error) {
-	return newTLSTransport(false, addr, advertise, cfg, func(layer raft.StreamLayer) *raft.NetworkTransport {
+	return newTLSTransport(false, addr, advertise, cfg, nil, func(layer raft.StreamLayer) *raft.NetworkTransport {
+		return raft.NewNetworkTransport(layer, maxPool, timeout, logOutput)
+	})
+}
+
+// NewMutualTLSTransport is a transport where both peers present a certificate signed by ca, the
+// server id of a peer is the DNS SAN of its certificate. keyPair returns the certificate to
+// present, it's called on each handshake. servers returns the raft configuration the peers are
+// verified against
+func NewMutualTLSTransport(
+	addr string,
+	advertise net.Addr,
+	maxPool int,
+	timeout time.Duration,
+	logOutput io.Writer,
+	keyPair func() *tls.Certificate,
+	ca *x509.CertPool,
+	servers func() []raft.Server,
+) (*raft.NetworkTransport, error) {
+	cfg := &tls.Config{
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return keyPair(), nil
+		},
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return keyPair(), nil
+		},
+		RootCAs:    ca,
+		ClientCAs:  ca,
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		MinVersion: tls.VersionTLS12,
+		VerifyConnection: func(cs tls.ConnectionState) error {
+			return verifyPeer(servers(), cs)
+		},
+	}
+	return newTLSTransport(false, addr, advertise, cfg, servers, func(layer raft.StreamLayer) *raft.NetworkTransport {
 		return raft.NewNetworkTransport(layer, maxPool, timeout, logOutput)
 	})
 }
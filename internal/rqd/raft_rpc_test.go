@@ -1,11 +1,21 @@
 package rqd_test
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	red "github.com/RealFax/RedQueen/internal/rqd"
 	"github.com/hashicorp/raft"
 	"github.com/stretchr/testify/require"
+	"io"
+	"math/big"
 	"net"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestNewTLSTransportWithGenerator(t *testing.T) {
@@ -19,3 +29,111 @@ func TestNewTLSTransportWithGenerator(t *testing.T) {
 	require.NoError(t, err)
 	require.NotNil(t, transport)
 }
+
+func TestNewTLSTransportWithGenerator_Advertise(t *testing.T) {
+	_, err := red.NewTLSTransportWithGenerator("0.0.0.0:0", nil, &raft.NetworkTransportConfig{})
+	require.Error(t, err)
+
+	_, err = red.NewTLSTransportWithGenerator("0.0.0.0:0", &net.TCPAddr{IP: net.IPv4zero, Port: 5678}, &raft.NetworkTransportConfig{})
+	require.Error(t, err)
+
+	advertise := &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 5678}
+	transport, err := red.NewTLSTransportWithGenerator("0.0.0.0:0", advertise, &raft.NetworkTransportConfig{})
+	require.NoError(t, err)
+	defer transport.Close()
+	require.Equal(t, raft.ServerAddress(advertise.String()), transport.LocalAddr())
+}
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pool *x509.CertPool
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "RedQueen Test CA"},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return &testCA{cert: cert, key: key, pool: pool}
+}
+
+// issue returns a certificate for both tls sides, name is its common name and DNS SAN
+func (ca *testCA) issue(t *testing.T, name string) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}, ca.cert, key.Public(), ca.key)
+	require.NoError(t, err)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func newTestMutualTLSTransport(t *testing.T, cert tls.Certificate, ca *x509.CertPool, servers func() []raft.Server) *raft.NetworkTransport {
+	keyPair := func() *tls.Certificate { return &cert }
+	transport, err := red.NewMutualTLSTransport("127.0.0.1:0", nil, 2, time.Second, io.Discard, keyPair, ca, servers)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = transport.Close() })
+
+	go func() {
+		for rpc := range transport.Consumer() {
+			rpc.Respond(&raft.AppendEntriesResponse{Success: true}, nil)
+		}
+	}()
+	return transport
+}
+
+func TestNewMutualTLSTransport(t *testing.T) {
+	var (
+		ca      = newTestCA(t)
+		members atomic.Pointer[[]raft.Server]
+		servers = func() []raft.Server { return *members.Load() }
+		node0   = newTestMutualTLSTransport(t, ca.issue(t, nodeID(0)), ca.pool, servers)
+		node1   = newTestMutualTLSTransport(t, ca.issue(t, nodeID(1)), ca.pool, servers)
+		// presents a certificate of another node id
+		spoofed = newTestMutualTLSTransport(t, ca.issue(t, nodeID(1)), ca.pool, servers)
+		// presents a certificate of another ca
+		foreign = newTestMutualTLSTransport(t, newTestCA(t).issue(t, nodeID(3)), ca.pool, servers)
+	)
+	members.Store(&[]raft.Server{
+		{ID: raft.ServerID(nodeID(0)), Address: node0.LocalAddr()},
+		{ID: raft.ServerID(nodeID(1)), Address: node1.LocalAddr()},
+		{ID: raft.ServerID(nodeID(2)), Address: spoofed.LocalAddr()},
+		{ID: raft.ServerID(nodeID(3)), Address: foreign.LocalAddr()},
+	})
+
+	appendEntries := func(from *raft.NetworkTransport, to raft.ServerAddress) error {
+		return from.AppendEntries("", to, &raft.AppendEntriesRequest{}, &raft.AppendEntriesResponse{})
+	}
+	require.NoError(t, appendEntries(node0, node1.LocalAddr()))
+	require.NoError(t, appendEntries(node1, node0.LocalAddr()))
+
+	require.Error(t, appendEntries(node0, spoofed.LocalAddr()))
+	require.Error(t, appendEntries(node0, foreign.LocalAddr()))
+	require.Error(t, appendEntries(foreign, node0.LocalAddr()))
+	require.Error(t, appendEntries(node0, "127.0.0.1:1"))
+
+	// accepted peers must be members
+	outsider := newTestMutualTLSTransport(t, ca.issue(t, nodeID(5)), ca.pool, servers)
+	require.Error(t, appendEntries(outsider, node1.LocalAddr()))
+}
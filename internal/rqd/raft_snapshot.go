@@ -1,14 +1,14 @@
 package rqd
 
 import (
-	"bytes"
 	"io"
 
 	"github.com/hashicorp/raft"
 )
 
+// Snapshot streams the store snapshot into the raft sink
 type Snapshot struct {
-	io.Reader
+	io.ReadCloser
 }
 
 func (s *Snapshot) Persist(sink raft.SnapshotSink) error {
@@ -20,10 +20,5 @@ func (s *Snapshot) Persist(sink raft.SnapshotSink) error {
 }
 
 func (s *Snapshot) Release() {
-	p, ok := s.Reader.(*bytes.Buffer)
-	if !ok {
-		return
-	}
-	p.Reset()
-	p = nil
+	_ = s.Close()
 }
@@ -4,8 +4,27 @@ import (
 	"encoding/binary"
 	"errors"
 	"github.com/RealFax/RedQueen/internal/rqd/store"
+	"github.com/hashicorp/raft"
+	"slices"
 )
 
+// StableStoreNamespace is where the raft stable store lived before it moved out of the replicated store
+const StableStoreNamespace = "_RaftStableStore"
+
+var (
+	// keys written by raft into its stable store
+	stableStoreUint64Keys = [][]byte{[]byte("CurrentTerm"), []byte("LastVoteTerm")}
+	stableStoreKeys       = [][]byte{[]byte("LastVoteCand")}
+)
+
+func init() {
+	store.ReserveNamespace(StableStoreNamespace)
+}
+
+// StableStore is the legacy raft stable store kept in a namespace of the replicated store.
+//
+// the namespace is included by FSM.Snapshot and replaced by FSM.Restore, so installing a
+// snapshot overwrites the term and vote of the node. it is only kept to migrate old data dirs
 type StableStore struct {
 	actions store.Actions
 }
@@ -38,9 +57,55 @@ func (s *StableStore) GetUint64(key []byte) (uint64, error) {
 }
 
 func NewStableStore(s store.Store) (*StableStore, error) {
-	namespace, err := s.Swap("_RaftStableStore")
+	namespace, err := s.Swap(StableStoreNamespace)
 	if err != nil {
 		return nil, err
 	}
 	return &StableStore{actions: namespace}, nil
 }
+
+// MigrateStableStore copies the raft term and vote from the legacy stable store into dst. it
+// does nothing for data dirs without the legacy namespace, or once dst holds a term.
+//
+// the legacy namespace is left in place, it is part of the replicated store and dropping it
+// locally would make the store of this node differ from the other members
+func MigrateStableStore(legacy store.Store, dst raft.StableStore) error {
+	namespaces, err := legacy.Namespaces()
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(namespaces, StableStoreNamespace) {
+		return nil
+	}
+
+	// dst already holds the state of this node, never overwrite it
+	if term, tErr := dst.GetUint64(stableStoreUint64Keys[0]); tErr == nil && term != 0 {
+		return nil
+	}
+
+	src, err := NewStableStore(legacy)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range stableStoreUint64Keys {
+		val, gErr := src.GetUint64(key)
+		if gErr != nil {
+			continue
+		}
+		if err = dst.SetUint64(key, val); err != nil {
+			return err
+		}
+	}
+
+	for _, key := range stableStoreKeys {
+		val, gErr := src.Get(key)
+		if gErr != nil || len(val) == 0 {
+			continue
+		}
+		if err = dst.Set(key, val); err != nil {
+			return err
+		}
+	}
+	return nil
+}
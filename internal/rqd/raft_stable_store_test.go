@@ -0,0 +1,64 @@
+package rqd_test
+
+import (
+	red "github.com/RealFax/RedQueen/internal/rqd"
+	"github.com/RealFax/RedQueen/internal/rqd/store/nuts"
+	raftboltdb "github.com/hashicorp/raft-boltdb/v2"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"testing"
+)
+
+func TestMigrateStableStore(t *testing.T) {
+	dir := t.TempDir()
+
+	db, err := nuts.New(nuts.Config{
+		NodeNum: 1,
+		DataDir: filepath.Join(dir, "data"),
+		RWMode:  nuts.FileIO,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	legacy, err := red.NewStableStore(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, legacy.SetUint64([]byte("CurrentTerm"), 7))
+	assert.NoError(t, legacy.SetUint64([]byte("LastVoteTerm"), 6))
+	assert.NoError(t, legacy.Set([]byte("LastVoteCand"), []byte("node-1")))
+
+	dst, err := raftboltdb.NewBoltStore(filepath.Join(dir, red.RaftStable))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dst.Close()
+
+	assert.NoError(t, red.MigrateStableStore(db, dst))
+
+	term, err := dst.GetUint64([]byte("CurrentTerm"))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(7), term)
+
+	voteTerm, err := dst.GetUint64([]byte("LastVoteTerm"))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(6), voteTerm)
+
+	cand, err := dst.Get([]byte("LastVoteCand"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("node-1"), cand)
+
+	// the replicated store is left untouched
+	namespaces, err := db.Namespaces()
+	assert.NoError(t, err)
+	assert.Contains(t, namespaces, red.StableStoreNamespace)
+
+	// the state of the node is never overwritten by the legacy one
+	assert.NoError(t, dst.SetUint64([]byte("CurrentTerm"), 9))
+	assert.NoError(t, red.MigrateStableStore(db, dst))
+	term, err = dst.GetUint64([]byte("CurrentTerm"))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(9), term)
+}
@@ -0,0 +1,147 @@
+package rqd
+
+import (
+	"context"
+	"net"
+	"net/http"
+
+	"github.com/RealFax/RedQueen/api/serverpb"
+	"github.com/RealFax/RedQueen/internal/rqd/config"
+	"github.com/RealFax/RedQueen/pkg/expr"
+	"github.com/RealFax/RedQueen/pkg/httputil"
+	"github.com/RealFax/RedQueen/pkg/ratelimit"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// rateClass is the class of requests a rate limit applies to
+type rateClass int
+
+const (
+	rateRead rateClass = iota
+	rateWrite
+	rateWatch
+	rateLock
+	rateClasses
+)
+
+// RateLimiter throttles the reads, writes, watches and locks of the clients with a token bucket
+// per user, namespace or ip
+type RateLimiter struct {
+	key config.EnumRateLimitKey
+	// limiters of the classes, nil is unlimited
+	limiters [rateClasses]*ratelimit.Limiter
+}
+
+// bucket returns the key of the bucket a request takes its token from
+func (l *RateLimiter) bucket(username string, namespace *string, remote string) string {
+	switch l.key {
+	case config.RateLimitKeyNamespace:
+		return namespaceOf(namespace)
+	case config.RateLimitKeyUser:
+		if username != "" {
+			return "user:" + username
+		}
+	}
+	if host, _, err := net.SplitHostPort(remote); err == nil {
+		remote = host
+	}
+	return "ip:" + remote
+}
+
+// allow takes a token of the class, it reports false once the client runs out of them
+func (l *RateLimiter) allow(class rateClass, username string, namespace *string, remote string) bool {
+	limiter := l.limiters[class]
+	if limiter == nil {
+		return true
+	}
+	return limiter.Allow(l.bucket(username, namespace, remote))
+}
+
+// rateLimitRPC returns the class and the namespace of the limited requests
+func rateLimitRPC(req any) (rateClass, *string, bool) {
+	switch r := req.(type) {
+	case *serverpb.GetRequest:
+		return rateRead, r.Namespace, true
+	case *serverpb.PrefixScanRequest:
+		return rateRead, r.Namespace, true
+	case *serverpb.HistoryRequest:
+		return rateRead, r.Namespace, true
+	case *serverpb.NamespaceStatsRequest:
+		return rateRead, &r.Namespace, true
+	case *serverpb.NamespaceGetQuotaRequest:
+		return rateRead, &r.Namespace, true
+	case *serverpb.SetRequest:
+		return rateWrite, r.Namespace, true
+	case *serverpb.DeleteRequest:
+		return rateWrite, r.Namespace, true
+	case *serverpb.WatchRequest:
+		return rateWatch, r.Namespace, true
+	case *serverpb.WatchPrefixRequest:
+		return rateWatch, r.Namespace, true
+	case *serverpb.LockRequest, *serverpb.UnlockRequest, *serverpb.TryLockRequest:
+		return rateLock, nil, true
+	default:
+		return 0, nil, false
+	}
+}
+
+// Limit is the grpcutil.AuthorizeFunc of the rate limits, it runs after the authentication so
+// that the users are known
+func (l *RateLimiter) Limit(ctx context.Context, username, method string, req any) error {
+	class, namespace, ok := rateLimitRPC(req)
+	if !ok {
+		return nil
+	}
+
+	var remote string
+	if p, ok := peer.FromContext(ctx); ok {
+		remote = p.Addr.String()
+	}
+	if !l.allow(class, username, namespace, remote) {
+		return status.Errorf(codes.ResourceExhausted, "%s: rate limit exceeded", method)
+	}
+	return nil
+}
+
+// limited throttles the requests of the route once its class is limited, scope reads their namespace
+func (s *v1HttpServer) limited(class rateClass, scope httpScope, next http.Handler) http.Handler {
+	if s.rateLimiter == nil || s.rateLimiter.limiters[class] == nil {
+		return next
+	}
+	return httputil.NewAuthorize(next, func(r *http.Request, username string) error {
+		var namespace *string
+		if s.rateLimiter.key == config.RateLimitKeyNamespace {
+			var err error
+			if namespace, _, err = scope(r); err != nil {
+				return err
+			}
+		}
+		if !s.rateLimiter.allow(class, username, namespace, r.RemoteAddr) {
+			return httputil.NewStatus(http.StatusTooManyRequests, 0, "Too Many Requests")
+		}
+		return nil
+	})
+}
+
+// NewRateLimiter returns the limiter of cfg, nil when no class is limited
+func NewRateLimiter(cfg config.RateLimit) *RateLimiter {
+	l := &RateLimiter{key: expr.OrDefault(cfg.Key, config.EnumRateLimitKey(config.DefaultRateLimitKey))}
+
+	var limited bool
+	for class, limit := range [rateClasses][2]int64{
+		rateRead:  {cfg.Read, cfg.ReadBurst},
+		rateWrite: {cfg.Write, cfg.WriteBurst},
+		rateWatch: {cfg.Watch, cfg.WatchBurst},
+		rateLock:  {cfg.Lock, cfg.LockBurst},
+	} {
+		if limit[0] > 0 {
+			l.limiters[class], limited = ratelimit.New(float64(limit[0]), int(limit[1])), true
+		}
+	}
+	if !limited {
+		return nil
+	}
+	return l
+}
@@ -0,0 +1,73 @@
+package rqd_test
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/RealFax/RedQueen/api/serverpb"
+	red "github.com/RealFax/RedQueen/internal/rqd"
+	"github.com/RealFax/RedQueen/internal/rqd/config"
+	"github.com/RealFax/RedQueen/pkg/expr"
+	"github.com/RealFax/RedQueen/pkg/grpcutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func peerContext(ip string) context.Context {
+	return peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(ip), Port: 40000}})
+}
+
+func TestNewRateLimiter(t *testing.T) {
+	assert.Nil(t, red.NewRateLimiter(config.RateLimit{}))
+	assert.NotNil(t, red.NewRateLimiter(config.RateLimit{Lock: 1}))
+}
+
+func TestRateLimiter_User(t *testing.T) {
+	l := red.NewRateLimiter(config.RateLimit{Write: 1, WriteBurst: 2})
+	require.NotNil(t, l)
+
+	var (
+		alice = grpcutil.ContextWithUser(peerContext("10.0.0.1"), "alice")
+		bob   = grpcutil.ContextWithUser(peerContext("10.0.0.1"), "bob")
+		set   = &serverpb.SetRequest{Key: []byte("key")}
+	)
+	require.NoError(t, l.Limit(alice, "alice", "/serverpb.KV/Set", set))
+	require.NoError(t, l.Limit(alice, "alice", "/serverpb.KV/Set", set))
+	err := l.Limit(alice, "alice", "/serverpb.KV/Set", set)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// the users from the same ip have their own bucket
+	require.NoError(t, l.Limit(bob, "bob", "/serverpb.KV/Set", set))
+	// the reads aren't limited
+	require.NoError(t, l.Limit(alice, "alice", "/serverpb.KV/Get", &serverpb.GetRequest{Key: []byte("key")}))
+
+	// the unauthenticated clients share the bucket of their ip
+	var (
+		anonymous = peerContext("10.0.0.2")
+		lock      = &serverpb.LockRequest{LockId: "lock"}
+	)
+	l = red.NewRateLimiter(config.RateLimit{Lock: 1})
+	require.NoError(t, l.Limit(anonymous, "", "/serverpb.Locker/Lock", lock))
+	err = l.Limit(peerContext("10.0.0.2"), "", "/serverpb.Locker/Lock", lock)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	require.NoError(t, l.Limit(peerContext("10.0.0.3"), "", "/serverpb.Locker/Lock", lock))
+}
+
+func TestRateLimiter_Namespace(t *testing.T) {
+	l := red.NewRateLimiter(config.RateLimit{Key: config.RateLimitKeyNamespace, Watch: 1})
+	require.NotNil(t, l)
+
+	var (
+		ctx  = peerContext("10.0.0.1")
+		jobs = &serverpb.WatchRequest{Namespace: expr.Pointer("jobs"), Key: []byte("key")}
+		app  = &serverpb.WatchPrefixRequest{Namespace: expr.Pointer("config"), Prefix: []byte("app/")}
+	)
+	require.NoError(t, l.Limit(ctx, "alice", "/serverpb.KV/Watch", jobs))
+	err := l.Limit(grpcutil.ContextWithUser(peerContext("10.0.0.2"), "bob"), "bob", "/serverpb.KV/Watch", jobs)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	require.NoError(t, l.Limit(ctx, "alice", "/serverpb.KV/WatchPrefix", app))
+}
@@ -0,0 +1,483 @@
+package rqd
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/RealFax/RedQueen/api/serverpb"
+	"github.com/RealFax/RedQueen/internal/rqd/store"
+	"github.com/RealFax/RedQueen/pkg/httputil"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// AuthUserNamespace holds the users and AuthRoleNamespace the roles of the rbac, keyed by name
+const (
+	AuthUserNamespace = "_AuthUser"
+	AuthRoleNamespace = "_AuthRole"
+)
+
+// GrantAllNamespaces is the namespace of a grant that applies to every namespace. the cluster and
+// the auth service are administrated with the admin permission on it
+const GrantAllNamespaces = "*"
+
+var (
+	ErrUserNotFound      = errors.New("user not found")
+	ErrUserAlreadyExists = errors.New("user already exists")
+	ErrRoleNotFound      = errors.New("role not found")
+	ErrRoleAlreadyExists = errors.New("role already exists")
+	ErrGrantNotFound     = errors.New("grant not found")
+	ErrPermissionDenied  = errors.New("permission denied")
+)
+
+func init() {
+	store.ReserveNamespace(AuthUserNamespace, AuthRoleNamespace)
+}
+
+func loadAuthRecord(s store.Store, namespace, name string, m proto.Message) error {
+	actions, err := s.Swap(namespace)
+	if err != nil {
+		return err
+	}
+
+	val, err := actions.Get([]byte(name))
+	if err != nil {
+		return err
+	}
+	if err = proto.Unmarshal(val.Data, m); err != nil {
+		return errors.Wrapf(err, "unmarshal %s error", namespace)
+	}
+	return nil
+}
+
+// storeAuthRecord replaces the record of name, nil removes it
+func storeAuthRecord(s store.Store, namespace, name string, b []byte) error {
+	actions, err := s.Swap(namespace)
+	if err != nil {
+		return err
+	}
+
+	if len(b) == 0 {
+		if _, err = actions.Get([]byte(name)); err != nil {
+			if errors.Is(err, store.ErrKeyNotFound) {
+				return nil
+			}
+			return err
+		}
+		return actions.Del([]byte(name))
+	}
+	return actions.Set([]byte(name), b)
+}
+
+// listAuthRecords returns the names of the records in namespace
+func listAuthRecords(s store.Store, namespace string, m proto.Message, name func() string) ([]string, error) {
+	actions, err := s.Swap(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	// prefix scans fail when nothing is found, the stats tell an empty namespace apart
+	stats, err := s.NamespaceStats(namespace)
+	if err != nil || stats.Keys == 0 {
+		if errors.Is(err, store.ErrNamespaceNotFound) {
+			err = nil
+		}
+		return []string{}, err
+	}
+
+	values, err := actions.PrefixScan(nil, 0, int(stats.Keys)+1)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(values))
+	for _, value := range values {
+		proto.Reset(m)
+		// internal keys of the store (e.g. the bucket init key) aren't records
+		if proto.Unmarshal(value.Data, m) != nil || name() == "" {
+			continue
+		}
+		names = append(names, name())
+	}
+	return names, nil
+}
+
+// LoadUser returns the user name, ErrUserNotFound when it doesn't exist
+func LoadUser(s store.Store, name string) (*serverpb.User, error) {
+	user := &serverpb.User{}
+	if err := loadAuthRecord(s, AuthUserNamespace, name, user); err != nil {
+		if errors.Is(err, store.ErrKeyNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// StoreUser replaces the user name, nil removes it
+func StoreUser(s store.Store, name string, user *serverpb.User) error {
+	if user == nil {
+		return storeAuthRecord(s, AuthUserNamespace, name, nil)
+	}
+	b, err := proto.Marshal(user)
+	if err != nil {
+		return errors.Wrap(err, "marshal user error")
+	}
+	return storeAuthRecord(s, AuthUserNamespace, name, b)
+}
+
+// updateUser applies update to the stored user name, ErrUserNotFound when it doesn't exist
+func updateUser(s store.Store, name string, update func(user *serverpb.User) error) error {
+	user, err := LoadUser(s, name)
+	if err != nil {
+		return err
+	}
+	if err = update(user); err != nil {
+		return err
+	}
+	return StoreUser(s, name, user)
+}
+
+func ListUsers(s store.Store) ([]string, error) {
+	user := &serverpb.User{}
+	return listAuthRecords(s, AuthUserNamespace, user, user.GetName)
+}
+
+// LoadRole returns the role name, ErrRoleNotFound when it doesn't exist
+func LoadRole(s store.Store, name string) (*serverpb.Role, error) {
+	role := &serverpb.Role{}
+	if err := loadAuthRecord(s, AuthRoleNamespace, name, role); err != nil {
+		if errors.Is(err, store.ErrKeyNotFound) {
+			return nil, ErrRoleNotFound
+		}
+		return nil, err
+	}
+	return role, nil
+}
+
+// StoreRole replaces the role name, nil removes it
+func StoreRole(s store.Store, name string, role *serverpb.Role) error {
+	if role == nil {
+		return storeAuthRecord(s, AuthRoleNamespace, name, nil)
+	}
+	b, err := proto.Marshal(role)
+	if err != nil {
+		return errors.Wrap(err, "marshal role error")
+	}
+	return storeAuthRecord(s, AuthRoleNamespace, name, b)
+}
+
+// updateRole applies update to the stored role name, ErrRoleNotFound when it doesn't exist
+func updateRole(s store.Store, name string, update func(role *serverpb.Role) error) error {
+	role, err := LoadRole(s, name)
+	if err != nil {
+		return err
+	}
+	if err = update(role); err != nil {
+		return err
+	}
+	return StoreRole(s, name, role)
+}
+
+// grantIndex returns the index of the grant on the prefix of namespace, -1 when there is none
+func grantIndex(grants []*serverpb.Grant, namespace string, prefix []byte) int {
+	return slices.IndexFunc(grants, func(g *serverpb.Grant) bool {
+		return g.Namespace == namespace && bytes.Equal(g.Prefix, prefix)
+	})
+}
+
+func ListRoles(s store.Store) ([]string, error) {
+	role := &serverpb.Role{}
+	return listAuthRecords(s, AuthRoleNamespace, role, role.GetName)
+}
+
+// GrantAllows reports whether g holds perm on key of namespace, a nil key needs a grant on every
+// key. lock grants apply to every namespace
+func GrantAllows(g *serverpb.Grant, namespace string, key []byte, perm serverpb.Permission) bool {
+	if perm != serverpb.Permission_lock && g.Namespace != GrantAllNamespaces && g.Namespace != namespace {
+		return false
+	}
+	if !bytes.HasPrefix(key, g.Prefix) {
+		return false
+	}
+	return slices.Contains(g.Permissions, perm) || slices.Contains(g.Permissions, serverpb.Permission_admin)
+}
+
+// CheckPermission reports whether a role of the user holds perm on key of namespace, roles that
+// no longer exist are skipped
+func CheckPermission(s store.Store, username, namespace string, key []byte, perm serverpb.Permission) error {
+	user, err := LoadUser(s, username)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return errors.Wrapf(ErrPermissionDenied, "unknown user %q", username)
+		}
+		return err
+	}
+
+	for _, name := range user.Roles {
+		role, rErr := LoadRole(s, name)
+		if rErr != nil {
+			if errors.Is(rErr, ErrRoleNotFound) {
+				continue
+			}
+			return rErr
+		}
+		for _, grant := range role.Grants {
+			if GrantAllows(grant, namespace, key, perm) {
+				return nil
+			}
+		}
+	}
+	return errors.Wrapf(ErrPermissionDenied, "%s on namespace %q", perm, namespace)
+}
+
+// rbacStatus converts the error of a permission check to a grpc status
+func rbacStatus(err error) error {
+	switch {
+	case errors.Is(err, ErrPermissionDenied):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, ErrUserNotFound), errors.Is(err, ErrRoleNotFound), errors.Is(err, ErrGrantNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, ErrUserAlreadyExists), errors.Is(err, ErrRoleAlreadyExists):
+		return status.Error(codes.AlreadyExists, err.Error())
+	default:
+		return applyStatus(err)
+	}
+}
+
+// rbacHttpStatus converts the error of a permission check to a http status
+func rbacHttpStatus(err error) error {
+	if errors.Is(err, ErrPermissionDenied) {
+		return httputil.StatusWrap(http.StatusForbidden, 0, err)
+	}
+	return httputil.StatusWrap(http.StatusInternalServerError, 0, err)
+}
+
+// applyUser replicates the user name, nil removes it
+func (s *Server) applyUser(ctx context.Context, name string, user *serverpb.User) error {
+	var (
+		b   []byte
+		err error
+	)
+	if user != nil {
+		if b, err = proto.Marshal(user); err != nil {
+			return errors.Wrap(err, "marshal user error")
+		}
+	}
+	return s.applyLog(ctx, &serverpb.RaftLogPayload{
+		Command: serverpb.RaftLogCommand_AuthUser,
+		Key:     []byte(name),
+		Value:   b,
+	}, 500*time.Millisecond)
+}
+
+// applyRole replicates the role name, nil removes it
+func (s *Server) applyRole(ctx context.Context, name string, role *serverpb.Role) error {
+	var (
+		b   []byte
+		err error
+	)
+	if role != nil {
+		if b, err = proto.Marshal(role); err != nil {
+			return errors.Wrap(err, "marshal role error")
+		}
+	}
+	return s.applyLog(ctx, &serverpb.RaftLogPayload{
+		Command: serverpb.RaftLogCommand_AuthRole,
+		Key:     []byte(name),
+		Value:   b,
+	}, 500*time.Millisecond)
+}
+
+// createUser replicates the creation of the user name, the fsm fails with ErrUserAlreadyExists
+// when the user exists at apply time
+func (s *Server) createUser(ctx context.Context, name string, user *serverpb.User) error {
+	b, err := proto.Marshal(user)
+	if err != nil {
+		return errors.Wrap(err, "marshal user error")
+	}
+	return s.applyAuthUpdate(ctx, serverpb.RaftLogCommand_AuthUserCreate, name, b)
+}
+
+// createRole replicates the creation of the role name, the fsm fails with ErrRoleAlreadyExists
+// when the role exists at apply time
+func (s *Server) createRole(ctx context.Context, name string, role *serverpb.Role) error {
+	b, err := proto.Marshal(role)
+	if err != nil {
+		return errors.Wrap(err, "marshal role error")
+	}
+	return s.applyAuthUpdate(ctx, serverpb.RaftLogCommand_AuthRoleCreate, name, b)
+}
+
+// applyAuthUpdate replicates an update of the user or role name, the fsm applies it to the
+// record as it is when the log is applied
+func (s *Server) applyAuthUpdate(ctx context.Context, command serverpb.RaftLogCommand, name string, value []byte) error {
+	return s.applyLog(ctx, &serverpb.RaftLogPayload{
+		Command: command,
+		Key:     []byte(name),
+		Value:   value,
+	}, 500*time.Millisecond)
+}
+
+func namespaceOf(namespace *string) string {
+	if namespace == nil {
+		return store.DefaultNamespace
+	}
+	return *namespace
+}
+
+// checkPermission reports whether the user holds perm on key of namespace, the rbac admins hold
+// every permission
+func (s *Server) checkPermission(username string, namespace *string, key []byte, perm serverpb.Permission) error {
+	if slices.Contains(s.cfg.Rbac.Admins, username) {
+		return nil
+	}
+	return CheckPermission(s.store, username, namespaceOf(namespace), key, perm)
+}
+
+// authorizeRPC is the grpcutil.AuthorizeFunc of the rbac, each request needs the permission of
+// what it reads or writes. the cluster and the auth service need the admin permission on every
+// namespace
+func (s *Server) authorizeRPC(_ context.Context, username, method string, req any) error {
+	if username == "" {
+		return status.Error(codes.Unauthenticated, "unauthenticated")
+	}
+
+	var (
+		err        error
+		allNs      = GrantAllNamespaces
+		checkAdmin = func() error { return s.checkPermission(username, &allNs, nil, serverpb.Permission_admin) }
+	)
+	switch r := req.(type) {
+	case *serverpb.GetRequest:
+		err = s.checkPermission(username, r.Namespace, r.Key, serverpb.Permission_read)
+	case *serverpb.PrefixScanRequest:
+		err = s.checkPermission(username, r.Namespace, r.Prefix, serverpb.Permission_read)
+	case *serverpb.HistoryRequest:
+		err = s.checkPermission(username, r.Namespace, r.Key, serverpb.Permission_read)
+	case *serverpb.SetRequest:
+		err = s.checkPermission(username, r.Namespace, r.Key, serverpb.Permission_write)
+	case *serverpb.DeleteRequest:
+		err = s.checkPermission(username, r.Namespace, r.Key, serverpb.Permission_write)
+	case *serverpb.WatchRequest:
+		err = s.checkPermission(username, r.Namespace, r.Key, serverpb.Permission_watch)
+	case *serverpb.WatchPrefixRequest:
+		err = s.checkPermission(username, r.Namespace, r.Prefix, serverpb.Permission_watch)
+	case *serverpb.LockRequest:
+		err = s.checkPermission(username, nil, []byte(r.LockId), serverpb.Permission_lock)
+	case *serverpb.UnlockRequest:
+		err = s.checkPermission(username, nil, []byte(r.LockId), serverpb.Permission_lock)
+	case *serverpb.TryLockRequest:
+		err = s.checkPermission(username, nil, []byte(r.LockId), serverpb.Permission_lock)
+	case *serverpb.NamespaceStatsRequest:
+		err = s.checkPermission(username, &r.Namespace, nil, serverpb.Permission_read)
+	case *serverpb.NamespaceGetQuotaRequest:
+		err = s.checkPermission(username, &r.Namespace, nil, serverpb.Permission_read)
+	case *serverpb.NamespaceCreateRequest:
+		err = s.checkPermission(username, &r.Namespace, nil, serverpb.Permission_admin)
+	case *serverpb.NamespaceDropRequest:
+		err = s.checkPermission(username, &r.Namespace, nil, serverpb.Permission_admin)
+	case *serverpb.NamespaceSetQuotaRequest:
+		err = s.checkPermission(username, &r.Namespace, nil, serverpb.Permission_admin)
+	case *serverpb.NamespaceListRequest, *serverpb.LeaderMonitorRequest, *emptypb.Empty:
+		// the namespaces and the cluster state are listed to every user
+	case *serverpb.AuthenticateRequest:
+		// every user exchanges its credentials for a token
+	case *serverpb.UserGetRequest:
+		// the users read their own roles
+		if r.Name != username {
+			err = checkAdmin()
+		}
+	case *serverpb.UserChangePasswordRequest:
+		// the users change their own password
+		if r.Name != username {
+			err = checkAdmin()
+		}
+	default:
+		// membership changes, snapshots and the auth service
+		err = checkAdmin()
+	}
+	if err != nil {
+		if errors.Is(err, ErrPermissionDenied) {
+			return status.Errorf(codes.PermissionDenied, "%s: %s", method, err)
+		}
+		return status.Error(codes.Internal, err.Error())
+	}
+	return nil
+}
+
+// httpScope returns the namespace and the key a http request works on
+type httpScope func(r *http.Request) (namespace *string, key []byte, err error)
+
+// queryScope reads the key from the base64 query param of the bucket
+func (s *v1HttpServer) queryScope(param string) httpScope {
+	return func(r *http.Request) (*string, []byte, error) {
+		key, err := base64.URLEncoding.DecodeString(r.URL.Query().Get(param))
+		if err != nil {
+			return nil, nil, httputil.NewStatus(http.StatusBadRequest, 0, "invalid query "+param)
+		}
+		return s.getBucket(r.Context()), key, nil
+	}
+}
+
+// bodyScope reads the key (lock id) from the json body, the body is kept for the handler
+func (s *v1HttpServer) bodyScope(lock bool) httpScope {
+	return func(r *http.Request) (*string, []byte, error) {
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			return nil, nil, httputil.StatusWrap(http.StatusBadRequest, 0, err)
+		}
+		r.Body = io.NopCloser(bytes.NewReader(b))
+
+		var req struct {
+			Key    []byte `json:"key"`
+			LockId string `json:"lock_id"`
+		}
+		if err = httputil.BindJSON(&req, bytes.NewReader(b)); err != nil {
+			return nil, nil, err
+		}
+		if lock {
+			return nil, []byte(req.LockId), nil
+		}
+		return s.getBucket(r.Context()), req.Key, nil
+	}
+}
+
+// namespaceScope is the whole namespace of the bucket
+func (s *v1HttpServer) namespaceScope(r *http.Request) (*string, []byte, error) {
+	return s.getBucket(r.Context()), nil, nil
+}
+
+// clusterScope is every namespace, the cluster is administrated with it
+func clusterScope(*http.Request) (*string, []byte, error) {
+	allNs := GrantAllNamespaces
+	return &allNs, nil, nil
+}
+
+// authorize checks the permission of the route before next once the rbac is enabled
+func (s *v1HttpServer) authorize(perm serverpb.Permission, scope httpScope, next http.Handler) http.Handler {
+	if !s.cfg.Rbac.Enabled {
+		return next
+	}
+	return httputil.NewAuthorize(next, func(r *http.Request, username string) error {
+		if username == "" {
+			return httputil.NewStatus(http.StatusUnauthorized, 401, "Unauthorized")
+		}
+		namespace, key, err := scope(r)
+		if err != nil {
+			return err
+		}
+		if err = s.checkPermission(username, namespace, key, perm); err != nil {
+			return rbacHttpStatus(err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,180 @@
+package rqd_test
+
+import (
+	"testing"
+
+	"github.com/RealFax/RedQueen/api/serverpb"
+	red "github.com/RealFax/RedQueen/internal/rqd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestGrantAllows(t *testing.T) {
+	grant := &serverpb.Grant{
+		Namespace:   "tenant",
+		Prefix:      []byte("app/"),
+		Permissions: []serverpb.Permission{serverpb.Permission_read, serverpb.Permission_lock},
+	}
+
+	assert.True(t, red.GrantAllows(grant, "tenant", []byte("app/key"), serverpb.Permission_read))
+	assert.False(t, red.GrantAllows(grant, "tenant", []byte("other/key"), serverpb.Permission_read))
+	assert.False(t, red.GrantAllows(grant, "other", []byte("app/key"), serverpb.Permission_read))
+	assert.False(t, red.GrantAllows(grant, "tenant", []byte("app/key"), serverpb.Permission_write))
+	// the whole namespace needs a grant on every key
+	assert.False(t, red.GrantAllows(grant, "tenant", nil, serverpb.Permission_read))
+	// lock grants apply to every namespace
+	assert.True(t, red.GrantAllows(grant, "", []byte("app/lock"), serverpb.Permission_lock))
+
+	admin := &serverpb.Grant{
+		Namespace:   red.GrantAllNamespaces,
+		Permissions: []serverpb.Permission{serverpb.Permission_admin},
+	}
+	assert.True(t, red.GrantAllows(admin, "tenant", []byte("key"), serverpb.Permission_write))
+	assert.True(t, red.GrantAllows(admin, red.GrantAllNamespaces, nil, serverpb.Permission_admin))
+	assert.False(t, red.GrantAllows(grant, red.GrantAllNamespaces, nil, serverpb.Permission_admin))
+}
+
+func TestCheckPermission(t *testing.T) {
+	db := newTestStore(t)
+
+	require.NoError(t, red.StoreRole(db, "reader", &serverpb.Role{
+		Name: "reader",
+		Grants: []*serverpb.Grant{{
+			Namespace:   "tenant",
+			Prefix:      []byte("app/"),
+			Permissions: []serverpb.Permission{serverpb.Permission_read, serverpb.Permission_watch},
+		}},
+	}))
+	require.NoError(t, red.StoreUser(db, "alice", &serverpb.User{Name: "alice", Roles: []string{"missing", "reader"}}))
+
+	assert.NoError(t, red.CheckPermission(db, "alice", "tenant", []byte("app/key"), serverpb.Permission_read))
+	assert.NoError(t, red.CheckPermission(db, "alice", "tenant", []byte("app/"), serverpb.Permission_watch))
+	assert.ErrorIs(t, red.CheckPermission(db, "alice", "tenant", []byte("app/key"), serverpb.Permission_write), red.ErrPermissionDenied)
+	assert.ErrorIs(t, red.CheckPermission(db, "alice", "tenant", []byte("key"), serverpb.Permission_read), red.ErrPermissionDenied)
+	assert.ErrorIs(t, red.CheckPermission(db, "bob", "tenant", []byte("app/key"), serverpb.Permission_read), red.ErrPermissionDenied)
+
+	// removing the role revokes its grants
+	require.NoError(t, red.StoreRole(db, "reader", nil))
+	assert.ErrorIs(t, red.CheckPermission(db, "alice", "tenant", []byte("app/key"), serverpb.Permission_read), red.ErrPermissionDenied)
+}
+
+func TestFSMHandlers_Auth(t *testing.T) {
+	var (
+		db       = newTestStore(t)
+		handlers = red.NewFSMHandlers(db)
+	)
+
+	users, err := red.ListUsers(db)
+	require.NoError(t, err)
+	assert.Empty(t, users)
+
+	for _, name := range []string{"alice", "bob"} {
+		user, mErr := proto.Marshal(&serverpb.User{Name: name})
+		require.NoError(t, mErr)
+		require.NoError(t, handlers[serverpb.RaftLogCommand_AuthUser](&serverpb.RaftLogPayload{
+			Command: serverpb.RaftLogCommand_AuthUser,
+			Key:     []byte(name),
+			Value:   user,
+		}))
+	}
+	users, err = red.ListUsers(db)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"alice", "bob"}, users)
+
+	require.NoError(t, handlers[serverpb.RaftLogCommand_AuthUser](&serverpb.RaftLogPayload{
+		Command: serverpb.RaftLogCommand_AuthUser,
+		Key:     []byte("bob"),
+	}))
+	_, err = red.LoadUser(db, "bob")
+	assert.ErrorIs(t, err, red.ErrUserNotFound)
+	users, err = red.ListUsers(db)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice"}, users)
+
+	role, err := proto.Marshal(&serverpb.Role{Name: "writer"})
+	require.NoError(t, err)
+	require.NoError(t, handlers[serverpb.RaftLogCommand_AuthRole](&serverpb.RaftLogPayload{
+		Command: serverpb.RaftLogCommand_AuthRole,
+		Key:     []byte("writer"),
+		Value:   role,
+	}))
+	roles, err := red.ListRoles(db)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"writer"}, roles)
+}
+
+func TestFSMHandlers_AuthCreate(t *testing.T) {
+	var (
+		db       = newTestStore(t)
+		handlers = red.NewFSMHandlers(db)
+		apply    = func(command serverpb.RaftLogCommand, name string, m proto.Message) error {
+			b, err := proto.Marshal(m)
+			require.NoError(t, err)
+			return handlers[command](&serverpb.RaftLogPayload{Command: command, Key: []byte(name), Value: b})
+		}
+	)
+
+	// the second create of the same user fails at apply time and keeps the first record
+	require.NoError(t, apply(serverpb.RaftLogCommand_AuthUserCreate, "alice", &serverpb.User{Name: "alice", PasswordHash: []byte("first")}))
+	assert.ErrorIs(t, apply(serverpb.RaftLogCommand_AuthUserCreate, "alice", &serverpb.User{Name: "alice", PasswordHash: []byte("second")}), red.ErrUserAlreadyExists)
+	user, err := red.LoadUser(db, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("first"), user.PasswordHash)
+
+	reader := &serverpb.Role{Name: "reader", Grants: []*serverpb.Grant{{Namespace: "orders"}}}
+	require.NoError(t, apply(serverpb.RaftLogCommand_AuthRoleCreate, "reader", reader))
+	assert.ErrorIs(t, apply(serverpb.RaftLogCommand_AuthRoleCreate, "reader", &serverpb.Role{Name: "reader"}), red.ErrRoleAlreadyExists)
+	role, err := red.LoadRole(db, "reader")
+	require.NoError(t, err)
+	assert.Len(t, role.Grants, 1)
+}
+
+func TestFSMHandlers_AuthUpdate(t *testing.T) {
+	var (
+		db       = newTestStore(t)
+		handlers = red.NewFSMHandlers(db)
+		apply    = func(command serverpb.RaftLogCommand, name string, value []byte) error {
+			return handlers[command](&serverpb.RaftLogPayload{Command: command, Key: []byte(name), Value: value})
+		}
+	)
+	require.NoError(t, red.StoreUser(db, "alice", &serverpb.User{Name: "alice", PasswordHash: []byte("hash")}))
+	for _, name := range []string{"reader", "writer"} {
+		require.NoError(t, red.StoreRole(db, name, &serverpb.Role{Name: name}))
+	}
+
+	// the updates of the same user are applied one after another, none of them is lost
+	require.NoError(t, apply(serverpb.RaftLogCommand_AuthUserGrantRole, "alice", []byte("reader")))
+	require.NoError(t, apply(serverpb.RaftLogCommand_AuthUserGrantRole, "alice", []byte("writer")))
+	require.NoError(t, apply(serverpb.RaftLogCommand_AuthUserGrantRole, "alice", []byte("writer")))
+	require.NoError(t, apply(serverpb.RaftLogCommand_AuthUserPassword, "alice", nil))
+	require.NoError(t, apply(serverpb.RaftLogCommand_AuthUserRevokeRole, "alice", []byte("reader")))
+
+	user, err := red.LoadUser(db, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"writer"}, user.Roles)
+	assert.Empty(t, user.PasswordHash)
+
+	assert.ErrorIs(t, apply(serverpb.RaftLogCommand_AuthUserGrantRole, "alice", []byte("admin")), red.ErrRoleNotFound)
+	assert.ErrorIs(t, apply(serverpb.RaftLogCommand_AuthUserGrantRole, "bob", []byte("reader")), red.ErrUserNotFound)
+
+	for _, grant := range []*serverpb.Grant{
+		{Namespace: "orders", Prefix: []byte("eu/"), Permissions: []serverpb.Permission{serverpb.Permission_read}},
+		{Namespace: "orders", Prefix: []byte("us/"), Permissions: []serverpb.Permission{serverpb.Permission_read}},
+		{Namespace: "orders", Prefix: []byte("eu/"), Permissions: []serverpb.Permission{serverpb.Permission_write}},
+	} {
+		b, mErr := proto.Marshal(grant)
+		require.NoError(t, mErr)
+		require.NoError(t, apply(serverpb.RaftLogCommand_AuthRoleGrantPermission, "writer", b))
+	}
+	revoke, err := proto.Marshal(&serverpb.Grant{Namespace: "orders", Prefix: []byte("us/")})
+	require.NoError(t, err)
+	require.NoError(t, apply(serverpb.RaftLogCommand_AuthRoleRevokePermission, "writer", revoke))
+	assert.ErrorIs(t, apply(serverpb.RaftLogCommand_AuthRoleRevokePermission, "writer", revoke), red.ErrGrantNotFound)
+
+	role, err := red.LoadRole(db, "writer")
+	require.NoError(t, err)
+	require.Len(t, role.Grants, 1)
+	assert.Equal(t, []byte("eu/"), role.Grants[0].Prefix)
+	assert.Equal(t, []serverpb.Permission{serverpb.Permission_write}, role.Grants[0].Permissions)
+}
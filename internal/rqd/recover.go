@@ -0,0 +1,151 @@
+package rqd
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/RealFax/RedQueen/internal/rqd/config"
+	"github.com/RealFax/RedQueen/internal/rqd/store"
+	"github.com/hashicorp/raft"
+	raftboltdb "github.com/hashicorp/raft-boltdb/v2"
+	"github.com/pkg/errors"
+)
+
+// ErrRecoverExistingState is returned when a snapshot is recovered into a node that has raft state
+var ErrRecoverExistingState = errors.New("recover from a snapshot requires a data dir without raft state")
+
+// seedSnapshot writes the store snapshot file as the only raft snapshot, the state starts over
+// at term 1
+func (r *Raft) seedSnapshot(path string) error {
+	hasState, err := raft.HasExistingState(r.logStore, r.stableStore, r.snapshotStore)
+	if err != nil {
+		return err
+	}
+	if hasState {
+		return ErrRecoverExistingState
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	src, err := r.keyring.OpenSnapshot(f)
+	if err != nil {
+		return errors.Wrap(err, "open snapshot")
+	}
+	manifest, err := store.VerifySnapshot(src, r.legacySnapshot)
+	if err != nil {
+		return errors.Wrap(err, "verify snapshot")
+	}
+	if manifest.Version == 0 && r.cfg.Logger != nil {
+		r.cfg.Logger.Warn("recovering from a legacy snapshot without manifest, it has no checksums")
+	}
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	sink, err := r.snapshotStore.Create(
+		raft.SnapshotVersionMax,
+		max(manifest.Index, 1),
+		1,
+		raft.Configuration{Servers: r.clusters},
+		1,
+		r.transport,
+	)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(sink, f); err != nil {
+		_ = sink.Cancel()
+		return err
+	}
+	return sink.Close()
+}
+
+// RecoverRaft forces a new cluster out of the raft state of the options, the clusters option
+// becomes the only members. the state is read from the snapshot file instead when it's set.
+//
+// it's the way out when the quorum is permanently lost, like the peers.json recovery of raft
+func RecoverRaft(snapshot string, opts ...RaftServerOption) error {
+	r := &Raft{}
+	for _, opt := range opts {
+		if err := opt(r); err != nil {
+			return err
+		}
+	}
+
+	if snapshot != "" {
+		if err := r.seedSnapshot(snapshot); err != nil {
+			return errors.Wrap(err, "seed snapshot")
+		}
+	}
+
+	return raft.RecoverCluster(
+		r.cfg,
+		r.fsm,
+		r.logStore,
+		r.stableStore,
+		r.snapshotStore,
+		r.transport,
+		raft.Configuration{Servers: r.clusters},
+	)
+}
+
+// Recover forces a new single node cluster out of the data dir, or out of the snapshot file of
+// the recover subcommand. it runs before the server starts, the other members must be wiped and
+// join the recovered node again
+func Recover(cfg *config.Config) error {
+	db, err := newStoreBackend(cfg.Store, cfg.Node.DataDir)
+	if err != nil {
+		return errors.Wrap(err, "Recover")
+	}
+	defer db.Close()
+
+	keyring, err := loadKeyring(cfg.Store.Encryption)
+	if err != nil {
+		return errors.Wrap(err, "Recover")
+	}
+
+	// the bolt files are opened here, so that they are closed before the server opens them again
+	logStore, err := raftboltdb.NewBoltStore(filepath.Join(cfg.Node.DataDir, RaftLog))
+	if err != nil {
+		return errors.Wrap(err, "Recover")
+	}
+	defer logStore.Close()
+
+	stableStore, err := newBoltStableStore(filepath.Join(cfg.Node.DataDir, RaftStable), db)
+	if err != nil {
+		return errors.Wrap(err, "Recover")
+	}
+	defer stableStore.Close()
+
+	snapshotStore, err := raft.NewFileSnapshotStore(cfg.Node.DataDir, int(cfg.Node.MaxSnapshots), os.Stderr)
+	if err != nil {
+		return errors.Wrap(err, "Recover")
+	}
+
+	// the transport only encodes the peer addresses of the snapshot
+	_, transport := raft.NewInmemTransport(raft.ServerAddress(cfg.Node.PeerAddr()))
+	defer transport.Close()
+
+	if err = RecoverRaft(
+		cfg.Env().RecoverSnapshot(),
+		RaftWithStdFSM(db),
+		RaftWithKeyring(keyring),
+		RaftWithLegacySnapshot(!cfg.Store.StrictSnapshot),
+		RaftWithStores(NewSealedLogStore(logStore, keyring), stableStore, snapshotStore),
+		RaftWithTransport(transport),
+		RaftWithConfig(newRaftConfig(cfg.Node.ID, cfg.Raft)),
+		RaftWithClusters([]raft.Server{{
+			Suffrage: raft.Voter,
+			ID:       raft.ServerID(cfg.Node.ID),
+			Address:  raft.ServerAddress(cfg.Node.PeerAddr()),
+		}}),
+	); err != nil {
+		return errors.Wrap(err, "Recover")
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package rqd_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/RealFax/RedQueen/api/serverpb"
+	red "github.com/RealFax/RedQueen/internal/rqd"
+	"github.com/RealFax/RedQueen/internal/rqd/store"
+	"github.com/hashicorp/raft"
+	raftboltdb "github.com/hashicorp/raft-boltdb/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// recoverNode keeps the state of a node across restarts
+type recoverNode struct {
+	db       store.Store
+	logs     *raftboltdb.BoltStore
+	snapshot *raft.InmemSnapshotStore
+}
+
+func newRecoverNode(t *testing.T) *recoverNode {
+	logs, err := raftboltdb.NewBoltStore(filepath.Join(t.TempDir(), red.RaftLog))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = logs.Close() })
+
+	return &recoverNode{
+		db:       newTestStore(t),
+		logs:     logs,
+		snapshot: raft.NewInmemSnapshotStore(),
+	}
+}
+
+func (n *recoverNode) options(opts ...red.RaftServerOption) []red.RaftServerOption {
+	cfg := raft.DefaultConfig()
+	cfg.LocalID = raft.ServerID(nodeID(0))
+	cfg.HeartbeatTimeout = 50 * time.Millisecond
+	cfg.ElectionTimeout = 50 * time.Millisecond
+	cfg.LeaderLeaseTimeout = 50 * time.Millisecond
+	cfg.CommitTimeout = 5 * time.Millisecond
+
+	_, transport := raft.NewInmemTransport(raft.ServerAddress(nodeID(0)))
+	return append([]red.RaftServerOption{
+		red.RaftWithConfig(cfg),
+		red.RaftWithStdFSM(n.db),
+		red.RaftWithStores(n.logs, n.logs, n.snapshot),
+		red.RaftWithTransport(transport),
+	}, opts...)
+}
+
+func (n *recoverNode) start(t *testing.T, opts ...red.RaftServerOption) *red.Raft {
+	r, err := red.NewRaftWithOptions(n.options(opts...)...)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = r.Shutdown().Error() })
+
+	require.Eventually(t, func() bool {
+		return r.State() == raft.Leader
+	}, 5*time.Second, 10*time.Millisecond)
+	return r
+}
+
+func recoverClusters() red.RaftServerOption {
+	return red.RaftWithClusters([]raft.Server{{
+		ID:      raft.ServerID(nodeID(0)),
+		Address: raft.ServerAddress(nodeID(0)),
+	}})
+}
+
+func TestRecoverRaft(t *testing.T) {
+	node := newRecoverNode(t)
+	r := node.start(t, red.RaftWithBootstrap(), recoverClusters())
+	require.ErrorIs(t, red.NewRaftSingeLogApply(r.Apply).Apply(nil, &serverpb.RaftLogPayload{
+		Command: serverpb.RaftLogCommand_Set,
+		Key:     []byte("key"),
+		Value:   []byte("value"),
+	}, time.Second), red.ErrApplyLogDone)
+
+	// the second voter never answers, the quorum is lost
+	_ = r.AddVoter(raft.ServerID(nodeID(1)), raft.ServerAddress(nodeID(1)), 0, time.Second).Error()
+	require.NoError(t, r.Shutdown().Error())
+
+	require.NoError(t, red.RecoverRaft("", node.options(recoverClusters())...))
+
+	r = node.start(t)
+	s, ok := suffrage(t, r, nodeID(0))
+	require.True(t, ok)
+	require.Equal(t, raft.Voter, s)
+	_, ok = suffrage(t, r, nodeID(1))
+	require.False(t, ok)
+
+	value, err := node.db.Get([]byte("key"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("value"), value.Data)
+}
+
+func TestRecoverRaft_Snapshot(t *testing.T) {
+	source := newTestStore(t)
+	require.NoError(t, source.Set([]byte("key"), []byte("value")))
+
+	snapshot, err := source.Snapshot(store.SnapshotMeta{Index: 42, Term: 3})
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "state.bin")
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	_, err = io.Copy(f, snapshot)
+	require.NoError(t, err)
+	require.NoError(t, snapshot.Close())
+	require.NoError(t, f.Close())
+
+	node := newRecoverNode(t)
+	require.NoError(t, red.RecoverRaft(path, node.options(recoverClusters())...))
+
+	r := node.start(t)
+	require.GreaterOrEqual(t, r.LastIndex(), uint64(42))
+	value, err := node.db.Get([]byte("key"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("value"), value.Data)
+
+	// a node with raft state is recovered from its own data
+	require.NoError(t, r.Shutdown().Error())
+	require.ErrorIs(t, red.RecoverRaft(path, node.options(recoverClusters())...), red.ErrRecoverExistingState)
+}
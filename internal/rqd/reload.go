@@ -0,0 +1,78 @@
+package rqd
+
+import (
+	"time"
+
+	"github.com/RealFax/RedQueen/internal/rqd/config"
+	"github.com/RealFax/RedQueen/pkg/tlsutil"
+	"github.com/pkg/errors"
+)
+
+// certWatchInterval is how often the certificate files are checked for changes
+const certWatchInterval = 10 * time.Second
+
+func (s *Server) keyPairReloaders() map[string]*tlsutil.KeyPairReloader {
+	reloaders := make(map[string]*tlsutil.KeyPairReloader, 2)
+	if s.certReloader != nil {
+		reloaders["tls"] = s.certReloader
+	}
+	if s.peerCertReloader != nil {
+		reloaders["peer-tls"] = s.peerCertReloader
+	}
+	return reloaders
+}
+
+// reloadCertificates loads the changed key pairs, or all of them when force
+func (s *Server) reloadCertificates(force bool) error {
+	var (
+		logger  = s.raft.cfg.Logger.Named("reload")
+		lastErr error
+	)
+	for name, reloader := range s.keyPairReloaders() {
+		reloaded, err := reloader.Reload(force)
+		if err != nil {
+			// the previous key pair is still served
+			logger.Warn("failed to reload key pair", "name", name, "error", err)
+			lastErr = errors.Wrap(err, name)
+			continue
+		}
+		if reloaded {
+			logger.Info("key pair reloaded", "name", name)
+		}
+	}
+	return lastErr
+}
+
+// watchCertificates reloads the key pairs when their files change
+func (s *Server) watchCertificates() {
+	if len(s.keyPairReloaders()) == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(certWatchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			_ = s.reloadCertificates(false)
+		}
+	}
+}
+
+// Reload applies the reloadable options of cfg, e.g. on SIGHUP. the key pairs are read again
+// from their files and the new basic auth users are seeded, other options require a restart
+func (s *Server) Reload(cfg *config.Config) error {
+	err := s.reloadCertificates(true)
+
+	switch {
+	case s.basicAuth != nil && len(cfg.BasicAuth) != 0:
+		s.basicAuth.StoreSeed(cfg.BasicAuth)
+		go s.seedUsers()
+	case s.basicAuth != nil || len(cfg.BasicAuth) != 0:
+		// the auth interceptors are installed on startup
+		s.raft.cfg.Logger.Named("reload").Warn("enabling or disabling basic auth requires a restart")
+	}
+	return err
+}
@@ -3,4 +3,5 @@ package rqd
 const (
 	StoreSuffix = "data"
 	RaftLog     = "raft-log.db"
+	RaftStable  = "raft-stable.db"
 )
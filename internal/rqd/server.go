@@ -13,7 +13,6 @@ import (
 	"github.com/RealFax/RedQueen/pkg/grpcutil"
 	"github.com/RealFax/RedQueen/pkg/httputil"
 	"github.com/RealFax/RedQueen/pkg/tlsutil"
-	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/raft"
 	"github.com/julienschmidt/httprouter"
 	"github.com/pkg/errors"
@@ -24,6 +23,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -33,6 +33,12 @@ import (
 	"github.com/RealFax/RedQueen/api/serverpb"
 )
 
+// MetadataReservedAccess is the grpc metadata (http header) a reserved admin sets to access reserved namespaces
+const MetadataReservedAccess = "X-Reserved-Access"
+
+// ErrServerGoingAway is returned to the watchers and the writers while the server shuts down
+var ErrServerGoingAway = errors.New("server going away")
+
 var (
 	bufferPool = sync.Pool{New: func() any {
 		return &bytes.Buffer{}
@@ -42,6 +48,10 @@ var (
 type Server struct {
 	close atomic.Bool
 
+	// goingAway is closed once the shutdown starts, the streams return on it
+	goingAway     chan struct{}
+	goingAwayOnce sync.Once
+
 	ctx    context.Context
 	cancel context.CancelCauseFunc
 
@@ -52,7 +62,16 @@ type Server struct {
 	lockerBackend dlocker.Backend
 	logApplyer    RaftApply
 
+	// certReloader and peerCertReloader serve the key pairs of the tls and peer tls files,
+	// basicAuth the basic auth users and their seed. they are swapped on reload
+	certReloader     *tlsutil.KeyPairReloader
+	peerCertReloader *tlsutil.KeyPairReloader
+	basicAuth        *UserAuth
+
 	raft        *Raft
+	autopilot   *Autopilot
+	audit       *Auditor
+	rateLimiter *RateLimiter
 	grpcServer  *grpc.Server
 	httpServer  *http.Server
 	pprofServer *pprofServer
@@ -77,6 +96,19 @@ func (s *Server) trySwapContext(namespace *string) (store.Actions, error) {
 	return actions, nil
 }
 
+// allowReserved reports whether the user is allowed to access reserved namespaces
+func (s *Server) allowReserved(username string) bool {
+	return slices.Contains(s.cfg.Reserved.Admins, username)
+}
+
+// filterReserved removes the reserved namespaces unless access is granted
+func (s *Server) filterReserved(namespaces []string, access bool) []string {
+	if access {
+		return namespaces
+	}
+	return slices.DeleteFunc(namespaces, store.IsReservedNamespace)
+}
+
 func (s *Server) applyLog(ctx context.Context, p *serverpb.RaftLogPayload, timeout time.Duration) error {
 	if err := s.logApplyer.Apply(&ctx, p, timeout); err != nil {
 		if errors.Is(err, ErrApplyLogTimeTravelDone) || errors.Is(err, ErrApplyLogDone) {
@@ -86,11 +118,28 @@ func (s *Server) applyLog(ctx context.Context, p *serverpb.RaftLogPayload, timeo
 	}
 	// waiting response
 	<-ctx.Done()
-	if errors.Is(context.Cause(ctx), ErrApplyLogDone) {
+	cause := context.Cause(ctx)
+	if errors.Is(cause, ErrApplyLogDone) || errors.Is(cause, ErrApplyLogTimeTravelDone) {
 		return nil
 	}
 
-	return ctx.Err()
+	return cause
+}
+
+// notifyState sends state to a leader monitor without blocking, a state the monitor hasn't
+// read yet is stale and replaced. the monitors may have returned already
+func notifyState(notify chan bool, state bool) {
+	for {
+		select {
+		case notify <- state:
+			return
+		default:
+		}
+		select {
+		case <-notify:
+		default:
+		}
+	}
 }
 
 func (s *Server) stateUpdater() {
@@ -99,8 +148,17 @@ func (s *Server) stateUpdater() {
 		case <-s.ctx.Done():
 			return
 		case state := <-s.raft.LeaderCh():
+			if state {
+				go s.publishLeaderClientAddr()
+				if s.tokenEnabled() {
+					go s.ensureTokenKey()
+				}
+				if s.basicAuth != nil {
+					go s.seedUsers()
+				}
+			}
 			s.stateNotify.Range(func(_, val any) bool {
-				val.(chan bool) <- state
+				notifyState(val.(chan bool), state)
 				return true
 			})
 		}
@@ -111,7 +169,8 @@ func (s *Server) initTLS() (err error) {
 	var cert tls.Certificate
 	switch {
 	case s.cfg.Node.TLS.CertFile != "" && s.cfg.Node.TLS.KeyFile != "":
-		cert, err = tls.LoadX509KeyPair(s.cfg.Node.TLS.CertFile, s.cfg.Node.TLS.KeyFile)
+		// the key pair is served by the reloader
+		s.certReloader, err = tlsutil.NewKeyPairReloader(s.cfg.Node.TLS.CertFile, s.cfg.Node.TLS.KeyFile)
 	case s.cfg.Node.TLS.Auto:
 		cert, err = tlsutil.GenX509KeyPair(pkix.Name{
 			Country:            []string{"Earth"},
@@ -119,19 +178,78 @@ func (s *Server) initTLS() (err error) {
 			OrganizationalUnit: []string{"RedQueen"},
 			CommonName:         "*",
 		})
+	default:
+		// tls is disabled
+		return nil
 	}
 	if err != nil {
 		return
 	}
 
 	s.tlsConfig = &tls.Config{
-		Certificates:       []tls.Certificate{cert},
 		InsecureSkipVerify: expr.If(s.cfg.Node.TLS.CertFile != "" && s.cfg.Node.TLS.KeyFile != "", false, true),
 		NextProtos:         []string{"http/1.1", "http/2.0"},
 	}
+	if s.certReloader != nil {
+		s.tlsConfig.GetCertificate = s.certReloader.GetCertificate
+	} else {
+		s.tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if !s.clientAuthEnabled() {
+		return nil
+	}
+	if s.tlsConfig.ClientCAs, err = tlsutil.LoadCertPool(s.cfg.Node.TLS.ClientCAFile); err != nil {
+		return err
+	}
+	s.tlsConfig.ClientAuth = map[config.EnumClientAuth]tls.ClientAuthType{
+		config.ClientAuthRequest: tls.RequestClientCert,
+		config.ClientAuthRequire: tls.RequireAnyClientCert,
+		config.ClientAuthVerify:  tls.RequireAndVerifyClientCert,
+	}[s.cfg.Node.TLS.ClientAuth]
 	return nil
 }
 
+func (s *Server) clientAuthEnabled() bool {
+	return s.tlsConfig != nil && s.cfg.Node.TLS.ClientAuth != "" && s.cfg.Node.TLS.ClientAuth != config.ClientAuthNone
+}
+
+// clientIdentity returns the identity of the verified client certificate, it authenticates the
+// client alongside the basic auth
+func (s *Server) clientIdentity(state tls.ConnectionState) (string, bool) {
+	return tlsutil.ClientIdentity(state, s.tlsConfig.ClientCAs)
+}
+
+// raftTransport returns the raft transport option, mutual tls when the peer tls is configured
+func (s *Server) raftTransport() RaftServerOption {
+	var (
+		maxPool = int(expr.OrDefault(s.cfg.Raft.TransportMaxPool, config.DefaultRaftTransportMaxPool))
+		timeout = expr.OrDefault(s.cfg.Raft.TransportTimeout, config.DefaultRaftTransportTimeout)
+	)
+	if !s.cfg.Node.PeerTLS.Enabled() {
+		return RaftWithTCPTransport(s.cfg.Node.ListenPeerAddr, s.cfg.Node.PeerAddr(), maxPool, timeout, os.Stderr)
+	}
+
+	return func(r *Raft) (err error) {
+		if s.peerCertReloader, err = tlsutil.NewKeyPairReloader(s.cfg.Node.PeerTLS.CertFile, s.cfg.Node.PeerTLS.KeyFile); err != nil {
+			return errors.Wrap(err, "peer-tls")
+		}
+		ca, err := tlsutil.LoadCertPool(s.cfg.Node.PeerTLS.CAFile)
+		if err != nil {
+			return errors.Wrap(err, "peer-tls")
+		}
+		return RaftWithMutualTLSTransport(
+			s.cfg.Node.ListenPeerAddr,
+			s.cfg.Node.PeerAddr(),
+			maxPool,
+			timeout,
+			os.Stderr,
+			s.peerCertReloader.Certificate,
+			ca,
+		)(r)
+	}
+}
+
 func (s *Server) newNetListener(network, addr string) (net.Listener, error) {
 	if s.tlsConfig != nil {
 		return tls.Listen(network, addr, s.tlsConfig)
@@ -145,10 +263,39 @@ func (s *Server) registerRPCServer() {
 		opts = append(opts, grpc.Creds(credentials.NewTLS(s.tlsConfig)))
 	}
 
-	if s.cfg.BasicAuth != nil && len(s.cfg.BasicAuth) != 0 {
-		auth := grpcutil.NewBasicAuth(grpcutil.NewMemoryBasicAuthFunc(s.cfg.BasicAuth))
-		opts = append(opts, grpc.UnaryInterceptor(auth.Unary), grpc.StreamInterceptor(auth.Stream))
+	var auth *grpcutil.BasicAuth
+	switch {
+	case s.clientAuthEnabled():
+		var basic grpcutil.BasicAuthFunc
+		if s.basicAuth != nil {
+			basic = s.basicAuth.Auth
+		}
+		auth = grpcutil.NewAuth(basic, s.clientIdentity)
+	case s.basicAuth != nil:
+		auth = grpcutil.NewBasicAuth(s.basicAuth.Auth)
 	}
+	// authenticate, rate limit, audit, then authorize so that the denied requests are audited with
+	// their user. the throttled ones are not, a client hammering the server would flood the audit
+	var (
+		unary  []grpc.UnaryServerInterceptor
+		stream []grpc.StreamServerInterceptor
+	)
+	if auth != nil {
+		auth.WithToken(s.verifyToken)
+		unary, stream = append(unary, auth.Unary), append(stream, auth.Stream)
+	}
+	if s.rateLimiter != nil {
+		limit := grpcutil.NewAuthorize(s.rateLimiter.Limit)
+		unary, stream = append(unary, limit.Unary), append(stream, limit.Stream)
+	}
+	if s.audit != nil {
+		unary = append(unary, s.audit.Unary)
+	}
+	if auth != nil && s.cfg.Rbac.Enabled {
+		authorize := grpcutil.NewAuthorize(s.authorizeRPC)
+		unary, stream = append(unary, authorize.Unary), append(stream, authorize.Stream)
+	}
+	opts = append(opts, grpc.ChainUnaryInterceptor(unary...), grpc.ChainStreamInterceptor(stream...))
 
 	if s.grpcServer == nil {
 		s.grpcServer = grpc.NewServer(opts...)
@@ -158,6 +305,8 @@ func (s *Server) registerRPCServer() {
 	serverpb.RegisterKVServer(s.grpcServer, rpcServer)
 	serverpb.RegisterLockerServer(s.grpcServer, rpcServer)
 	serverpb.RegisterRedQueenServer(s.grpcServer, rpcServer)
+	serverpb.RegisterNamespaceServer(s.grpcServer, rpcServer)
+	serverpb.RegisterAuthServer(s.grpcServer, rpcServer)
 }
 
 func (s *Server) registerHttpServer() {
@@ -169,7 +318,7 @@ func (s *Server) registerHttpServer() {
 		}
 	}
 
-	httpHandlers := &v1HttpServer{Server: s}
+	h := &v1HttpServer{Server: s}
 	router := httprouter.New()
 	router.NotFound = httputil.WrapE(func(w http.ResponseWriter, r *http.Request) error {
 		return httputil.NewStatus(http.StatusNotFound, 0, "Not Found")
@@ -178,30 +327,64 @@ func (s *Server) registerHttpServer() {
 		httputil.Any(http.StatusServiceUnavailable, 0).Message("Service Unavailable").Ok(w)
 	}
 
-	router.Handler(http.MethodGet, "/", httputil.WrapE(httpHandlers.Stats))
-	router.Handler(http.MethodPost, "/lock", httputil.WrapE(httpHandlers.Lock))
-	router.Handler(http.MethodDelete, "/lock", httputil.WrapE(httpHandlers.Unlock))
-	router.Handler(http.MethodPatch, "/lock", httputil.WrapE(httpHandlers.TryLock))
-	router.Handler(http.MethodPost, "/raft/add", httputil.WrapE(httpHandlers.AppendCluster))
+	var (
+		read    = serverpb.Permission_read
+		write   = serverpb.Permission_write
+		lock    = serverpb.Permission_lock
+		admin   = serverpb.Permission_admin
+		body    = h.bodyScope(false)
+		lockID  = h.bodyScope(true)
+		cluster = clusterScope
+	)
+
+	router.Handler(http.MethodGet, "/", httputil.WrapE(h.Stats))
+	router.Handler(http.MethodPost, "/lock", h.limited(rateLock, lockID, h.audited(lockID, h.authorize(lock, lockID, httputil.WrapE(h.Lock)))))
+	router.Handler(http.MethodDelete, "/lock", h.limited(rateLock, lockID, h.audited(lockID, h.authorize(lock, lockID, httputil.WrapE(h.Unlock)))))
+	router.Handler(http.MethodPatch, "/lock", h.limited(rateLock, lockID, h.audited(lockID, h.authorize(lock, lockID, httputil.WrapE(h.TryLock)))))
+	router.Handler(http.MethodGet, "/raft/members", httputil.WrapE(h.MemberList))
+	router.Handler(http.MethodPost, "/raft/add", h.audited(serverScope, h.authorize(admin, cluster, httputil.WrapE(h.AppendCluster))))
+	router.Handler(http.MethodPost, "/raft/remove", h.audited(serverScope, h.authorize(admin, cluster, httputil.WrapE(h.RemoveCluster))))
+	router.Handler(http.MethodPost, "/raft/demote", h.audited(serverScope, h.authorize(admin, cluster, httputil.WrapE(h.DemoteVoter))))
+	router.Handler(http.MethodPost, "/raft/promote", h.audited(serverScope, h.authorize(admin, cluster, httputil.WrapE(h.PromoteLearner))))
+	router.Handler(http.MethodPost, "/raft/transfer", h.audited(serverScope, h.authorize(admin, cluster, httputil.WrapE(h.LeadershipTransfer))))
 
 	// ---- action handlers ----
-	router.Handler(http.MethodPut, "/action/:bucket", httputil.WrapE(httpHandlers.Set))
-	router.Handler(http.MethodGet, "/action/:bucket", httputil.WrapE(httpHandlers.Get))
-	router.Handler(http.MethodDelete, "/action/:bucket", httputil.WrapE(httpHandlers.Delete))
-	router.Handler(http.MethodGet, "/action/:bucket/scan", httputil.WrapE(httpHandlers.PrefixScan))
-	router.Handler(http.MethodPut, "/action/:bucket/try", httputil.WrapE(httpHandlers.TrySet))
+	router.Handler(http.MethodPut, "/action/:bucket", h.limited(rateWrite, body, h.audited(body, h.authorize(write, body, httputil.WrapE(h.Set)))))
+	router.Handler(http.MethodGet, "/action/:bucket", h.limited(rateRead, h.namespaceScope, h.authorize(read, h.queryScope("key"), httputil.WrapE(h.Get))))
+	router.Handler(http.MethodDelete, "/action/:bucket", h.limited(rateWrite, body, h.audited(body, h.authorize(write, body, httputil.WrapE(h.Delete)))))
+	router.Handler(http.MethodGet, "/action/:bucket/scan", h.limited(rateRead, h.namespaceScope, h.authorize(read, h.queryScope("prefix"), httputil.WrapE(h.PrefixScan))))
+	router.Handler(http.MethodGet, "/action/:bucket/history", h.limited(rateRead, h.namespaceScope, h.authorize(read, h.queryScope("key"), httputil.WrapE(h.History))))
+	router.Handler(http.MethodPut, "/action/:bucket/try", h.limited(rateWrite, body, h.audited(body, h.authorize(write, body, httputil.WrapE(h.TrySet)))))
+
+	// ---- auth handlers ----
+	router.Handler(http.MethodPost, "/auth/token", httputil.WrapE(h.Authenticate))
+
+	// ---- namespace handlers ----
+	router.Handler(http.MethodGet, "/namespace", httputil.WrapE(h.NamespaceList))
+	router.Handler(http.MethodPut, "/namespace/:bucket", h.audited(h.namespaceScope, h.authorize(admin, h.namespaceScope, httputil.WrapE(h.NamespaceCreate))))
+	router.Handler(http.MethodDelete, "/namespace/:bucket", h.audited(h.namespaceScope, h.authorize(admin, h.namespaceScope, httputil.WrapE(h.NamespaceDrop))))
+	router.Handler(http.MethodGet, "/namespace/:bucket/stats", h.limited(rateRead, h.namespaceScope, h.authorize(read, h.namespaceScope, httputil.WrapE(h.NamespaceStats))))
+	router.Handler(http.MethodGet, "/namespace/:bucket/quota", h.limited(rateRead, h.namespaceScope, h.authorize(read, h.namespaceScope, httputil.WrapE(h.NamespaceGetQuota))))
+	router.Handler(http.MethodPut, "/namespace/:bucket/quota", h.audited(h.namespaceScope, h.authorize(admin, h.namespaceScope, httputil.WrapE(h.NamespaceSetQuota))))
 
 	s.httpServer.Handler = httputil.UseMiddleware(router, func(w http.ResponseWriter, r *http.Request) bool {
 		w.Header().Add("Server", version.String())
 		return true
 	})
 
-	if s.cfg.BasicAuth != nil && len(s.cfg.BasicAuth) != 0 {
-		// use basic-auth
-		s.httpServer.Handler = httputil.NewBasicAuth(
-			s.httpServer.Handler,
-			httputil.NewMemoryBasicAuthFunc(s.cfg.BasicAuth),
+	if s.tokenEnabled() {
+		// use client certificates, then bearer tokens, then basic-auth
+		var (
+			basic httputil.BasicAuthFunc
+			cert  httputil.CertAuthFunc
 		)
+		if s.basicAuth != nil {
+			basic = s.basicAuth.Auth
+		}
+		if s.clientAuthEnabled() {
+			cert = s.clientIdentity
+		}
+		s.httpServer.Handler = httputil.NewAuth(s.httpServer.Handler, basic, cert, s.verifyToken)
 	}
 
 	if s.tlsConfig != nil {
@@ -237,11 +420,96 @@ func (s *Server) ListenAndServe() error {
 	return nil
 }
 
+func (s *Server) notifyGoingAway() {
+	s.goingAwayOnce.Do(func() {
+		close(s.goingAway)
+	})
+}
+
+// GracefulShutdown stops accepting requests and drains the in-flight ones for up to timeout,
+// then hands the leadership off to another voter (if it's the leader) and shuts down
+func (s *Server) GracefulShutdown(timeout time.Duration) {
+	if !s.close.CompareAndSwap(false, true) {
+		return
+	}
+
+	var (
+		logger      = s.raft.cfg.Logger.Named("shutdown")
+		ctx, cancel = context.WithTimeout(context.Background(), timeout)
+		drained     sync.WaitGroup
+	)
+	defer cancel()
+
+	s.notifyGoingAway()
+
+	// the streams return on going away, so GracefulStop only waits for the unary calls
+	drained.Add(1)
+	go func() {
+		defer drained.Done()
+		s.grpcServer.GracefulStop()
+	}()
+	if s.cfg.Node.ListenHttpAddr != "" && s.httpServer != nil {
+		drained.Add(1)
+		go func() {
+			defer drained.Done()
+			_ = s.httpServer.Shutdown(ctx)
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		drained.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		logger.Warn("drain timed out, closing the remaining connections", "timeout", timeout)
+		s.grpcServer.Stop()
+		if s.httpServer != nil {
+			_ = s.httpServer.Close()
+		}
+		<-done
+	}
+
+	// only the leader replicates its audit records, the drained requests are audited too
+	if s.audit != nil && s.raft.State() == raft.Leader {
+		s.audit.Flush(ctx)
+	}
+
+	// flush the merged logs of the drained requests
+	if err := s.logApplyer.Close(); err != nil {
+		logger.Warn("failed to close log applier", "error", err)
+	}
+
+	// the writes are applied by the leader only, hand it off once nothing is left to apply
+	if s.raft.State() == raft.Leader {
+		if err := s.raft.TransferLeadership(""); err != nil {
+			logger.Warn("failed to transfer leadership", "error", err)
+		} else {
+			logger.Info("leadership transferred")
+		}
+	}
+
+	s.cancel(ErrServerGoingAway)
+	s.raft.Shutdown()
+
+	if s.cfg.PPROF {
+		s.pprofServer.Close()
+	}
+	if s.audit != nil {
+		if err := s.audit.Close(); err != nil {
+			logger.Warn("failed to close audit file", "error", err)
+		}
+	}
+}
+
 func (s *Server) Shutdown() {
 	if !s.close.CompareAndSwap(false, true) {
 		return
 	}
 
+	s.notifyGoingAway()
 	s.cancel(errors.New("server close"))
 
 	s.raft.Shutdown()
@@ -256,6 +524,9 @@ func (s *Server) Shutdown() {
 	if s.cfg.PPROF {
 		s.pprofServer.Close()
 	}
+	if s.audit != nil {
+		_ = s.audit.Close()
+	}
 	return
 }
 
@@ -263,6 +534,7 @@ func NewServer(cfg *config.Config) (*Server, error) {
 	var (
 		err    error
 		server = &Server{
+			goingAway: make(chan struct{}),
 			clusterID: cfg.Node.ID,
 			cfg:       cfg,
 		}
@@ -282,43 +554,56 @@ func NewServer(cfg *config.Config) (*Server, error) {
 		return nil, errors.Wrap(err, "NewServer")
 	}
 
+	if len(cfg.BasicAuth) != 0 {
+		server.basicAuth = NewUserAuth(server.store, cfg.BasicAuth)
+	}
+
 	// try init tls config
 	if err = server.initTLS(); err != nil {
 		return nil, errors.Wrap(err, "NewServer")
 	}
 
+	if cfg.Rbac.Enabled && server.basicAuth == nil && !server.clientAuthEnabled() {
+		return nil, errors.New("NewServer: rbac requires basic auth or client certificates")
+	}
+
+	raftCfg := newRaftConfig(cfg.Node.ID, cfg.Raft)
+	if cfg.Audit.Enabled {
+		var applyFC AuditApplyFunc
+		if cfg.Audit.Replicate {
+			applyFC = server.replicateAudit
+		}
+		if server.audit, err = NewAuditor(cfg.Audit, cfg.Node.DataDir, cfg.Node.ID, raftCfg.Logger, applyFC); err != nil {
+			return nil, errors.Wrap(err, "NewServer")
+		}
+	}
+
+	server.rateLimiter = NewRateLimiter(cfg.RateLimit)
+
 	// init server grpc
 	server.registerRPCServer()
 
 	// init server http
 	server.registerHttpServer()
 
+	// the raft log is sealed with the keyring of the store
+	keyring, err := loadKeyring(cfg.Store.Encryption)
+	if err != nil {
+		return nil, errors.Wrap(err, "NewServer")
+	}
+
 	// init server raft
 	if server.raft, err = NewRaftWithOptions(
 		RaftWithContext(server.ctx),
 		RaftWithStdFSM(server.store),
-		RaftWithBoltLogStore(filepath.Join(cfg.Node.DataDir, RaftLog)),
-		RaftWithStdStableStore(server.store),
+		RaftWithBoltLogStore(filepath.Join(cfg.Node.DataDir, RaftLog), keyring),
+		RaftWithBoltStableStore(filepath.Join(cfg.Node.DataDir, RaftStable), server.store),
 		RaftWithFileSnapshotStore(cfg.Node.DataDir, int(cfg.Node.MaxSnapshots), os.Stderr),
-		RaftWithTCPTransport(cfg.Node.ListenPeerAddr, 32, 10*time.Second, os.Stderr),
-		RaftWithConfig(func() *raft.Config {
-			c := raft.DefaultConfig()
-			c.LocalID = raft.ServerID(cfg.Node.ID)
-			c.LogLevel = "INFO"
-			c.Logger = hclog.New(&hclog.LoggerOptions{
-				Name:            "rqd",
-				Level:           hclog.Info,
-				Output:          os.Stderr,
-				IncludeLocation: false,
-				TimeFormat:      time.RFC3339,
-				TimeFn:          time.Now,
-				Color:           hclog.AutoColor,
-				ColorHeaderOnly: true,
-			})
-			return c
-		}()),
+		server.raftTransport(),
+		RaftWithConfig(raftCfg),
 		func() RaftServerOption {
-			if cfg.Env().FirstRun() {
+			// joining nodes are added by a member instead, recovered nodes already hold the configuration
+			if cfg.Env().FirstRun() && !cfg.Env().Recovering() && len(cfg.Cluster.Join) == 0 {
 				return RaftWithBootstrap()
 			}
 			return RaftWithEmpty()
@@ -359,8 +644,28 @@ func NewServer(cfg *config.Config) (*Server, error) {
 		server.logApplyer = NewRaftSingeLogApply(server.raft.Apply)
 	}
 
+	var joinTLS *tls.Config
+	if len(cfg.Cluster.Join) != 0 {
+		if joinTLS, err = server.joinTLSConfig(); err != nil {
+			return nil, errors.Wrap(err, "NewServer")
+		}
+	}
+
 	// start daemon service
 	go server.stateUpdater()
+	if len(cfg.Cluster.Join) != 0 {
+		go server.joinCluster(joinTLS)
+	}
+	go server.watchCertificates()
+	if server.audit != nil {
+		go server.audit.Run(server.ctx)
+	}
+	if cfg.Autopilot.Enabled {
+		server.autopilot = NewAutopilot(server.raft, cfg.Autopilot)
+		server.autopilot.Promotable(server.promotable)
+		server.autopilot.OnChange(server.autopilotChanged)
+		go server.autopilot.Run(server.ctx)
+	}
 
 	return server, nil
 }
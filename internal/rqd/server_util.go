@@ -2,13 +2,40 @@ package rqd
 
 import (
 	"errors"
+	"github.com/RealFax/RedQueen/api/serverpb"
 	config2 "github.com/RealFax/RedQueen/internal/rqd/config"
 	"github.com/RealFax/RedQueen/internal/rqd/store"
 	"github.com/RealFax/RedQueen/internal/rqd/store/nuts"
+	"github.com/RealFax/RedQueen/pkg/expr"
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/raft"
+	"os"
 	"path/filepath"
+	"time"
 )
 
+// loadKeyring returns the keyring of the store encryption, nil when it's disabled
+func loadKeyring(cfg config2.StoreEncryption) (*store.Keyring, error) {
+	switch {
+	case cfg.Keyring != "":
+		return store.LoadKeyring(cfg.Keyring)
+	case cfg.KeyFile != "":
+		keyring, err := store.LoadKeyring(cfg.KeyFile)
+		if err == nil && keyring.Len() != 1 {
+			return nil, errors.New("store encryption key-file holds more than one key, use a keyring")
+		}
+		return keyring, err
+	default:
+		return nil, nil
+	}
+}
+
 func newNutsStore(cfg config2.Store, dir string) (store.Store, error) {
+	keyring, err := loadKeyring(cfg.Encryption)
+	if err != nil {
+		return nil, err
+	}
+
 	if cfg.Nuts.StrictMode {
 		nuts.EnableStrictMode()
 	} else {
@@ -19,6 +46,12 @@ func newNutsStore(cfg config2.Store, dir string) (store.Store, error) {
 		NodeNum: cfg.Nuts.NodeNum,
 		Sync:    cfg.Nuts.Sync,
 		DataDir: filepath.Join(dir, StoreSuffix),
+		History: nuts.History{
+			MaxVersions: cfg.History.MaxVersions,
+			Namespaces:  cfg.History.Namespaces,
+		},
+		Keyring:        keyring,
+		LegacySnapshot: !cfg.StrictSnapshot,
 		RWMode: func() nuts.RWMode {
 			switch cfg.Nuts.RWMode {
 			case config2.NutsRWModeFileIO:
@@ -41,3 +74,44 @@ func newStoreBackend(cfg config2.Store, dir string) (store.Store, error) {
 	}
 	return handle(cfg, dir)
 }
+
+func historyVersions(values []*store.Value) []*serverpb.HistoryResponse_Version {
+	versions := make([]*serverpb.HistoryResponse_Version, 0, len(values))
+	for _, value := range values {
+		versions = append(versions, &serverpb.HistoryResponse_Version{
+			Revision:  value.Revision,
+			Timestamp: value.Timestamp,
+			Ttl:       value.TTL,
+			Value:     value.Data,
+			Deleted:   value.Deleted,
+		})
+	}
+	return versions
+}
+
+func newRaftConfig(id string, cfg config2.Raft) *raft.Config {
+	level := hclog.LevelFromString(string(expr.OrDefault(cfg.LogLevel, config2.EnumRaftLogLevel(config2.DefaultRaftLogLevel))))
+
+	c := raft.DefaultConfig()
+	c.LocalID = raft.ServerID(id)
+	c.HeartbeatTimeout = expr.OrDefault(cfg.HeartbeatTimeout, config2.DefaultRaftHeartbeatTimeout)
+	c.ElectionTimeout = expr.OrDefault(cfg.ElectionTimeout, config2.DefaultRaftElectionTimeout)
+	c.LeaderLeaseTimeout = expr.OrDefault(cfg.LeaderLeaseTimeout, config2.DefaultRaftLeaderLeaseTimeout)
+	c.CommitTimeout = expr.OrDefault(cfg.CommitTimeout, config2.DefaultRaftCommitTimeout)
+	c.SnapshotInterval = expr.OrDefault(cfg.SnapshotInterval, config2.DefaultRaftSnapshotInterval)
+	c.SnapshotThreshold = expr.OrDefault(cfg.SnapshotThreshold, config2.DefaultRaftSnapshotThreshold)
+	c.TrailingLogs = expr.OrDefault(cfg.TrailingLogs, config2.DefaultRaftTrailingLogs)
+	c.MaxAppendEntries = int(expr.OrDefault(cfg.MaxAppendEntries, config2.DefaultRaftMaxAppendEntries))
+	c.LogLevel = level.String()
+	c.Logger = hclog.New(&hclog.LoggerOptions{
+		Name:            "rqd",
+		Level:           level,
+		Output:          os.Stderr,
+		IncludeLocation: false,
+		TimeFormat:      time.RFC3339,
+		TimeFn:          time.Now,
+		Color:           hclog.AutoColor,
+		ColorHeaderOnly: true,
+	})
+	return c
+}
@@ -3,13 +3,18 @@ package store
 import (
 	"context"
 	"io"
+	"time"
 )
 
 type Value struct {
 	Timestamp uint64
-	TTL       uint32
-	Key       []byte
-	Data      []byte
+	// Revision is only set on the values returned by GetRevision and History
+	Revision uint64
+	TTL      uint32
+	// Deleted marks a version recorded by Del, its Data is always nil
+	Deleted bool
+	Key     []byte
+	Data    []byte
 }
 
 type WatchValue struct {
@@ -25,6 +30,14 @@ func (v *WatchValue) Deleted() bool {
 	return v.Value == nil
 }
 
+type NamespaceStats struct {
+	Namespace string
+	// Keys is the number of live keys
+	Keys uint64
+	// Bytes is the sum of the key and value sizes of live keys
+	Bytes uint64
+}
+
 type Watcher interface {
 	Notify() chan *WatchValue
 	Close() error
@@ -33,6 +46,10 @@ type Watcher interface {
 type Actions interface {
 	Current() string
 	Get(key []byte) (value *Value, err error)
+	// GetRevision returns the newest kept version of key whose revision is not greater than revision
+	GetRevision(key []byte, revision uint64) (value *Value, err error)
+	// History returns at most limit kept versions of key, newest first. limit <= 0 means all versions
+	History(key []byte, limit int) ([]*Value, error)
 	PrefixSearchScan(prefix []byte, reg string, offset, limit int) ([]*Value, error)
 	PrefixScan(prefix []byte, offset, limit int) ([]*Value, error)
 	SetWithTTL(key, value []byte, ttl uint32) error
@@ -48,9 +65,24 @@ type Actions interface {
 type Store interface {
 	Actions
 	Swap(namespace string) (Actions, error)
+	Namespaces() ([]string, error)
+	// CreateNamespace creates an empty namespace that outlives its keys
+	CreateNamespace(namespace string) error
+	// DropNamespace deletes the namespace with all its keys
+	DropNamespace(namespace string) error
+	NamespaceStats(namespace string) (*NamespaceStats, error)
+	// KeyUsage returns the size key counts in the usage of namespace, found is false when the key
+	// isn't counted
+	KeyUsage(namespace string, key []byte) (size uint64, found bool, err error)
+	// SetApplyTime sets the time the usage is accounted at, the ttl of the counted keys starts
+	// at it. the wall clock is used until it is set
+	SetApplyTime(t time.Time)
 	Close() error
-	// Snapshot should be in tar & gzip format
-	Snapshot() (io.Reader, error)
+	// Snapshot should be written by SnapshotWriter, it is streamed from a point-in-time view of
+	// the store and the reader must be closed to release that view
+	Snapshot(meta SnapshotMeta) (io.ReadCloser, error)
 	Break(context.Context) error
-	Restore(src io.Reader) (err error)
+	// Restore replaces the store with the snapshot from src, it returns the raft position the
+	// snapshot was taken at
+	Restore(src io.Reader) (meta SnapshotMeta, err error)
 }
@@ -0,0 +1,396 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	envelopeVersion = 1
+	keyIDSize       = 4
+	nonceSize       = 12
+	tagSize         = 16
+	dataKeySize     = 32
+
+	// envelopeHeaderSize is the magic, the version, the id of the keyring key and the data key
+	// wrapped by it
+	envelopeHeaderSize = 4 + 1 + keyIDSize + nonceSize + dataKeySize + tagSize
+	// EnvelopeOverhead is the size a sealed value grows by
+	EnvelopeOverhead = envelopeHeaderSize + nonceSize + tagSize
+
+	// dataKeyUses bounds the values sealed with a data key, their nonces are random
+	dataKeyUses = 1 << 20
+	// openedDataKeys bounds the unwrapped data keys cached by Open
+	openedDataKeys = 64
+
+	snapshotChunkSize = 64 << 10
+	snapshotFinal     = 1 << 31
+)
+
+var (
+	valueMagic    = []byte("\x00RQE")
+	snapshotMagic = []byte("\x00RQS")
+)
+
+var (
+	ErrInvalidKey        = errors.New("invalid encryption key")
+	ErrUnknownKey        = errors.New("sealed with a key missing from the keyring")
+	ErrDecrypt           = errors.New("decrypt error")
+	ErrValueEncrypted    = errors.New("value is encrypted, it requires the keyring")
+	ErrSnapshotEncrypted = errors.New("snapshot is encrypted, it requires the keyring")
+)
+
+type keyringKey struct {
+	id   []byte
+	aead cipher.AEAD
+}
+
+// dataKey is a random key the values are sealed with, the envelope header carries it wrapped by
+// a key of the keyring
+type dataKey struct {
+	header []byte
+	aead   cipher.AEAD
+	uses   int
+}
+
+// Keyring seals the values and the snapshots with AES-GCM envelope encryption. the first key
+// wraps the new data keys, the others only unwrap the data keys of the values sealed before a
+// rotation. a nil Keyring doesn't encrypt
+type Keyring struct {
+	keys []*keyringKey
+
+	mu      sync.Mutex
+	current *dataKey
+	// opened caches the data keys unwrapped by Open, keyed by their envelope header
+	opened map[string]cipher.AEAD
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, ErrInvalidKey
+	}
+	return cipher.NewGCM(block)
+}
+
+func randomBytes(n int) ([]byte, error) {
+	p := make([]byte, n)
+	_, err := rand.Read(p)
+	return p, err
+}
+
+// newDataKey generates a data key wrapped by the first key, magic tells the values and the
+// snapshots apart
+func (k *Keyring) newDataKey(magic []byte) (*dataKey, error) {
+	key, err := randomBytes(dataKeySize)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := randomBytes(nonceSize)
+	if err != nil {
+		return nil, err
+	}
+
+	primary := k.keys[0]
+	header := make([]byte, 0, envelopeHeaderSize)
+	header = append(append(append(header, magic...), envelopeVersion), primary.id...)
+	aad := header
+	header = primary.aead.Seal(append(header, nonce...), nonce, key, aad)
+	return &dataKey{header: header, aead: aead}, nil
+}
+
+// openDataKey unwraps the data key of an envelope header
+func (k *Keyring) openDataKey(header []byte) (cipher.AEAD, error) {
+	if len(header) != envelopeHeaderSize || header[4] != envelopeVersion {
+		return nil, errors.Wrap(ErrDecrypt, "unsupported envelope")
+	}
+	var (
+		aad    = header[:5+keyIDSize]
+		id     = header[5 : 5+keyIDSize]
+		nonce  = header[5+keyIDSize : 5+keyIDSize+nonceSize]
+		sealed = header[5+keyIDSize+nonceSize:]
+	)
+	for _, key := range k.keys {
+		if !bytes.Equal(key.id, id) {
+			continue
+		}
+		dk, err := key.aead.Open(nil, nonce, sealed, aad)
+		if err != nil {
+			return nil, errors.Wrap(ErrDecrypt, "unwrap data key")
+		}
+		return newAEAD(dk)
+	}
+	return nil, ErrUnknownKey
+}
+
+func (k *Keyring) cachedDataKey(header []byte) (cipher.AEAD, error) {
+	k.mu.Lock()
+	aead, ok := k.opened[string(header)]
+	k.mu.Unlock()
+	if ok {
+		return aead, nil
+	}
+
+	aead, err := k.openDataKey(header)
+	if err != nil {
+		return nil, err
+	}
+	k.mu.Lock()
+	if len(k.opened) >= openedDataKeys {
+		clear(k.opened)
+	}
+	k.opened[string(header)] = aead
+	k.mu.Unlock()
+	return aead, nil
+}
+
+// Seal encrypts value with the current data key, a new one is generated every dataKeyUses values
+func (k *Keyring) Seal(value []byte) ([]byte, error) {
+	if k == nil {
+		return value, nil
+	}
+
+	k.mu.Lock()
+	if k.current == nil || k.current.uses >= dataKeyUses {
+		dk, err := k.newDataKey(valueMagic)
+		if err != nil {
+			k.mu.Unlock()
+			return nil, err
+		}
+		k.current = dk
+	}
+	dk := k.current
+	dk.uses++
+	k.mu.Unlock()
+
+	nonce, err := randomBytes(nonceSize)
+	if err != nil {
+		return nil, err
+	}
+	sealed := make([]byte, 0, len(value)+EnvelopeOverhead)
+	sealed = append(append(sealed, dk.header...), nonce...)
+	return dk.aead.Seal(sealed, nonce, value, dk.header), nil
+}
+
+// Open decrypts a value sealed by Seal. the caller tracks which values are sealed, a value that
+// wasn't sealed by the keyring fails to authenticate
+func (k *Keyring) Open(value []byte) ([]byte, error) {
+	if k == nil {
+		return nil, ErrValueEncrypted
+	}
+	if len(value) < EnvelopeOverhead || !bytes.HasPrefix(value, valueMagic) {
+		return nil, ErrDecrypt
+	}
+
+	header := value[:envelopeHeaderSize]
+	aead, err := k.cachedDataKey(header)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := aead.Open(nil, value[envelopeHeaderSize:envelopeHeaderSize+nonceSize], value[envelopeHeaderSize+nonceSize:], header)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plain, nil
+}
+
+func chunkNonce(seq uint64) []byte {
+	nonce := make([]byte, nonceSize)
+	binary.BigEndian.PutUint64(nonce[4:], seq)
+	return nonce
+}
+
+// snapshotSealer seals a snapshot in chunks, each snapshot has a data key of its own so that the
+// nonces are counted. the last chunk is flagged, a truncated snapshot fails to open
+type snapshotSealer struct {
+	dst  io.Writer
+	aead cipher.AEAD
+	buf  []byte
+	seq  uint64
+}
+
+func (s *snapshotSealer) writeChunk(p []byte, final bool) error {
+	header := uint32(len(p))
+	if final {
+		header |= snapshotFinal
+	}
+	chunk := binary.BigEndian.AppendUint32(make([]byte, 0, 4+len(p)+tagSize), header)
+	chunk = s.aead.Seal(chunk, chunkNonce(s.seq), p, chunk[:4])
+	s.seq++
+	_, err := s.dst.Write(chunk)
+	return err
+}
+
+func (s *snapshotSealer) Write(p []byte) (int, error) {
+	s.buf = append(s.buf, p...)
+	for len(s.buf) >= snapshotChunkSize {
+		if err := s.writeChunk(s.buf[:snapshotChunkSize], false); err != nil {
+			return 0, err
+		}
+		s.buf = s.buf[snapshotChunkSize:]
+	}
+	return len(p), nil
+}
+
+// Close seals the last chunk, it doesn't close dst
+func (s *snapshotSealer) Close() error {
+	return s.writeChunk(s.buf, true)
+}
+
+// SealSnapshot returns a writer sealing the snapshot written to it into dst, it must be closed
+// once the snapshot is written. a nil Keyring writes dst as is
+func (k *Keyring) SealSnapshot(dst io.Writer) (io.WriteCloser, error) {
+	if k == nil {
+		return nopWriteCloser{dst}, nil
+	}
+	dk, err := k.newDataKey(snapshotMagic)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = dst.Write(dk.header); err != nil {
+		return nil, err
+	}
+	return &snapshotSealer{dst: dst, aead: dk.aead}, nil
+}
+
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+type snapshotOpener struct {
+	src   io.Reader
+	aead  cipher.AEAD
+	buf   []byte
+	seq   uint64
+	final bool
+}
+
+func (s *snapshotOpener) readChunk() error {
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(s.src, header); err != nil {
+		return errors.Wrap(ErrDecrypt, "truncated snapshot")
+	}
+	size := binary.BigEndian.Uint32(header) &^ snapshotFinal
+	if size > snapshotChunkSize {
+		return errors.Wrap(ErrDecrypt, "invalid snapshot chunk")
+	}
+	chunk := make([]byte, int(size)+tagSize)
+	if _, err := io.ReadFull(s.src, chunk); err != nil {
+		return errors.Wrap(ErrDecrypt, "truncated snapshot")
+	}
+
+	plain, err := s.aead.Open(chunk[:0], chunkNonce(s.seq), chunk, header)
+	if err != nil {
+		return errors.Wrap(ErrDecrypt, "snapshot chunk")
+	}
+	s.seq++
+	s.buf, s.final = plain, binary.BigEndian.Uint32(header)&snapshotFinal != 0
+	return nil
+}
+
+func (s *snapshotOpener) Read(p []byte) (int, error) {
+	for len(s.buf) == 0 {
+		if s.final {
+			return 0, io.EOF
+		}
+		if err := s.readChunk(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, s.buf)
+	s.buf = s.buf[n:]
+	return n, nil
+}
+
+// OpenSnapshot returns the snapshot of src, the snapshots not sealed by SealSnapshot are
+// returned as is
+func (k *Keyring) OpenSnapshot(src io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(src)
+	if magic, err := br.Peek(len(snapshotMagic)); err != nil || !bytes.Equal(magic, snapshotMagic) {
+		return br, nil
+	}
+	if k == nil {
+		return nil, ErrSnapshotEncrypted
+	}
+
+	header := make([]byte, envelopeHeaderSize)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return nil, errors.Wrap(ErrDecrypt, "truncated snapshot")
+	}
+	aead, err := k.openDataKey(header)
+	if err != nil {
+		return nil, err
+	}
+	return &snapshotOpener{src: br, aead: aead}, nil
+}
+
+// Len returns the number of keys
+func (k *Keyring) Len() int {
+	if k == nil {
+		return 0
+	}
+	return len(k.keys)
+}
+
+// NewKeyring returns the keyring of the AES-128, AES-192 or AES-256 keys, the first one seals
+func NewKeyring(keys ...[]byte) (*Keyring, error) {
+	if len(keys) == 0 {
+		return nil, errors.Wrap(ErrInvalidKey, "empty keyring")
+	}
+
+	k := &Keyring{opened: make(map[string]cipher.AEAD)}
+	ids := make(map[string]struct{}, len(keys))
+	for i, key := range keys {
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, errors.Wrapf(err, "key %d", i+1)
+		}
+		sum := sha256.Sum256(key)
+		id := sum[:keyIDSize]
+		if _, ok := ids[string(id)]; ok {
+			return nil, errors.Wrapf(ErrInvalidKey, "key %d is a duplicate", i+1)
+		}
+		ids[string(id)] = struct{}{}
+		k.keys = append(k.keys, &keyringKey{id: id, aead: aead})
+	}
+	return k, nil
+}
+
+// LoadKeyring reads a keyring file, one base64 key per line with the sealing key first. blank
+// lines and the lines starting with # are skipped
+func LoadKeyring(path string) (*Keyring, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys [][]byte
+	for i, line := range strings.Split(string(b), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, dErr := base64.StdEncoding.DecodeString(line)
+		if dErr != nil {
+			return nil, errors.Wrapf(ErrInvalidKey, "line %d is not base64", i+1)
+		}
+		keys = append(keys, key)
+	}
+	return NewKeyring(keys...)
+}
@@ -0,0 +1,134 @@
+package store_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/RealFax/RedQueen/internal/rqd/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newKey(t *testing.T) []byte {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	return key
+}
+
+func TestKeyring_Seal(t *testing.T) {
+	var (
+		retired = newKey(t)
+		primary = newKey(t)
+		value   = []byte("customer-token")
+	)
+	k, err := store.NewKeyring(retired)
+	require.NoError(t, err)
+
+	sealed, err := k.Seal(value)
+	require.NoError(t, err)
+	assert.False(t, bytes.Contains(sealed, value))
+	assert.Equal(t, len(value)+store.EnvelopeOverhead, len(sealed))
+
+	plain, err := k.Open(sealed)
+	require.NoError(t, err)
+	assert.Equal(t, value, plain)
+
+	// the values that weren't sealed never open, even when they look sealed
+	_, err = k.Open(value)
+	assert.ErrorIs(t, err, store.ErrDecrypt)
+	_, err = k.Open(append([]byte("\x00RQE\x01"), bytes.Repeat(value, 8)...))
+	assert.Error(t, err)
+
+	// the retired keys still open the values sealed with them
+	rotated, err := store.NewKeyring(primary, retired)
+	require.NoError(t, err)
+	plain, err = rotated.Open(sealed)
+	require.NoError(t, err)
+	assert.Equal(t, value, plain)
+
+	resealed, err := rotated.Seal(value)
+	require.NoError(t, err)
+	_, err = k.Open(resealed)
+	assert.ErrorIs(t, err, store.ErrUnknownKey)
+
+	tampered := bytes.Clone(sealed)
+	tampered[len(tampered)-1] ^= 1
+	_, err = k.Open(tampered)
+	assert.ErrorIs(t, err, store.ErrDecrypt)
+
+	var nilKeyring *store.Keyring
+	_, err = nilKeyring.Open(sealed)
+	assert.ErrorIs(t, err, store.ErrValueEncrypted)
+}
+
+func TestNewKeyring(t *testing.T) {
+	key := newKey(t)
+	_, err := store.NewKeyring()
+	assert.ErrorIs(t, err, store.ErrInvalidKey)
+	_, err = store.NewKeyring(key[:20])
+	assert.ErrorIs(t, err, store.ErrInvalidKey)
+	_, err = store.NewKeyring(key, key)
+	assert.ErrorIs(t, err, store.ErrInvalidKey)
+}
+
+func TestLoadKeyring(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keyring")
+	require.NoError(t, os.WriteFile(path, []byte(
+		"# rotated on 2024-01-01\n"+
+			base64.StdEncoding.EncodeToString(newKey(t))+"\n\n"+
+			base64.StdEncoding.EncodeToString(newKey(t))+"\n",
+	), 0600))
+
+	k, err := store.LoadKeyring(path)
+	require.NoError(t, err)
+	assert.Equal(t, 2, k.Len())
+
+	require.NoError(t, os.WriteFile(path, []byte("not-a-key\n"), 0600))
+	_, err = store.LoadKeyring(path)
+	assert.ErrorIs(t, err, store.ErrInvalidKey)
+}
+
+func TestKeyring_SealSnapshot(t *testing.T) {
+	k, err := store.NewKeyring(newKey(t))
+	require.NoError(t, err)
+
+	snapshot := newSnapshot(t, map[string][]byte{
+		"0.dat": bytes.Repeat([]byte("customer-token"), 10000),
+	})
+
+	buf := &bytes.Buffer{}
+	w, err := k.SealSnapshot(buf)
+	require.NoError(t, err)
+	_, err = w.Write(snapshot)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	sealed := buf.Bytes()
+
+	r, err := k.OpenSnapshot(bytes.NewReader(sealed))
+	require.NoError(t, err)
+	manifest, err := store.VerifySnapshot(r, false)
+	require.NoError(t, err)
+	assert.Len(t, manifest.Files, 1)
+
+	// the snapshots written before the encryption was enabled
+	r, err = k.OpenSnapshot(bytes.NewReader(snapshot))
+	require.NoError(t, err)
+	_, err = store.VerifySnapshot(r, false)
+	require.NoError(t, err)
+
+	var nilKeyring *store.Keyring
+	_, err = nilKeyring.OpenSnapshot(bytes.NewReader(sealed))
+	assert.ErrorIs(t, err, store.ErrSnapshotEncrypted)
+
+	// a truncated snapshot is rejected
+	r, err = k.OpenSnapshot(bytes.NewReader(sealed[:len(sealed)-1]))
+	require.NoError(t, err)
+	_, err = io.ReadAll(r)
+	assert.ErrorIs(t, err, store.ErrDecrypt)
+}
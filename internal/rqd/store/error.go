@@ -5,4 +5,8 @@ import "errors"
 var (
 	ErrKeyAlreadyExists = errors.New("key already exists")
 	ErrKeyNotFound      = errors.New("key not found")
+	ErrRevisionNotFound = errors.New("revision not found")
+
+	ErrNamespaceNotFound = errors.New("namespace not found")
+	ErrReservedNamespace = errors.New("namespace is reserved")
 )
@@ -1,13 +1,10 @@
 package nuts
 
 import (
-	"bytes"
 	"context"
 	"github.com/RealFax/RedQueen/internal/rqd/store"
 	"github.com/nutsdb/nutsdb"
 	"github.com/pkg/errors"
-	"io"
-	"os"
 	"sync/atomic"
 )
 
@@ -22,6 +19,9 @@ func (s *DB) swap(namespace string) (*DB, error) {
 	return &DB{
 		state:        s.state,
 		db:           s.db,
+		history:      s.history,
+		keyring:      s.keyring,
+		usage:        s.usage,
 		watcher:      s.watcher,
 		watcherChild: s.watcher.UseTarget(namespace),
 		namespace:    namespace,
@@ -86,8 +86,8 @@ func (s *DB) Get(key []byte) (*store.Value, error) {
 		val.Timestamp = entry.Meta.Timestamp
 		val.TTL = ReadTTL(entry.Meta)
 		val.Key = entry.Key
-		val.Data = entry.Value
-		return nil
+		val.Data, err = s.openValue(entry.Value)
+		return err
 	})
 }
 
@@ -109,11 +109,15 @@ func (s *DB) PrefixSearchScan(prefix []byte, reg string, offset, limit int) ([]*
 		}
 
 		for _, entry := range entries {
+			data, oErr := s.openValue(entry.Value)
+			if oErr != nil {
+				return oErr
+			}
 			val = append(val, &store.Value{
 				Timestamp: entry.Meta.Timestamp,
 				TTL:       ReadTTL(entry.Meta),
 				Key:       entry.Key,
-				Data:      entry.Value,
+				Data:      data,
 			})
 		}
 		return nil
@@ -125,14 +129,49 @@ func (s *DB) PrefixScan(prefix []byte, offset, limit int) ([]*store.Value, error
 }
 
 func (s *DB) SetWithTTL(key, value []byte, ttl uint32) error {
-	return s.Transaction(true, func(tx *nutsdb.Tx) error {
-		if err := tx.Put(s.namespace, key, value, ttl); err != nil {
+	sealed, err := s.keyring.Seal(value)
+	if err != nil {
+		return err
+	}
+
+	s.usage.mu.Lock()
+	defer s.usage.mu.Unlock()
+	var (
+		u     *usage
+		prev  uint64
+		found bool
+		now   = s.usage.now()
+		size  = uint64(len(key) + len(value))
+	)
+	if err = s.Transaction(true, func(tx *nutsdb.Tx) (err error) {
+		if u, err = s.trackUsage(tx, key); err != nil {
+			return err
+		}
+		if u != nil {
+			if prev, found, err = s.keyUsage(tx, s.namespace, key, now); err != nil {
+				return err
+			}
+			if err = s.putUsage(tx, u, key, size, ttl, false, now); err != nil {
+				return err
+			}
+		}
+		if err = tx.Put(s.namespace, key, sealed, ttl); err != nil {
+			return err
+		}
+		if err = s.record(tx, key, sealed, ttl, false); err != nil {
 			return err
 		}
 		// notify watcher key-value update
 		s.watcherChild.Update(key, value, ttl)
 		return nil
-	})
+	}); err != nil {
+		return err
+	}
+	if u != nil {
+		u.expired = u.expired[:0]
+		u.replace(string(key), prev, found, size, ttl, now)
+	}
+	return nil
 }
 
 func (s *DB) Set(key, value []byte) error {
@@ -140,20 +179,56 @@ func (s *DB) Set(key, value []byte) error {
 }
 
 func (s *DB) TrySetWithTTL(key, value []byte, ttl uint32) error {
-	return s.Transaction(true, func(tx *nutsdb.Tx) error {
+	sealed, err := s.keyring.Seal(value)
+	if err != nil {
+		return err
+	}
+
+	s.usage.mu.Lock()
+	defer s.usage.mu.Unlock()
+	var (
+		u     *usage
+		prev  uint64
+		found bool
+		now   = s.usage.now()
+		size  = uint64(len(key) + len(value))
+	)
+	if err = s.Transaction(true, func(tx *nutsdb.Tx) error {
 		_, err := tx.Get(s.namespace, key)
 		if err == nil {
 			return store.ErrKeyAlreadyExists
 		}
 
-		if err = tx.Put(s.namespace, key, value, ttl); err != nil {
+		if u, err = s.trackUsage(tx, key); err != nil {
+			return err
+		}
+		if u != nil {
+			// nuts may have expired a key the usage still counts
+			if prev, found, err = s.keyUsage(tx, s.namespace, key, now); err != nil {
+				return err
+			}
+			if err = s.putUsage(tx, u, key, size, ttl, false, now); err != nil {
+				return err
+			}
+		}
+		if err = tx.Put(s.namespace, key, sealed, ttl); err != nil {
+			return err
+		}
+		if err = s.record(tx, key, sealed, ttl, false); err != nil {
 			return err
 		}
 
 		// notify watcher key-value update
 		s.watcherChild.Update(key, value, ttl)
 		return nil
-	})
+	}); err != nil {
+		return err
+	}
+	if u != nil {
+		u.expired = u.expired[:0]
+		u.replace(string(key), prev, found, size, ttl, now)
+	}
+	return nil
 }
 
 func (s *DB) TrySet(key, value []byte) error {
@@ -161,13 +236,42 @@ func (s *DB) TrySet(key, value []byte) error {
 }
 
 func (s *DB) Del(key []byte) error {
-	return s.Transaction(true, func(tx *nutsdb.Tx) error {
-		if err := tx.Delete(s.namespace, key); err != nil {
+	s.usage.mu.Lock()
+	defer s.usage.mu.Unlock()
+	var (
+		u     *usage
+		prev  uint64
+		found bool
+		now   = s.usage.now()
+	)
+	if err := s.Transaction(true, func(tx *nutsdb.Tx) (err error) {
+		if u, err = s.trackUsage(tx, key); err != nil {
+			return err
+		}
+		if u != nil {
+			if prev, found, err = s.keyUsage(tx, s.namespace, key, now); err != nil {
+				return err
+			}
+			if err = s.putUsage(tx, u, key, 0, 0, true, now); err != nil {
+				return err
+			}
+		}
+		if err = tx.Delete(s.namespace, key); err != nil {
+			return err
+		}
+		if err = s.record(tx, key, nil, 0, true); err != nil {
 			return err
 		}
 		s.watcherChild.Update(key, nil, 0)
 		return nil
-	})
+	}); err != nil {
+		return err
+	}
+	if u != nil {
+		u.expired = u.expired[:0]
+		u.remove(string(key), prev, found)
+	}
+	return nil
 }
 
 func (s *DB) Watch(key []byte) (store.Watcher, error) {
@@ -197,6 +301,12 @@ func (s *DB) Swap(namespace string) (store.Actions, error) {
 	if err != nil {
 		return nil, err
 	}
+
+	// the bucket of nuts is created lazily by its first write
+	exist, err := n.bucketExist(namespace)
+	if err != nil || exist {
+		return n, err
+	}
 	return n, n.SetWithTTL(KeyInitBucket, nil, 4)
 }
 
@@ -209,82 +319,33 @@ func (s *DB) Close() error {
 	return db.Close()
 }
 
-func (s *DB) Snapshot() (io.Reader, error) {
-	// get db session first
-	db, err := s.DB()
-	if err != nil {
-		return nil, errors.Wrap(err, "fail snapshot, get db error")
-	}
-
-	// break db
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-	if err = s.Break(ctx); err != nil {
-		return nil, errors.Wrap(err, "fail snapshot, break error")
-	}
-
-	_ = db.Merge()
-
-	buf := &bytes.Buffer{}
-	return buf, db.View(func(tx *nutsdb.Tx) error {
-		return BackupWriter(s.dataDir, buf)
-	})
-}
-
 func (s *DB) Break(ctx context.Context) error {
-	if atomic.LoadUint32(s.state) == StateBreak {
-		return ErrStateBreak
+	resume, err := s.pause()
+	if err != nil {
+		return err
 	}
 
-	// switch to state: break
-	s.mu.Lock()
-	atomic.StoreUint32(s.state, StateBreak)
-
 	go func() {
-		select {
-		case <-ctx.Done():
-			s.mu.Unlock()
-			atomic.StoreUint32(s.state, StateOk)
-		}
+		<-ctx.Done()
+		resume()
 	}()
 
 	return nil
 }
 
-func (s *DB) Restore(src io.Reader) (err error) {
-	ctx, cancel := context.WithCancel(context.Background())
-
-	if err = s.Break(ctx); err != nil {
-		goto CancelBreak
-	}
-
-	// close nuts db
-	_ = s.db.Load().Close()
-
-	// clear new db files
-	if err = os.RemoveAll(s.dataDir); err != nil {
-		err = errors.Wrap(err, "clean files error")
-		goto CancelBreak
-	}
-
-	// restore old db files
-	if err = BackupReader(s.dataDir, src); err != nil {
-		goto CancelBreak
-	}
-
-	// reopen nuts db
-	{
-		newDB, oErr := nutsdb.Open(nutsdb.DefaultOptions, s.options...)
-		if oErr != nil {
-			err = oErr
-			goto CancelBreak
-		}
-		s.db.Store(newDB)
+// pause switches to state: break until resume is called, unlike Break the state is ok again once
+// resume returns
+func (s *DB) pause() (resume func(), err error) {
+	if atomic.LoadUint32(s.state) == StateBreak {
+		return nil, ErrStateBreak
 	}
 
-	goto CancelBreak
+	s.mu.Lock()
+	atomic.StoreUint32(s.state, StateBreak)
 
-CancelBreak:
-	cancel()
-	return
+	return func() {
+		// back to state: ok before unlocking, writers waiting on the lock must not see break
+		atomic.StoreUint32(s.state, StateOk)
+		s.mu.Unlock()
+	}, nil
 }
@@ -1,11 +1,15 @@
 package nuts_test
 
 import (
+	"bytes"
 	"context"
+	"crypto/rand"
 	"github.com/RealFax/RedQueen/internal/rqd/store"
 	"github.com/RealFax/RedQueen/internal/rqd/store/nuts"
 	"github.com/stretchr/testify/assert"
+	"io/fs"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 )
@@ -196,13 +200,14 @@ func TestDB_Snapshot(t *testing.T) {
 		assert.NoError(t, db.Set(node.Key, node.Value))
 	}
 
-	reader, err := db.Snapshot()
+	reader, err := db.Snapshot(store.SnapshotMeta{})
 	assert.NoError(t, err)
 	assert.NotNil(t, reader)
 
 	time.Sleep(100 * time.Millisecond) // wait db state change
 
-	assert.NoError(t, db.Restore(reader))
+	_, err = db.Restore(reader)
+	assert.NoError(t, err)
 
 	for _, node := range pairMatrix {
 		value, err := db.Get(node.Key)
@@ -317,3 +322,129 @@ func TestDB_Del(t *testing.T) {
 	assert.NoError(t, db.Set(pair2.Key, pair2.Value))
 	assert.NoError(t, db.Del(pair2.Key))
 }
+
+func newEncryptedDB(t *testing.T, dir string, keyring *store.Keyring) store.Store {
+	edb, err := nuts.New(nuts.Config{
+		NodeNum: 1,
+		DataDir: dir,
+		RWMode:  nuts.FileIO,
+		History: nuts.History{MaxVersions: 2},
+		Keyring: keyring,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return edb
+}
+
+func newKeyring(t *testing.T) *store.Keyring {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	keyring, err := store.NewKeyring(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keyring
+}
+
+func TestDB_Encryption(t *testing.T) {
+	var (
+		dir    = t.TempDir()
+		secret = []byte("customer-token-0123456789")
+		edb    = newEncryptedDB(t, dir, newKeyring(t))
+	)
+
+	watcher := edb.WatchPrefix([]byte("token/"))
+	defer watcher.Close()
+
+	assert.NoError(t, edb.Set([]byte("token/1"), secret))
+	assert.NoError(t, edb.TrySet([]byte("token/2"), secret))
+
+	// the watchers are notified with the plaintext
+	notify := <-watcher.Notify()
+	assert.Equal(t, secret, *notify.Value)
+
+	value, err := edb.Get([]byte("token/1"))
+	assert.NoError(t, err)
+	assert.Equal(t, secret, value.Data)
+
+	values, err := edb.PrefixScan([]byte("token/"), 0, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, values, 2) {
+		assert.Equal(t, secret, values[0].Data)
+		assert.Equal(t, secret, values[1].Data)
+	}
+
+	versions, err := edb.History([]byte("token/1"), 0)
+	assert.NoError(t, err)
+	if assert.Len(t, versions, 1) {
+		assert.Equal(t, secret, versions[0].Data)
+	}
+
+	// the stats count the plaintext
+	stats, err := edb.NamespaceStats(store.DefaultNamespace)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2*(len("token/1")+len(secret))), stats.Bytes)
+
+	assert.NoError(t, edb.Close())
+
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		assert.False(t, bytes.Contains(b, secret), "plaintext in %s", path)
+		return nil
+	})
+	assert.NoError(t, err)
+
+	// the store can't be opened without the keyring
+	_, err = nuts.New(nuts.Config{NodeNum: 1, DataDir: dir, RWMode: nuts.FileIO})
+	assert.ErrorIs(t, err, store.ErrValueEncrypted)
+}
+
+func TestDB_EnableEncryption(t *testing.T) {
+	var (
+		dir     = t.TempDir()
+		keyring = newKeyring(t)
+		// user data that looks like a sealed value
+		forged = append([]byte("\x00RQE\x01"), bytes.Repeat([]byte("customer-token"), 8)...)
+	)
+
+	db := newEncryptedDB(t, dir, nil)
+	assert.NoError(t, db.Set([]byte("token/1"), forged))
+	assert.NoError(t, db.SetWithTTL([]byte("token/2"), []byte("expiring"), 60))
+	assert.NoError(t, db.Close())
+
+	// the plaintext values are sealed once the encryption is enabled
+	for i := 0; i < 2; i++ {
+		edb := newEncryptedDB(t, dir, keyring)
+		value, err := edb.Get([]byte("token/1"))
+		assert.NoError(t, err)
+		assert.Equal(t, forged, value.Data)
+
+		value, err = edb.Get([]byte("token/2"))
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("expiring"), value.Data)
+		assert.NotZero(t, value.TTL)
+
+		versions, err := edb.History([]byte("token/1"), 0)
+		assert.NoError(t, err)
+		if assert.Len(t, versions, 1) {
+			assert.Equal(t, forged, versions[0].Data)
+		}
+
+		stats, err := edb.NamespaceStats(store.DefaultNamespace)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(len("token/1")+len(forged)+len("token/2")+len("expiring")), stats.Bytes)
+		assert.NoError(t, edb.Close())
+	}
+
+	_, err := nuts.New(nuts.Config{NodeNum: 1, DataDir: dir, RWMode: nuts.FileIO})
+	assert.ErrorIs(t, err, store.ErrValueEncrypted)
+}
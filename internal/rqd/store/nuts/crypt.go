@@ -0,0 +1,163 @@
+package nuts
+
+import (
+	"encoding/binary"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/RealFax/RedQueen/internal/rqd/store"
+	"github.com/nutsdb/nutsdb"
+	"github.com/pkg/errors"
+)
+
+const (
+	// EncryptionBucket marks a store whose values are all sealed, the values of a store without
+	// the mark are never opened
+	EncryptionBucket = "_Encryption"
+
+	// sealBatch bounds the values sealed by a transaction when the encryption is enabled
+	sealBatch = 1024
+)
+
+var (
+	KeySealed = []byte("sealed")
+)
+
+func init() {
+	store.ReserveNamespace(EncryptionBucket)
+}
+
+// openValue decrypts a value read from the store, the values are only sealed when a keyring is configured
+func (s *DB) openValue(value []byte) ([]byte, error) {
+	if s.keyring == nil {
+		return value, nil
+	}
+	return s.keyring.Open(value)
+}
+
+// isSealed reports whether the values of db were sealed
+func isSealed(db *nutsdb.DB) (bool, error) {
+	var sealed bool
+	err := db.View(func(tx *nutsdb.Tx) error {
+		_, err := tx.Get(EncryptionBucket, KeySealed)
+		switch {
+		case err == nil:
+			sealed = true
+		case isNotFound(err):
+		default:
+			return err
+		}
+		return nil
+	})
+	return sealed, err
+}
+
+// sealStore keeps the values of db sealed exactly when a keyring is configured. the plaintext
+// values of a store the encryption is enabled on are sealed once, then the store is marked
+func (s *DB) sealStore(db *nutsdb.DB) error {
+	sealed, err := isSealed(db)
+	if err != nil {
+		return errors.Wrap(err, "read encryption mark error")
+	}
+	switch {
+	case sealed && s.keyring == nil:
+		return store.ErrValueEncrypted
+	case sealed || s.keyring == nil:
+		return nil
+	}
+
+	var buckets []string
+	if err = db.View(func(tx *nutsdb.Tx) error {
+		return tx.IterateBuckets(nutsdb.DataStructureBTree, "*", func(bucket string) bool {
+			// the other internal buckets hold no values
+			if !internalBucket(bucket) || strings.HasPrefix(bucket, HistoryBucketPrefix) {
+				buckets = append(buckets, bucket)
+			}
+			return true
+		})
+	}); err != nil && !isNotFound(err) {
+		return errors.Wrap(err, "list buckets error")
+	}
+
+	for _, bucket := range buckets {
+		if err = s.sealBucket(db, bucket); err != nil {
+			return errors.Wrapf(err, "seal bucket %s error", bucket)
+		}
+	}
+
+	return db.Update(func(tx *nutsdb.Tx) error {
+		return tx.Put(EncryptionBucket, KeySealed, nil, nutsdb.Persistent)
+	})
+}
+
+// sealBucket seals the values of bucket in batches. the values a crash left sealed open with
+// the keyring and are skipped, the mark is only written once every bucket is sealed
+func (s *DB) sealBucket(db *nutsdb.DB, bucket string) error {
+	var entries []*nutsdb.Entry
+	if err := db.View(func(tx *nutsdb.Tx) (err error) {
+		entries, err = tx.GetAll(bucket)
+		return
+	}); err != nil {
+		if isNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	history := strings.HasPrefix(bucket, HistoryBucketPrefix)
+	for len(entries) != 0 {
+		batch := entries[:min(sealBatch, len(entries))]
+		entries = entries[len(batch):]
+		if err := db.Update(func(tx *nutsdb.Tx) error {
+			for _, entry := range batch {
+				value, ttl, ok, err := s.sealEntry(entry, history)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					continue
+				}
+				if err = tx.Put(bucket, entry.Key, value, ttl); err != nil {
+					return err
+				}
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sealEntry returns the sealed value of entry and its remaining ttl, ok is false when the
+// entry is kept as is. the versions of a history bucket only seal their data
+func (s *DB) sealEntry(entry *nutsdb.Entry, history bool) (value []byte, ttl uint32, ok bool, err error) {
+	ttl = nutsdb.Persistent
+	if entry.Meta.TTL != nutsdb.Persistent {
+		remaining := math.Ceil(time.Until(entryDeadline(entry.Meta)).Seconds())
+		if remaining <= 0 {
+			return nil, 0, false, nil
+		}
+		ttl = uint32(remaining)
+	}
+
+	data := entry.Value
+	if history {
+		if len(entry.Value) < 5 || entry.Value[0]&historyFlagDeleted != 0 {
+			return nil, 0, false, nil
+		}
+		data = entry.Value[5:]
+	}
+	if _, oErr := s.keyring.Open(data); oErr == nil {
+		return nil, 0, false, nil
+	}
+
+	if value, err = s.keyring.Seal(data); err != nil {
+		return nil, 0, false, err
+	}
+	if history {
+		value = encodeHistoryValue(value, binary.LittleEndian.Uint32(entry.Value[1:5]), false)
+	}
+	return value, ttl, true, nil
+}
@@ -0,0 +1,207 @@
+package nuts
+
+import (
+	"encoding/binary"
+	"github.com/RealFax/RedQueen/internal/rqd/store"
+	"github.com/nutsdb/nutsdb"
+	"github.com/pkg/errors"
+)
+
+const (
+	// HistoryBucketPrefix is the bucket prefix of the versions kept for a namespace
+	HistoryBucketPrefix = "_History:"
+	// RevisionBucket holds the store wide revision counter
+	RevisionBucket = "_Revision"
+
+	historyFlagDeleted byte = 1
+)
+
+var (
+	KeyRevision = []byte("revision")
+)
+
+func init() {
+	store.ReserveNamespace(RevisionBucket)
+	store.ReserveNamespacePrefix(HistoryBucketPrefix)
+}
+
+func historyBucket(namespace string) string {
+	return HistoryBucketPrefix + namespace
+}
+
+// historyPrefix returns the length-prefixed key, so that the versions of "a" never
+// share a prefix with the versions of "ab"
+func historyPrefix(key []byte) []byte {
+	p := binary.AppendUvarint(make([]byte, 0, binary.MaxVarintLen64+len(key)+8), uint64(len(key)))
+	return append(p, key...)
+}
+
+func historyKey(key []byte, revision uint64) []byte {
+	return binary.BigEndian.AppendUint64(historyPrefix(key), revision)
+}
+
+func encodeHistoryValue(value []byte, ttl uint32, deleted bool) []byte {
+	p := make([]byte, 5, 5+len(value))
+	if deleted {
+		p[0] |= historyFlagDeleted
+	}
+	binary.LittleEndian.PutUint32(p[1:5], ttl)
+	return append(p, value...)
+}
+
+func decodeHistoryEntry(prefixLen int, entry *nutsdb.Entry) (*store.Value, error) {
+	if len(entry.Key) != prefixLen+8 || len(entry.Value) < 5 {
+		return nil, errors.New("malformed history entry")
+	}
+	val := &store.Value{
+		Timestamp: entry.Meta.Timestamp,
+		Revision:  binary.BigEndian.Uint64(entry.Key[prefixLen:]),
+		Key:       entry.Key[:prefixLen],
+		TTL:       binary.LittleEndian.Uint32(entry.Value[1:5]),
+		Deleted:   entry.Value[0]&historyFlagDeleted != 0,
+	}
+	if !val.Deleted {
+		val.Data = entry.Value[5:]
+	}
+	return val, nil
+}
+
+// maxVersions returns the number of versions kept for the current namespace
+func (s *DB) maxVersions() int {
+	if internalBucket(s.namespace) {
+		return 0
+	}
+	if n, ok := s.history.Namespaces[s.namespace]; ok {
+		return int(n)
+	}
+	if store.IsReservedNamespace(s.namespace) {
+		// reserved namespaces are only versioned when configured explicitly
+		return 0
+	}
+	return int(s.history.MaxVersions)
+}
+
+func (s *DB) nextRevision(tx *nutsdb.Tx) (uint64, error) {
+	var revision uint64
+	entry, err := tx.Get(RevisionBucket, KeyRevision)
+	switch {
+	case err == nil:
+		revision = binary.BigEndian.Uint64(entry.Value)
+	case errors.Is(err, nutsdb.ErrNotFoundBucket), errors.Is(err, nutsdb.ErrKeyNotFound):
+	default:
+		return 0, err
+	}
+	revision++
+	return revision, tx.Put(RevisionBucket, KeyRevision, binary.BigEndian.AppendUint64(nil, revision), nutsdb.Persistent)
+}
+
+// open decrypts the data of the versions, the versions are sealed together with the values
+func (s *DB) open(versions []*store.Value) error {
+	for _, val := range versions {
+		if val.Deleted {
+			continue
+		}
+		data, err := s.openValue(val.Data)
+		if err != nil {
+			return err
+		}
+		val.Data = data
+	}
+	return nil
+}
+
+// versions returns the kept versions of key in ascending order of revision
+func (s *DB) versions(tx *nutsdb.Tx, key []byte) ([]*store.Value, error) {
+	prefix := historyPrefix(key)
+	entries, err := tx.PrefixScan(historyBucket(s.namespace), prefix, 0, nutsdb.ScanNoLimit)
+	if err != nil {
+		if errors.Is(err, nutsdb.ErrPrefixScan) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	values := make([]*store.Value, 0, len(entries))
+	for _, entry := range entries {
+		// skip keys that only share the length-prefixed key
+		if len(entry.Key) != len(prefix)+8 {
+			continue
+		}
+		val, dErr := decodeHistoryEntry(len(prefix), entry)
+		if dErr != nil {
+			return nil, dErr
+		}
+		values = append(values, val)
+	}
+	return values, nil
+}
+
+// record appends a version of key and evicts the versions beyond the namespace limit
+func (s *DB) record(tx *nutsdb.Tx, key, value []byte, ttl uint32, deleted bool) error {
+	limit := s.maxVersions()
+	if limit == 0 || string(key) == string(KeyInitBucket) {
+		return nil
+	}
+
+	revision, err := s.nextRevision(tx)
+	if err != nil {
+		return errors.Wrap(err, "next revision")
+	}
+
+	versions, err := s.versions(tx, key)
+	if err != nil {
+		return err
+	}
+
+	bucket := historyBucket(s.namespace)
+	// the new version is not visible to this transaction, keep room for it
+	for i := 0; i < len(versions)-limit+1; i++ {
+		if err = tx.Delete(bucket, historyKey(key, versions[i].Revision)); err != nil {
+			return err
+		}
+	}
+
+	return tx.Put(bucket, historyKey(key, revision), encodeHistoryValue(value, ttl, deleted), nutsdb.Persistent)
+}
+
+func (s *DB) GetRevision(key []byte, revision uint64) (*store.Value, error) {
+	var val *store.Value
+	err := s.Transaction(false, func(tx *nutsdb.Tx) error {
+		versions, err := s.versions(tx, key)
+		if err != nil {
+			return err
+		}
+		for i := len(versions) - 1; i >= 0; i-- {
+			if versions[i].Revision > revision {
+				continue
+			}
+			// the versions are persistent, the ttl they were written with expires them here
+			if versions[i].Deleted || nutsdb.IsExpired(versions[i].TTL, versions[i].Timestamp) {
+				return store.ErrKeyNotFound
+			}
+			val = versions[i]
+			return s.open(versions[i : i+1])
+		}
+		return store.ErrRevisionNotFound
+	})
+	return val, err
+}
+
+func (s *DB) History(key []byte, limit int) ([]*store.Value, error) {
+	var values []*store.Value
+	err := s.Transaction(false, func(tx *nutsdb.Tx) error {
+		versions, err := s.versions(tx, key)
+		if err != nil {
+			return err
+		}
+		if limit <= 0 || limit > len(versions) {
+			limit = len(versions)
+		}
+		values = make([]*store.Value, 0, limit)
+		for i := len(versions) - 1; i >= len(versions)-limit; i-- {
+			values = append(values, versions[i])
+		}
+		return s.open(values)
+	})
+	return values, err
+}
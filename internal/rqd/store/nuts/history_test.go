@@ -0,0 +1,116 @@
+package nuts_test
+
+import (
+	"github.com/RealFax/RedQueen/internal/rqd/store"
+	"github.com/RealFax/RedQueen/internal/rqd/store/nuts"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+	"time"
+)
+
+func newTestDB(t *testing.T, history nuts.History) store.Store {
+	dir, err := os.MkdirTemp("", "nuts-db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	hdb, err := nuts.New(nuts.Config{
+		NodeNum: 1,
+		DataDir: dir,
+		RWMode:  nuts.FileIO,
+		History: history,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hdb
+}
+
+func TestDB_History(t *testing.T) {
+	hdb := newTestDB(t, nuts.History{MaxVersions: 3})
+
+	for _, pair := range pairMatrix[:5] {
+		assert.NoError(t, hdb.Set(pair1.Key, pair.Value))
+	}
+	assert.NoError(t, hdb.Del(pair1.Key))
+
+	versions, err := hdb.History(pair1.Key, 0)
+	assert.NoError(t, err)
+	assert.Len(t, versions, 3)
+	assert.True(t, versions[0].Deleted)
+	assert.Equal(t, pairMatrix[4].Value, versions[1].Data)
+	assert.Equal(t, pairMatrix[3].Value, versions[2].Data)
+	assert.Greater(t, versions[0].Revision, versions[1].Revision)
+
+	versions, err = hdb.History(pair1.Key, 1)
+	assert.NoError(t, err)
+	assert.Len(t, versions, 1)
+
+	// keys sharing a prefix are kept apart
+	assert.NoError(t, hdb.Set(append(pair1.Key, 'x'), pair2.Value))
+	versions, err = hdb.History(pair1.Key, 0)
+	assert.NoError(t, err)
+	assert.Len(t, versions, 3)
+}
+
+func TestDB_GetRevision(t *testing.T) {
+	hdb := newTestDB(t, nuts.History{MaxVersions: 8})
+
+	assert.NoError(t, hdb.Set(pair1.Key, pairMatrix[0].Value))
+	assert.NoError(t, hdb.Set(pair1.Key, pairMatrix[1].Value))
+
+	versions, err := hdb.History(pair1.Key, 0)
+	assert.NoError(t, err)
+	assert.Len(t, versions, 2)
+
+	value, err := hdb.GetRevision(pair1.Key, versions[1].Revision)
+	assert.NoError(t, err)
+	assert.Equal(t, pairMatrix[0].Value, value.Data)
+
+	value, err = hdb.GetRevision(pair1.Key, versions[0].Revision+10)
+	assert.NoError(t, err)
+	assert.Equal(t, pairMatrix[1].Value, value.Data)
+
+	_, err = hdb.GetRevision(pair1.Key, 0)
+	assert.ErrorIs(t, err, store.ErrRevisionNotFound)
+
+	assert.NoError(t, hdb.Del(pair1.Key))
+	_, err = hdb.GetRevision(pair1.Key, versions[0].Revision+10)
+	assert.ErrorIs(t, err, store.ErrKeyNotFound)
+}
+
+func TestDB_GetRevisionExpired(t *testing.T) {
+	hdb := newTestDB(t, nuts.History{MaxVersions: 8})
+
+	assert.NoError(t, hdb.SetWithTTL(pair1.Key, pairMatrix[0].Value, 1))
+	versions, err := hdb.History(pair1.Key, 0)
+	assert.NoError(t, err)
+	assert.Len(t, versions, 1)
+
+	value, err := hdb.GetRevision(pair1.Key, versions[0].Revision)
+	assert.NoError(t, err)
+	assert.Equal(t, pairMatrix[0].Value, value.Data)
+
+	// the version expires with the ttl of the key it was written with
+	time.Sleep(1100 * time.Millisecond)
+	_, err = hdb.GetRevision(pair1.Key, versions[0].Revision)
+	assert.ErrorIs(t, err, store.ErrKeyNotFound)
+}
+
+func TestDB_HistoryNamespace(t *testing.T) {
+	hdb := newTestDB(t, nuts.History{Namespaces: map[string]uint32{"bucket1": 2}})
+
+	assert.NoError(t, hdb.Set(pair1.Key, pair1.Value))
+	versions, err := hdb.History(pair1.Key, 0)
+	assert.NoError(t, err)
+	assert.Empty(t, versions)
+
+	bucket1, err := hdb.Swap("bucket1")
+	assert.NoError(t, err)
+	assert.NoError(t, bucket1.Set(pair1.Key, pair1.Value))
+	versions, err = bucket1.History(pair1.Key, 0)
+	assert.NoError(t, err)
+	assert.Len(t, versions, 1)
+}
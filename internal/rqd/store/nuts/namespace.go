@@ -0,0 +1,102 @@
+package nuts
+
+import (
+	"github.com/RealFax/RedQueen/internal/rqd/store"
+	"github.com/nutsdb/nutsdb"
+	"github.com/pkg/errors"
+	"sort"
+	"strings"
+)
+
+// internalBucket reports whether the bucket is maintained by the nuts store itself
+func internalBucket(bucket string) bool {
+	return bucket == RevisionBucket || bucket == EncryptionBucket ||
+		strings.HasPrefix(bucket, HistoryBucketPrefix) || strings.HasPrefix(bucket, UsageBucketPrefix)
+}
+
+func (s *DB) bucketExist(namespace string) (bool, error) {
+	var exist bool
+	err := s.Transaction(false, func(tx *nutsdb.Tx) (err error) {
+		exist, err = tx.ExistBucket(nutsdb.DataStructureBTree, namespace)
+		return
+	})
+	return exist, err
+}
+
+func (s *DB) Namespaces() ([]string, error) {
+	var namespaces []string
+	err := s.Transaction(false, func(tx *nutsdb.Tx) error {
+		return tx.IterateBuckets(nutsdb.DataStructureBTree, "*", func(bucket string) bool {
+			if !internalBucket(bucket) {
+				namespaces = append(namespaces, bucket)
+			}
+			return true
+		})
+	})
+	sort.Strings(namespaces)
+	return namespaces, err
+}
+
+func (s *DB) CreateNamespace(namespace string) error {
+	if internalBucket(namespace) {
+		return errors.New("namespace is used by the store")
+	}
+	n, err := s.swap(namespace)
+	if err != nil {
+		return err
+	}
+	return n.SetWithTTL(KeyInitBucket, nil, nutsdb.Persistent)
+}
+
+func (s *DB) DropNamespace(namespace string) error {
+	if internalBucket(namespace) {
+		return errors.New("namespace is used by the store")
+	}
+	s.usage.mu.Lock()
+	defer s.usage.mu.Unlock()
+	delete(s.usage.namespaces, namespace)
+	return s.Transaction(true, func(tx *nutsdb.Tx) error {
+		if err := tx.DeleteBucket(nutsdb.DataStructureBTree, namespace); err != nil {
+			if errors.Is(err, nutsdb.ErrBucketNotFound) {
+				return store.ErrNamespaceNotFound
+			}
+			return err
+		}
+		// drop the kept versions and the usage rows together with the namespace
+		for _, bucket := range []string{historyBucket(namespace), usageBucket(namespace)} {
+			err := tx.DeleteBucket(nutsdb.DataStructureBTree, bucket)
+			if err != nil && !errors.Is(err, nutsdb.ErrBucketNotFound) {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *DB) NamespaceStats(namespace string) (*store.NamespaceStats, error) {
+	s.usage.mu.Lock()
+	defer s.usage.mu.Unlock()
+	now := s.usage.now()
+	if u, ok := s.usage.namespaces[namespace]; ok {
+		u.expire(now)
+		return u.stats(namespace), nil
+	}
+
+	// the namespace is only scanned once, the writes keep its usage since
+	var u *usage
+	if err := s.Transaction(false, func(tx *nutsdb.Tx) error {
+		exist, err := tx.ExistBucket(nutsdb.DataStructureBTree, namespace)
+		if err != nil {
+			return err
+		}
+		if !exist {
+			return store.ErrNamespaceNotFound
+		}
+		u, err = s.countUsage(tx, namespace, now)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	s.usage.namespaces[namespace] = u
+	return u.stats(namespace), nil
+}
@@ -0,0 +1,159 @@
+package nuts_test
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/RealFax/RedQueen/internal/rqd/store"
+	"github.com/RealFax/RedQueen/internal/rqd/store/nuts"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestDB_Namespaces(t *testing.T) {
+	ndb := newTestDB(t, nuts.History{MaxVersions: 2})
+
+	assert.NoError(t, ndb.CreateNamespace("bucket1"))
+	bucket2, err := ndb.Swap("bucket2")
+	assert.NoError(t, err)
+	assert.NoError(t, bucket2.Set(pair1.Key, pair1.Value))
+
+	namespaces, err := ndb.Namespaces()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"bucket1", "bucket2"}, namespaces)
+}
+
+func TestDB_NamespaceStats(t *testing.T) {
+	ndb := newTestDB(t, nuts.History{})
+
+	assert.NoError(t, ndb.CreateNamespace("bucket1"))
+	stats, err := ndb.NamespaceStats("bucket1")
+	assert.NoError(t, err)
+	assert.Zero(t, stats.Keys)
+
+	bucket1, err := ndb.Swap("bucket1")
+	assert.NoError(t, err)
+	assert.NoError(t, bucket1.Set(pair1.Key, pair1.Value))
+	assert.NoError(t, bucket1.Set(pair2.Key, pair2.Value))
+
+	stats, err = ndb.NamespaceStats("bucket1")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), stats.Keys)
+	assert.Equal(t, uint64(len(pair1.Key)+len(pair1.Value)+len(pair2.Key)+len(pair2.Value)), stats.Bytes)
+
+	_, err = ndb.NamespaceStats("bucket0")
+	assert.ErrorIs(t, err, store.ErrNamespaceNotFound)
+}
+
+func TestDB_DropNamespace(t *testing.T) {
+	ndb := newTestDB(t, nuts.History{MaxVersions: 2})
+
+	bucket1, err := ndb.Swap("bucket1")
+	assert.NoError(t, err)
+	assert.NoError(t, bucket1.Set(pair1.Key, pair1.Value))
+
+	assert.NoError(t, ndb.DropNamespace("bucket1"))
+	assert.ErrorIs(t, ndb.DropNamespace("bucket1"), store.ErrNamespaceNotFound)
+
+	namespaces, err := ndb.Namespaces()
+	assert.NoError(t, err)
+	assert.NotContains(t, namespaces, "bucket1")
+
+	versions, err := bucket1.History(pair1.Key, 0)
+	assert.NoError(t, err)
+	assert.Empty(t, versions)
+}
+
+func TestDB_NamespaceUsage(t *testing.T) {
+	ndb := newTestDB(t, nuts.History{})
+
+	bucket1, err := ndb.Swap("bucket1")
+	assert.NoError(t, err)
+	assert.NoError(t, bucket1.Set(pair1.Key, pair1.Value))
+
+	// the namespace is counted once, the writes keep its usage since
+	stats, err := ndb.NamespaceStats("bucket1")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), stats.Keys)
+
+	assert.NoError(t, bucket1.Set(pair1.Key, []byte("v")))
+	assert.NoError(t, bucket1.TrySetWithTTL(pair2.Key, pair2.Value, 1))
+	stats, err = ndb.NamespaceStats("bucket1")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), stats.Keys)
+	assert.Equal(t, uint64(len(pair1.Key)+1+len(pair2.Key)+len(pair2.Value)), stats.Bytes)
+
+	// the expired keys leave the usage
+	time.Sleep(1100 * time.Millisecond)
+	stats, err = ndb.NamespaceStats("bucket1")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), stats.Keys)
+	assert.Equal(t, uint64(len(pair1.Key)+1), stats.Bytes)
+
+	// a key set again after it expired is only counted once
+	assert.NoError(t, bucket1.Set(pair2.Key, pair2.Value))
+	assert.NoError(t, bucket1.Del(pair1.Key))
+	stats, err = ndb.NamespaceStats("bucket1")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), stats.Keys)
+	assert.Equal(t, uint64(len(pair2.Key)+len(pair2.Value)), stats.Bytes)
+
+	assert.NoError(t, ndb.DropNamespace("bucket1"))
+	_, err = ndb.NamespaceStats("bucket1")
+	assert.ErrorIs(t, err, store.ErrNamespaceNotFound)
+}
+
+func TestDB_UsageApplyTime(t *testing.T) {
+	var (
+		ndb  = newTestDB(t, nuts.History{})
+		base = time.Now().Add(-time.Hour)
+	)
+	ndb.SetApplyTime(base)
+
+	bucket1, err := ndb.Swap("bucket1")
+	assert.NoError(t, err)
+	assert.NoError(t, bucket1.SetWithTTL(pair1.Key, pair1.Value, 60))
+	assert.NoError(t, bucket1.Set(pair2.Key, pair2.Value))
+
+	stats, err := ndb.NamespaceStats("bucket1")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), stats.Keys)
+
+	// a member replaying the writes later counts them at the same time
+	reader, err := ndb.Snapshot(store.SnapshotMeta{})
+	assert.NoError(t, err)
+	snapshot, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.NoError(t, reader.Close())
+	replica := newTestDB(t, nuts.History{})
+	_, err = replica.Restore(bytes.NewReader(snapshot))
+	assert.NoError(t, err)
+	replica.SetApplyTime(base.Add(30 * time.Second))
+	size, found, err := replica.KeyUsage("bucket1", pair1.Key)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, uint64(len(pair1.Key)+len(pair1.Value)), size)
+
+	// the key expires at the apply time, not at the clock of nuts
+	ndb.SetApplyTime(base.Add(61 * time.Second))
+	stats, err = ndb.NamespaceStats("bucket1")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), stats.Keys)
+	assert.Equal(t, uint64(len(pair2.Key)+len(pair2.Value)), stats.Bytes)
+	_, found, err = ndb.KeyUsage("bucket1", pair1.Key)
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	// the apply time never goes backwards
+	ndb.SetApplyTime(base)
+	_, found, err = ndb.KeyUsage("bucket1", pair1.Key)
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	// the key written again is counted once
+	assert.NoError(t, bucket1.Set(pair1.Key, pair1.Value))
+	stats, err = ndb.NamespaceStats("bucket1")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), stats.Keys)
+}
@@ -41,11 +41,24 @@ func init() {
 	EnableStrictMode()
 }
 
+// History declares how many versions of each key are kept
+type History struct {
+	// MaxVersions is applied to every namespace without an explicit limit, 0 disables history
+	MaxVersions uint32
+	// Namespaces overrides MaxVersions for the given namespaces
+	Namespaces map[string]uint32
+}
+
 type Config struct {
 	NodeNum int64
 	Sync    bool
 	DataDir string
 	RWMode  RWMode
+	History History
+	// Keyring seals the values and the snapshots, nil stores them in plaintext
+	Keyring *store.Keyring
+	// LegacySnapshot accepts restoring the snapshots without manifest, they have no checksums
+	LegacySnapshot bool
 }
 
 type DB struct {
@@ -53,6 +66,11 @@ type DB struct {
 
 	db      *atomic.Pointer[nutsdb.DB]
 	options []nutsdb.Option
+	history History
+	keyring *store.Keyring
+	usage   *usages
+
+	legacySnapshot bool
 
 	// root watcher
 	watcher *Watcher
@@ -63,6 +81,10 @@ type DB struct {
 	mu        sync.RWMutex
 	namespace string
 	dataDir   string
+
+	// previousMu guards the data dir kept by the last restore, restores counts the restores
+	previousMu sync.Mutex
+	restores   uint64
 }
 
 func New(cfg Config) (store.Store, error) {
@@ -73,6 +95,10 @@ func New(cfg Config) (store.Store, error) {
 		nutsdb.WithRWMode(cfg.RWMode),
 		// nutsdb.WithSegmentSize(128 * nutsdb.MB),
 	}
+	if err := recoverRestore(cfg.DataDir); err != nil {
+		return nil, errors.Wrap(err, "can't recover nuts store restore")
+	}
+
 	db, err := nutsdb.Open(nutsdb.DefaultOptions, opts...)
 	if err != nil {
 		return nil, errors.Wrap(err, "can't create nuts store api")
@@ -83,13 +109,22 @@ func New(cfg Config) (store.Store, error) {
 	dbPtr := atomic.Pointer[nutsdb.DB]{}
 	dbPtr.Store(db)
 
-	return &DB{
-		state:        new(uint32),
-		db:           &dbPtr,
-		options:      opts,
-		watcher:      rootWatcher,
-		watcherChild: rootWatcher.UseTarget(store.DefaultNamespace),
-		namespace:    store.DefaultNamespace,
-		dataDir:      cfg.DataDir,
-	}, nil
+	s := &DB{
+		state:          new(uint32),
+		db:             &dbPtr,
+		options:        opts,
+		history:        cfg.History,
+		keyring:        cfg.Keyring,
+		usage:          newUsages(),
+		legacySnapshot: cfg.LegacySnapshot,
+		watcher:        rootWatcher,
+		watcherChild:   rootWatcher.UseTarget(store.DefaultNamespace),
+		namespace:      store.DefaultNamespace,
+		dataDir:        cfg.DataDir,
+	}
+	if err = s.sealStore(db); err != nil {
+		_ = db.Close()
+		return nil, errors.Wrap(err, "can't seal nuts store")
+	}
+	return s, nil
 }
@@ -0,0 +1,192 @@
+package nuts
+
+import (
+	"github.com/RealFax/RedQueen/internal/rqd/store"
+	"github.com/nutsdb/nutsdb"
+	"github.com/pkg/errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	// restoreStagingSuffix is the dir suffix a snapshot is extracted into before it is installed
+	restoreStagingSuffix = ".restore-"
+	// restorePreviousSuffix is the dir suffix the data dir is moved to while a snapshot is installed
+	restorePreviousSuffix = ".previous"
+	// restoreFailedSuffix is the dir suffix a failed install is moved to before it is removed
+	restoreFailedSuffix = ".failed"
+)
+
+// syncDir persists the renames made inside dir
+func syncDir(dir string) error {
+	f, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return f.Sync()
+}
+
+// recoverRestore finishes a restore interrupted by a crash. the data dir is only moved aside
+// while a verified snapshot is installed, so a missing data dir means the previous one is
+// still the good one, otherwise the install went through
+func recoverRestore(dataDir string) error {
+	previous := dataDir + restorePreviousSuffix
+	if _, err := os.Stat(previous); err == nil {
+		if _, err = os.Stat(dataDir); os.IsNotExist(err) {
+			if err = os.Rename(previous, dataDir); err != nil {
+				return errors.Wrap(err, "rollback interrupted restore error")
+			}
+		} else if err = os.RemoveAll(previous); err != nil {
+			return errors.Wrap(err, "clean previous data dir error")
+		}
+	}
+
+	if err := os.RemoveAll(dataDir + restoreFailedSuffix); err != nil {
+		return errors.Wrap(err, "clean failed restore error")
+	}
+
+	// staging dirs of interrupted restores and freeze dirs of interrupted snapshots
+	entries, err := os.ReadDir(filepath.Dir(dataDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	base := filepath.Base(dataDir)
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, base+restoreStagingSuffix) && !strings.HasPrefix(name, base+snapshotFreezeSuffix) {
+			continue
+		}
+		if err = os.RemoveAll(filepath.Join(filepath.Dir(dataDir), name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verify opens the db extracted into dir and seals its values when the keyring is configured,
+// so that a broken snapshot is rejected before the live data dir is touched
+func (s *DB) verify(dir string) error {
+	db, err := nutsdb.Open(nutsdb.DefaultOptions, append(slices.Clone(s.options), nutsdb.WithDir(dir))...)
+	if err != nil {
+		return err
+	}
+	if err = s.sealStore(db); err != nil {
+		_ = db.Close()
+		return err
+	}
+	return db.Close()
+}
+
+// rollback reopens the previous data dir after installing a snapshot failed
+func (s *DB) rollback(cause error, previous string) error {
+	if previous != "" {
+		// the failed install is moved aside rather than removed in place, a crash in between
+		// leaves no data dir and the previous one is rolled back by recoverRestore
+		failed := s.dataDir + restoreFailedSuffix
+		if err := os.RemoveAll(failed); err != nil {
+			return errors.Wrapf(cause, "rollback error: %s", err)
+		}
+		if err := os.Rename(s.dataDir, failed); err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(cause, "rollback error: %s", err)
+		}
+		if err := os.Rename(previous, s.dataDir); err != nil {
+			return errors.Wrapf(cause, "rollback error: %s", err)
+		}
+		_ = syncDir(filepath.Dir(s.dataDir))
+		_ = os.RemoveAll(failed)
+	}
+
+	db, err := nutsdb.Open(nutsdb.DefaultOptions, s.options...)
+	if err != nil {
+		return errors.Wrapf(cause, "reopen previous db error: %s", err)
+	}
+	s.db.Store(db)
+	return cause
+}
+
+// install swaps the staging dir with the data dir. the previous data dir is kept until a
+// snapshot of the restored db is written, recoverRestore removes it on the next start
+func (s *DB) install(staging string) error {
+	s.previousMu.Lock()
+	defer s.previousMu.Unlock()
+	s.restores++
+
+	previous := s.dataDir + restorePreviousSuffix
+	if err := os.RemoveAll(previous); err != nil {
+		return errors.Wrap(err, "clean previous data dir error")
+	}
+
+	// close nuts db
+	_ = s.db.Load().Close()
+
+	if err := os.Rename(s.dataDir, previous); err != nil {
+		return s.rollback(errors.Wrap(err, "move data dir error"), "")
+	}
+	if err := os.Rename(staging, s.dataDir); err != nil {
+		return s.rollback(errors.Wrap(err, "install snapshot error"), previous)
+	}
+	_ = syncDir(filepath.Dir(s.dataDir))
+
+	// reopen nuts db
+	db, err := nutsdb.Open(nutsdb.DefaultOptions, s.options...)
+	if err != nil {
+		return s.rollback(errors.Wrap(err, "open restored db error"), previous)
+	}
+	s.db.Store(db)
+	return nil
+}
+
+// dropPrevious removes the data dir kept by the last restore, once a snapshot frozen after it
+// was written. restores is the count of restores when the snapshot was frozen
+func (s *DB) dropPrevious(restores uint64) error {
+	s.previousMu.Lock()
+	defer s.previousMu.Unlock()
+	if s.restores != restores {
+		return nil
+	}
+	return os.RemoveAll(s.dataDir + restorePreviousSuffix)
+}
+
+func (s *DB) Restore(src io.Reader) (store.SnapshotMeta, error) {
+	staging, err := os.MkdirTemp(filepath.Dir(s.dataDir), filepath.Base(s.dataDir)+restoreStagingSuffix+"*")
+	if err != nil {
+		return store.SnapshotMeta{}, errors.Wrap(err, "create staging dir error")
+	}
+	// nothing is left to remove once the staging dir is installed
+	defer os.RemoveAll(staging)
+
+	if src, err = s.keyring.OpenSnapshot(src); err != nil {
+		return store.SnapshotMeta{}, errors.Wrap(err, "open snapshot error")
+	}
+	manifest, err := store.ExtractSnapshot(staging, src, s.legacySnapshot)
+	if err != nil {
+		return store.SnapshotMeta{}, errors.Wrap(err, "extract snapshot error")
+	}
+	if manifest.Version != 0 && manifest.Backend != Backend {
+		return store.SnapshotMeta{}, errors.Errorf("snapshot of store backend %s can't be restored into %s", manifest.Backend, Backend)
+	}
+	if err = s.verify(staging); err != nil {
+		return store.SnapshotMeta{}, errors.Wrap(err, "verify snapshot error")
+	}
+	meta := store.SnapshotMeta{Index: manifest.Index, Term: manifest.Term, Legacy: manifest.Version == 0}
+
+	// the usage is counted again from the restored db
+	s.usage.mu.Lock()
+	defer s.usage.mu.Unlock()
+	s.usage.reset()
+
+	resume, err := s.pause()
+	if err != nil {
+		return meta, errors.Wrap(err, "break error")
+	}
+	defer resume()
+
+	return meta, s.install(staging)
+}
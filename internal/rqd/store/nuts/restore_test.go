@@ -0,0 +1,143 @@
+package nuts_test
+
+import (
+	"bytes"
+	"github.com/RealFax/RedQueen/internal/rqd/store"
+	"github.com/RealFax/RedQueen/internal/rqd/store/nuts"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDB_RestoreCorrupt(t *testing.T) {
+	rdb := newTestDB(t, nuts.History{})
+	assert.NoError(t, rdb.Set(pair1.Key, pair1.Value))
+
+	reader, err := rdb.Snapshot(store.SnapshotMeta{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a truncated snapshot must leave the live data untouched
+	_, err = rdb.Restore(bytes.NewReader(snapshot[:len(snapshot)/2]))
+	assert.Error(t, err)
+
+	value, err := rdb.Get(pair1.Key)
+	assert.NoError(t, err)
+	assert.Equal(t, pair1.Value, value.Data)
+	assert.NoError(t, rdb.Set(pair2.Key, pair2.Value))
+}
+
+func TestNew_RecoverRestore(t *testing.T) {
+	dataDir := filepath.Join(t.TempDir(), "data")
+	cfg := nuts.Config{NodeNum: 1, DataDir: dataDir, RWMode: nuts.FileIO}
+
+	rdb, err := nuts.New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, rdb.Set(pair1.Key, pair1.Value))
+	assert.NoError(t, rdb.Close())
+
+	// crash after the data dir was moved aside, before the snapshot was installed
+	assert.NoError(t, os.Rename(dataDir, dataDir+".previous"))
+	assert.NoError(t, os.Mkdir(dataDir+".restore-1", 0700))
+
+	rdb, err = nuts.New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rdb.Close()
+
+	value, err := rdb.Get(pair1.Key)
+	assert.NoError(t, err)
+	assert.Equal(t, pair1.Value, value.Data)
+
+	assert.NoDirExists(t, dataDir+".previous")
+	assert.NoDirExists(t, dataDir+".restore-1")
+}
+
+func TestNew_RecoverRollback(t *testing.T) {
+	dataDir := filepath.Join(t.TempDir(), "data")
+	cfg := nuts.Config{NodeNum: 1, DataDir: dataDir, RWMode: nuts.FileIO}
+
+	rdb, err := nuts.New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, rdb.Set(pair1.Key, pair1.Value))
+	assert.NoError(t, rdb.Close())
+
+	// crash during the rollback, after the failed install was moved aside
+	assert.NoError(t, os.Rename(dataDir, dataDir+".previous"))
+	assert.NoError(t, os.Mkdir(dataDir+".failed", 0700))
+
+	rdb, err = nuts.New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rdb.Close()
+
+	value, err := rdb.Get(pair1.Key)
+	assert.NoError(t, err)
+	assert.Equal(t, pair1.Value, value.Data)
+
+	assert.NoDirExists(t, dataDir+".previous")
+	assert.NoDirExists(t, dataDir+".failed")
+}
+
+func TestDB_RestoreKeepsPrevious(t *testing.T) {
+	dataDir := filepath.Join(t.TempDir(), "data")
+	cfg := nuts.Config{NodeNum: 1, DataDir: dataDir, RWMode: nuts.FileIO}
+
+	rdb, err := nuts.New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, rdb.Set(pair1.Key, pair1.Value))
+
+	reader, err := rdb.Snapshot(store.SnapshotMeta{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the replaced data dir is kept until the restored db is in a snapshot
+	_, err = rdb.Restore(bytes.NewReader(snapshot))
+	assert.NoError(t, err)
+	assert.DirExists(t, dataDir+".previous")
+
+	reader, err = rdb.Snapshot(store.SnapshotMeta{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = io.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.NoDirExists(t, dataDir+".previous")
+
+	// or until the next start
+	_, err = rdb.Restore(bytes.NewReader(snapshot))
+	assert.NoError(t, err)
+	assert.DirExists(t, dataDir+".previous")
+	assert.NoError(t, rdb.Close())
+
+	rdb, err = nuts.New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rdb.Close()
+	assert.NoDirExists(t, dataDir+".previous")
+
+	value, err := rdb.Get(pair1.Key)
+	assert.NoError(t, err)
+	assert.Equal(t, pair1.Value, value.Data)
+}
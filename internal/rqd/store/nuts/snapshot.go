@@ -0,0 +1,146 @@
+package nuts
+
+import (
+	"github.com/RealFax/RedQueen/internal/rqd/store"
+	"github.com/nutsdb/nutsdb"
+	"github.com/pkg/errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// Backend is the store backend name recorded in snapshot manifests
+const Backend = "nuts"
+
+// snapshotFreezeSuffix is the dir suffix the files of a snapshot are hard linked into
+const snapshotFreezeSuffix = ".snapshot-"
+
+// frozenFile is a file of the data dir as it was when the snapshot was taken
+type frozenFile struct {
+	name string
+	info fs.FileInfo
+	// size is the number of bytes that belong to the snapshot
+	size int64
+}
+
+// freeze hard links the files of the data dir into dir. nutsdb only appends to the active data
+// file, so recording its write offset is enough to keep later writes out of the snapshot
+func (s *DB) freeze(db *nutsdb.DB, dir string) ([]frozenFile, error) {
+	active := strconv.FormatInt(db.MaxFileID, 10) + nutsdb.DataSuffix
+
+	var files []frozenFile
+	err := db.View(func(tx *nutsdb.Tx) error {
+		return filepath.WalkDir(s.dataDir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			// the root is kept as ".", restoring relies on it to recreate the data dir
+			name, err := filepath.Rel(s.dataDir, path)
+			if err != nil {
+				return err
+			}
+
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+
+			if d.IsDir() {
+				files = append(files, frozenFile{name: name, info: info})
+				return os.MkdirAll(filepath.Join(dir, name), info.Mode().Perm())
+			}
+			if !info.Mode().IsRegular() {
+				return nil
+			}
+
+			size := info.Size()
+			if name == active && db.ActiveFile != nil && db.ActiveFile.ActualSize < size {
+				size = db.ActiveFile.ActualSize
+			}
+			files = append(files, frozenFile{name: name, info: info, size: size})
+			return os.Link(path, filepath.Join(dir, name))
+		})
+	})
+	return files, err
+}
+
+// writeFrozen writes the snapshot of the frozen files, sealed with the keyring
+func (s *DB) writeFrozen(dir string, files []frozenFile, meta store.SnapshotMeta, dst io.Writer) error {
+	sealer, err := s.keyring.SealSnapshot(dst)
+	if err != nil {
+		return err
+	}
+
+	w := store.NewSnapshotWriter(sealer, Backend, meta)
+	for _, file := range files {
+		if file.info.IsDir() {
+			if err := w.WriteDir(file.name, file.info); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := copyFrozen(w, dir, file); err != nil {
+			return err
+		}
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+	return sealer.Close()
+}
+
+func copyFrozen(w *store.SnapshotWriter, dir string, file frozenFile) error {
+	f, err := os.Open(filepath.Join(dir, file.name))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return w.WriteFile(file.name, file.info, file.size, f)
+}
+
+func (s *DB) Snapshot(meta store.SnapshotMeta) (io.ReadCloser, error) {
+	// get db session first
+	db, err := s.DB()
+	if err != nil {
+		return nil, errors.Wrap(err, "fail snapshot, get db error")
+	}
+
+	// merging runs alongside writes, keep it out of the paused section
+	_ = db.Merge()
+
+	dir, err := os.MkdirTemp(filepath.Dir(s.dataDir), filepath.Base(s.dataDir)+snapshotFreezeSuffix+"*")
+	if err != nil {
+		return nil, errors.Wrap(err, "fail snapshot, create freeze dir error")
+	}
+
+	// writes are only paused while the files are frozen
+	resume, err := s.pause()
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, errors.Wrap(err, "fail snapshot, break error")
+	}
+	files, err := s.freeze(db, dir)
+	// the restores install under the pause as well
+	restores := s.restores
+	resume()
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, errors.Wrap(err, "fail snapshot, freeze error")
+	}
+
+	r, w := io.Pipe()
+	go func() {
+		defer os.RemoveAll(dir)
+		err := s.writeFrozen(dir, files, meta, w)
+		if err == nil {
+			// the restored db is in a snapshot, the data dir it replaced isn't needed anymore
+			_ = s.dropPrevious(restores)
+		}
+		_ = w.CloseWithError(err)
+	}()
+	return r, nil
+}
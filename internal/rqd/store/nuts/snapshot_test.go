@@ -0,0 +1,152 @@
+package nuts_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"github.com/RealFax/RedQueen/internal/rqd/store"
+	"github.com/RealFax/RedQueen/internal/rqd/store/nuts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"testing"
+)
+
+func TestDB_SnapshotPointInTime(t *testing.T) {
+	sdb := newTestDB(t, nuts.History{})
+
+	for _, pair := range pairMatrix[:5] {
+		assert.NoError(t, sdb.Set(pair.Key, pair.Value))
+	}
+
+	reader, err := sdb.Snapshot(store.SnapshotMeta{Index: 7, Term: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// writes resume right after the snapshot is taken and must not leak into it
+	for _, pair := range pairMatrix[5:] {
+		assert.NoError(t, sdb.Set(pair.Key, pair.Value))
+	}
+
+	meta, err := sdb.Restore(reader)
+	assert.NoError(t, err)
+	assert.Equal(t, store.SnapshotMeta{Index: 7, Term: 2}, meta)
+	assert.NoError(t, reader.Close())
+
+	for _, pair := range pairMatrix[:5] {
+		value, gErr := sdb.Get(pair.Key)
+		assert.NoError(t, gErr)
+		assert.Equal(t, pair.Value, value.Data)
+	}
+	for _, pair := range pairMatrix[5:] {
+		_, gErr := sdb.Get(pair.Key)
+		assert.ErrorIs(t, gErr, store.ErrKeyNotFound)
+	}
+}
+
+func TestDB_SnapshotRelease(t *testing.T) {
+	sdb := newTestDB(t, nuts.History{})
+	assert.NoError(t, sdb.Set(pair1.Key, pair1.Value))
+
+	reader, err := sdb.Snapshot(store.SnapshotMeta{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// closing before reading must not block the snapshot writer
+	assert.NoError(t, reader.Close())
+	_, err = reader.Read(make([]byte, 1))
+	assert.ErrorIs(t, err, io.ErrClosedPipe)
+
+	assert.NoError(t, sdb.Set(pair2.Key, pair2.Value))
+}
+
+func TestDB_SnapshotEncryption(t *testing.T) {
+	var (
+		keyring = newKeyring(t)
+		edb     = newEncryptedDB(t, t.TempDir(), keyring)
+	)
+	defer edb.Close()
+	assert.NoError(t, edb.Set(pair1.Key, pair1.Value))
+
+	reader, err := edb.Snapshot(store.SnapshotMeta{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.NoError(t, reader.Close())
+
+	// the snapshot is opened with the keyring only
+	_, err = store.VerifySnapshot(bytes.NewReader(sealed), false)
+	assert.Error(t, err)
+	plain := newEncryptedDB(t, t.TempDir(), nil)
+	defer plain.Close()
+	_, err = plain.Restore(bytes.NewReader(sealed))
+	assert.ErrorIs(t, err, store.ErrSnapshotEncrypted)
+
+	restored := newEncryptedDB(t, t.TempDir(), keyring)
+	defer restored.Close()
+	_, err = restored.Restore(bytes.NewReader(sealed))
+	assert.NoError(t, err)
+	value, err := restored.Get(pair1.Key)
+	assert.NoError(t, err)
+	assert.Equal(t, pair1.Value, value.Data)
+}
+
+// stripManifest rewrites a snapshot the way the releases before the manifest wrote it
+func stripManifest(t *testing.T, snapshot []byte) []byte {
+	gr, err := gzip.NewReader(bytes.NewReader(snapshot))
+	require.NoError(t, err)
+	var (
+		tr  = tar.NewReader(gr)
+		buf = &bytes.Buffer{}
+		gw  = gzip.NewWriter(buf)
+		tw  = tar.NewWriter(gw)
+	)
+	for {
+		header, rErr := tr.Next()
+		if rErr == io.EOF {
+			break
+		}
+		require.NoError(t, rErr)
+		if header.Name == store.SnapshotManifestName {
+			continue
+		}
+		require.NoError(t, tw.WriteHeader(header))
+		_, err = io.Copy(tw, tr)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gw.Close())
+	return buf.Bytes()
+}
+
+func TestDB_RestoreLegacySnapshot(t *testing.T) {
+	sdb := newTestDB(t, nuts.History{})
+	assert.NoError(t, sdb.Set(pair1.Key, pair1.Value))
+
+	reader, err := sdb.Snapshot(store.SnapshotMeta{Index: 7, Term: 2})
+	require.NoError(t, err)
+	snapshot, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.NoError(t, reader.Close())
+	legacy := stripManifest(t, snapshot)
+
+	// the legacy snapshots are only rejected in strict mode
+	strict, err := nuts.New(nuts.Config{NodeNum: 1, DataDir: t.TempDir(), RWMode: nuts.FileIO})
+	require.NoError(t, err)
+	defer strict.Close()
+	_, err = strict.Restore(bytes.NewReader(legacy))
+	assert.ErrorIs(t, err, store.ErrSnapshotLegacy)
+
+	restored, err := nuts.New(nuts.Config{NodeNum: 1, DataDir: t.TempDir(), RWMode: nuts.FileIO, LegacySnapshot: true})
+	require.NoError(t, err)
+	defer restored.Close()
+	meta, err := restored.Restore(bytes.NewReader(legacy))
+	assert.NoError(t, err)
+	assert.Equal(t, store.SnapshotMeta{Legacy: true}, meta)
+	value, err := restored.Get(pair1.Key)
+	assert.NoError(t, err)
+	assert.Equal(t, pair1.Value, value.Data)
+}
@@ -0,0 +1,310 @@
+package nuts
+
+import (
+	"container/heap"
+	"encoding/binary"
+	"sync"
+	"time"
+
+	"github.com/RealFax/RedQueen/internal/rqd/store"
+	"github.com/nutsdb/nutsdb"
+	"github.com/pkg/errors"
+)
+
+const (
+	// UsageBucketPrefix is the bucket prefix of the deadlines the keys with a ttl are counted
+	// with. the deadlines start at the apply time of the write, unlike the ttl of nuts which
+	// starts at the local clock of each member
+	UsageBucketPrefix = "_Usage:"
+
+	usageRowSize = 16
+)
+
+func init() {
+	store.ReserveNamespacePrefix(UsageBucketPrefix)
+}
+
+func usageBucket(namespace string) string {
+	return UsageBucketPrefix + namespace
+}
+
+func encodeUsageRow(size uint64, deadline time.Time) []byte {
+	p := binary.BigEndian.AppendUint64(make([]byte, 0, usageRowSize), size)
+	return binary.BigEndian.AppendUint64(p, uint64(deadline.UnixMilli()))
+}
+
+func decodeUsageRow(p []byte) (size uint64, deadline time.Time, err error) {
+	if len(p) != usageRowSize {
+		return 0, time.Time{}, errors.New("malformed usage row")
+	}
+	return binary.BigEndian.Uint64(p[:8]), time.UnixMilli(int64(binary.BigEndian.Uint64(p[8:]))), nil
+}
+
+type expiringKey struct {
+	deadline time.Time
+	size     uint64
+}
+
+type keyDeadline struct {
+	deadline time.Time
+	key      string
+}
+
+// deadlineHeap orders the keys with a ttl by their deadline, earliest first
+type deadlineHeap []keyDeadline
+
+func (h deadlineHeap) Len() int           { return len(h) }
+func (h deadlineHeap) Less(i, j int) bool { return h[i].deadline.Before(h[j].deadline) }
+func (h deadlineHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *deadlineHeap) Push(x any)        { *h = append(*h, x.(keyDeadline)) }
+func (h *deadlineHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// usage is the live keys and bytes of a namespace. it is counted once by the first
+// NamespaceStats or write of the namespace and kept up to date by the writes since, so that the
+// quotas only scan the namespace once
+type usage struct {
+	keys, bytes uint64
+	// expiring holds the keys with a ttl, they leave the usage once their deadline passes
+	expiring  map[string]expiringKey
+	deadlines deadlineHeap
+	// expired holds the keys that left the usage, their rows are dropped by the next write
+	expired []string
+}
+
+func (u *usage) add(key string, size uint64, deadline time.Time) {
+	u.keys++
+	u.bytes += size
+	if deadline.IsZero() {
+		return
+	}
+	u.expiring[key] = expiringKey{deadline: deadline, size: size}
+	heap.Push(&u.deadlines, keyDeadline{deadline: deadline, key: key})
+}
+
+// remove takes key out of the usage, prev is the size of the live key
+func (u *usage) remove(key string, prev uint64, found bool) {
+	if !found {
+		return
+	}
+	u.keys--
+	u.bytes -= prev
+	delete(u.expiring, key)
+}
+
+// replace applies a committed write of key, the ttl of the key starts at now
+func (u *usage) replace(key string, prev uint64, found bool, size uint64, ttl uint32, now time.Time) {
+	u.remove(key, prev, found)
+	u.add(key, size, ttlDeadline(ttl, now))
+}
+
+// expire drops the keys whose deadline passed, the deadlines of overwritten keys are stale
+func (u *usage) expire(now time.Time) {
+	for len(u.deadlines) != 0 && !u.deadlines[0].deadline.After(now) {
+		d := heap.Pop(&u.deadlines).(keyDeadline)
+		if e, ok := u.expiring[d.key]; ok && e.deadline.Equal(d.deadline) {
+			u.remove(d.key, e.size, true)
+			u.expired = append(u.expired, d.key)
+		}
+	}
+}
+
+func (u *usage) stats(namespace string) *store.NamespaceStats {
+	return &store.NamespaceStats{Namespace: namespace, Keys: u.keys, Bytes: u.bytes}
+}
+
+// usages is shared by every namespace of a DB, writes and counts are serialized on mu
+type usages struct {
+	mu         sync.Mutex
+	namespaces map[string]*usage
+	// applied is the time of the last applied log, zero until SetApplyTime is called
+	applied time.Time
+}
+
+func newUsages() *usages {
+	return &usages{namespaces: make(map[string]*usage)}
+}
+
+// reset forgets every counted namespace, mu must be held
+func (u *usages) reset() {
+	u.namespaces = make(map[string]*usage)
+}
+
+// now is the time the usage is accounted at, mu must be held. the members apply the same logs
+// at the same time, so that they count the same usage whatever their clock
+func (u *usages) now() time.Time {
+	if u.applied.IsZero() {
+		return time.Now()
+	}
+	return u.applied
+}
+
+// SetApplyTime sets the time the writes are accounted at, it never goes backwards
+func (s *DB) SetApplyTime(t time.Time) {
+	s.usage.mu.Lock()
+	defer s.usage.mu.Unlock()
+	if t.After(s.usage.applied) {
+		s.usage.applied = t
+	}
+}
+
+func ttlDeadline(ttl uint32, now time.Time) time.Time {
+	if ttl == nutsdb.Persistent {
+		return time.Time{}
+	}
+	return now.Add(time.Duration(ttl) * time.Second)
+}
+
+func isNotFound(err error) bool {
+	return errors.Is(err, nutsdb.ErrKeyNotFound) ||
+		errors.Is(err, nutsdb.ErrNotFoundKey) ||
+		errors.Is(err, nutsdb.ErrNotFoundBucket) ||
+		errors.Is(err, nutsdb.ErrBucketEmpty)
+}
+
+// entrySize is the plaintext size of an entry, the quotas don't depend on the encryption of each member
+func (s *DB) entrySize(key, value []byte) uint64 {
+	if s.keyring != nil && len(value) >= store.EnvelopeOverhead {
+		return uint64(len(key) + len(value) - store.EnvelopeOverhead)
+	}
+	return uint64(len(key) + len(value))
+}
+
+func entryDeadline(meta *nutsdb.MetaData) time.Time {
+	if meta.TTL == nutsdb.Persistent {
+		return time.Time{}
+	}
+	return time.UnixMilli(int64(meta.Timestamp)).Add(time.Duration(meta.TTL) * time.Second)
+}
+
+// trackUsage returns the usage of the current namespace, it is counted by tx when it isn't
+// yet. writes of the bucket init key are never counted. mu must be held
+func (s *DB) trackUsage(tx *nutsdb.Tx, key []byte) (*usage, error) {
+	if string(key) == string(KeyInitBucket) {
+		return nil, nil
+	}
+	if u, ok := s.usage.namespaces[s.namespace]; ok {
+		u.expire(s.usage.now())
+		return u, nil
+	}
+	u, err := s.countUsage(tx, s.namespace, s.usage.now())
+	if err != nil {
+		return nil, err
+	}
+	s.usage.namespaces[s.namespace] = u
+	return u, nil
+}
+
+// keyUsage returns the size key counts in the usage of namespace at now, found is false when
+// the key isn't live. the keys with a ttl are looked up by their row, nuts expires them with
+// the local clock
+func (s *DB) keyUsage(tx *nutsdb.Tx, namespace string, key []byte, now time.Time) (uint64, bool, error) {
+	row, err := tx.Get(usageBucket(namespace), key)
+	switch {
+	case err == nil:
+		size, deadline, dErr := decodeUsageRow(row.Value)
+		if dErr != nil {
+			return 0, false, dErr
+		}
+		return size, deadline.After(now), nil
+	case !isNotFound(err):
+		return 0, false, err
+	}
+
+	entry, err := tx.Get(namespace, key)
+	if err != nil {
+		if isNotFound(err) {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+	// the keys with a ttl written before the rows are counted with the deadline of nuts
+	if deadline := entryDeadline(entry.Meta); !deadline.IsZero() && !deadline.After(now) {
+		return 0, false, nil
+	}
+	return s.entrySize(entry.Key, entry.Value), true, nil
+}
+
+// KeyUsage returns the size key counts in the usage of namespace, found is false when the key
+// isn't counted
+func (s *DB) KeyUsage(namespace string, key []byte) (size uint64, found bool, err error) {
+	s.usage.mu.Lock()
+	defer s.usage.mu.Unlock()
+	err = s.Transaction(false, func(tx *nutsdb.Tx) (err error) {
+		size, found, err = s.keyUsage(tx, namespace, key, s.usage.now())
+		return
+	})
+	return
+}
+
+// putUsage keeps the row of key up to date with a committed write, the expired rows of u are
+// dropped along. tx must be a writable transaction of the current namespace
+func (s *DB) putUsage(tx *nutsdb.Tx, u *usage, key []byte, size uint64, ttl uint32, deleted bool, now time.Time) error {
+	bucket := usageBucket(s.namespace)
+	for _, expired := range u.expired {
+		if expired == string(key) {
+			continue
+		}
+		if err := tx.Delete(bucket, []byte(expired)); err != nil && !isNotFound(err) {
+			return err
+		}
+	}
+	u.expired = u.expired[:0]
+
+	if deleted || ttl == nutsdb.Persistent {
+		if err := tx.Delete(bucket, key); err != nil && !isNotFound(err) {
+			return err
+		}
+		return nil
+	}
+	return tx.Put(bucket, key, encodeUsageRow(size, ttlDeadline(ttl, now)), nutsdb.Persistent)
+}
+
+// countUsage counts the live keys of namespace at now, tx must be a transaction of the same db
+func (s *DB) countUsage(tx *nutsdb.Tx, namespace string, now time.Time) (*usage, error) {
+	u := &usage{expiring: make(map[string]expiringKey)}
+
+	rows, err := tx.GetAll(usageBucket(namespace))
+	if err != nil && !isNotFound(err) {
+		return nil, err
+	}
+	counted := make(map[string]struct{}, len(rows))
+	for _, row := range rows {
+		size, deadline, dErr := decodeUsageRow(row.Value)
+		if dErr != nil {
+			return nil, dErr
+		}
+		counted[string(row.Key)] = struct{}{}
+		if deadline.After(now) {
+			u.add(string(row.Key), size, deadline)
+		} else {
+			u.expired = append(u.expired, string(row.Key))
+		}
+	}
+
+	entries, err := tx.GetAll(namespace)
+	if err != nil {
+		if isNotFound(err) {
+			return u, nil
+		}
+		return nil, err
+	}
+	for _, entry := range entries {
+		if string(entry.Key) == string(KeyInitBucket) {
+			continue
+		}
+		if _, ok := counted[string(entry.Key)]; ok {
+			continue
+		}
+		deadline := entryDeadline(entry.Meta)
+		if !deadline.IsZero() && !deadline.After(now) {
+			continue
+		}
+		u.add(string(entry.Key), s.entrySize(entry.Key, entry.Value), deadline)
+	}
+	return u, nil
+}
@@ -0,0 +1,44 @@
+package store
+
+import (
+	"strings"
+	"sync"
+)
+
+// reserved namespaces are maintained by RedQueen itself, clients should never read or write them
+var (
+	reservedMu       sync.RWMutex
+	reservedNames    = map[string]struct{}{}
+	reservedPrefixes []string
+)
+
+// ReserveNamespace marks the namespaces as internal
+func ReserveNamespace(namespaces ...string) {
+	reservedMu.Lock()
+	for _, namespace := range namespaces {
+		reservedNames[namespace] = struct{}{}
+	}
+	reservedMu.Unlock()
+}
+
+// ReserveNamespacePrefix marks every namespace starting with prefix as internal
+func ReserveNamespacePrefix(prefix string) {
+	reservedMu.Lock()
+	reservedPrefixes = append(reservedPrefixes, prefix)
+	reservedMu.Unlock()
+}
+
+func IsReservedNamespace(namespace string) bool {
+	reservedMu.RLock()
+	defer reservedMu.RUnlock()
+
+	if _, ok := reservedNames[namespace]; ok {
+		return true
+	}
+	for _, prefix := range reservedPrefixes {
+		if strings.HasPrefix(namespace, prefix) {
+			return true
+		}
+	}
+	return false
+}
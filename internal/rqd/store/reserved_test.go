@@ -0,0 +1,17 @@
+package store_test
+
+import (
+	"github.com/RealFax/RedQueen/internal/rqd/store"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestIsReservedNamespace(t *testing.T) {
+	store.ReserveNamespace("_Test")
+	store.ReserveNamespacePrefix("_TestPrefix:")
+
+	assert.True(t, store.IsReservedNamespace("_Test"))
+	assert.True(t, store.IsReservedNamespace("_TestPrefix:bucket1"))
+	assert.False(t, store.IsReservedNamespace("_Test1"))
+	assert.False(t, store.IsReservedNamespace(store.DefaultNamespace))
+}
@@ -0,0 +1,249 @@
+package store
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// SnapshotFormatVersion is the version of the snapshot envelope written by this build
+	SnapshotFormatVersion = 1
+	// SnapshotManifestName is the name of the last archive entry, it describes the entries before it
+	SnapshotManifestName = "MANIFEST.json"
+)
+
+var (
+	ErrSnapshotManifest = errors.New("invalid snapshot manifest")
+	ErrSnapshotChecksum = errors.New("snapshot checksum mismatch")
+	ErrSnapshotPath     = errors.New("snapshot entry escapes the target dir")
+	ErrSnapshotLegacy   = errors.New("legacy snapshot without manifest")
+)
+
+// SnapshotMeta is the raft position of the state included in a snapshot
+type SnapshotMeta struct {
+	Index uint64
+	Term  uint64
+	// Legacy is set by Restore when the snapshot has no manifest
+	Legacy bool
+}
+
+type SnapshotFile struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// SnapshotManifest describes a snapshot, version 0 means a legacy snapshot without manifest
+type SnapshotManifest struct {
+	Version int            `json:"version"`
+	Backend string         `json:"backend"`
+	Index   uint64         `json:"index"`
+	Term    uint64         `json:"term"`
+	Files   []SnapshotFile `json:"files"`
+}
+
+// SnapshotWriter writes the snapshot envelope, a tar & gzip archive of the store files
+// followed by the manifest of them
+type SnapshotWriter struct {
+	gzipWriter *gzip.Writer
+	tarWriter  *tar.Writer
+	manifest   SnapshotManifest
+}
+
+func (w *SnapshotWriter) WriteDir(name string, info fs.FileInfo) error {
+	header, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+	header.Name = name
+	return w.tarWriter.WriteHeader(header)
+}
+
+// WriteFile writes the first size bytes of r as the file name
+func (w *SnapshotWriter) WriteFile(name string, info fs.FileInfo, size int64, r io.Reader) error {
+	if name == SnapshotManifestName {
+		return errors.Wrapf(ErrSnapshotManifest, "file name %s is reserved", name)
+	}
+
+	header, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+	header.Name = name
+	header.Size = size
+
+	if err = w.tarWriter.WriteHeader(header); err != nil {
+		return err
+	}
+
+	h := sha256.New()
+	if _, err = io.CopyN(io.MultiWriter(w.tarWriter, h), r, size); err != nil {
+		return err
+	}
+
+	w.manifest.Files = append(w.manifest.Files, SnapshotFile{
+		Name:   name,
+		Size:   size,
+		SHA256: hex.EncodeToString(h.Sum(nil)),
+	})
+	return nil
+}
+
+// Close writes the manifest and flushes the archive
+func (w *SnapshotWriter) Close() error {
+	b, err := json.Marshal(w.manifest)
+	if err != nil {
+		return err
+	}
+
+	if err = w.tarWriter.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     SnapshotManifestName,
+		Mode:     0600,
+		Size:     int64(len(b)),
+	}); err != nil {
+		return err
+	}
+	if _, err = w.tarWriter.Write(b); err != nil {
+		return err
+	}
+
+	if err = w.tarWriter.Close(); err != nil {
+		return err
+	}
+	return w.gzipWriter.Close()
+}
+
+func NewSnapshotWriter(dst io.Writer, backend string, meta SnapshotMeta) *SnapshotWriter {
+	gzipWriter := gzip.NewWriter(dst)
+	return &SnapshotWriter{
+		gzipWriter: gzipWriter,
+		tarWriter:  tar.NewWriter(gzipWriter),
+		manifest: SnapshotManifest{
+			Version: SnapshotFormatVersion,
+			Backend: backend,
+			Index:   meta.Index,
+			Term:    meta.Term,
+		},
+	}
+}
+
+func (m *SnapshotManifest) verify(files map[string]SnapshotFile) error {
+	if m.Version < 1 || m.Version > SnapshotFormatVersion {
+		return errors.Wrapf(ErrSnapshotManifest, "unsupported version %d", m.Version)
+	}
+	if len(m.Files) != len(files) {
+		return errors.Wrapf(ErrSnapshotManifest, "expect %d files, got %d", len(m.Files), len(files))
+	}
+	for _, expect := range m.Files {
+		got, ok := files[expect.Name]
+		if !ok {
+			return errors.Wrapf(ErrSnapshotManifest, "missing file %s", expect.Name)
+		}
+		if got.Size != expect.Size || got.SHA256 != expect.SHA256 {
+			return errors.Wrapf(ErrSnapshotChecksum, "file %s", expect.Name)
+		}
+	}
+	return nil
+}
+
+func extractFile(path string, mode fs.FileMode, r io.Reader, h io.Writer) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err = io.Copy(io.MultiWriter(f, h), r); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// ExtractSnapshot extracts the snapshot from src into dst and verifies it against its manifest.
+// dst may be empty to only verify the snapshot.
+//
+// legacy snapshots without manifest are rejected with ErrSnapshotLegacy unless legacy is set,
+// their entries are then only guarded against escaping dst
+func ExtractSnapshot(dst string, src io.Reader, legacy bool) (*SnapshotManifest, error) {
+	gzipReader, err := gzip.NewReader(src)
+	if err != nil {
+		return nil, err
+	}
+	defer gzipReader.Close()
+
+	var (
+		tarReader = tar.NewReader(gzipReader)
+		manifest  *SnapshotManifest
+		files     = make(map[string]SnapshotFile)
+	)
+	for {
+		header, rErr := tarReader.Next()
+		if rErr != nil {
+			if rErr == io.EOF {
+				break // end of archive
+			}
+			return nil, rErr
+		}
+
+		if manifest != nil {
+			return nil, errors.Wrapf(ErrSnapshotManifest, "unexpected entry %s after manifest", header.Name)
+		}
+		if !filepath.IsLocal(header.Name) {
+			return nil, errors.Wrap(ErrSnapshotPath, header.Name)
+		}
+		name := filepath.Clean(header.Name)
+
+		switch {
+		case header.Typeflag == tar.TypeReg && name == SnapshotManifestName:
+			manifest = &SnapshotManifest{}
+			if err = json.NewDecoder(tarReader).Decode(manifest); err != nil {
+				return nil, errors.Wrap(ErrSnapshotManifest, err.Error())
+			}
+		case header.Typeflag == tar.TypeDir:
+			if dst == "" {
+				continue
+			}
+			if err = os.MkdirAll(filepath.Join(dst, name), os.FileMode(header.Mode).Perm()); err != nil {
+				return nil, err
+			}
+		case header.Typeflag == tar.TypeReg:
+			h := sha256.New()
+			if dst == "" {
+				_, err = io.Copy(h, tarReader)
+			} else {
+				err = extractFile(filepath.Join(dst, name), os.FileMode(header.Mode).Perm(), tarReader, h)
+			}
+			if err != nil {
+				return nil, err
+			}
+			files[name] = SnapshotFile{Name: name, Size: header.Size, SHA256: hex.EncodeToString(h.Sum(nil))}
+		default:
+			return nil, errors.Errorf("unsupported tar entry: %s", header.Name)
+		}
+	}
+
+	if manifest == nil {
+		if !legacy {
+			return nil, ErrSnapshotLegacy
+		}
+		return &SnapshotManifest{}, nil
+	}
+	if err = manifest.verify(files); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// VerifySnapshot verifies the snapshot from src without extracting it
+func VerifySnapshot(src io.Reader, legacy bool) (*SnapshotManifest, error) {
+	return ExtractSnapshot("", src, legacy)
+}
@@ -0,0 +1,138 @@
+package store_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"github.com/RealFax/RedQueen/internal/rqd/store"
+	"github.com/stretchr/testify/assert"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type fileInfo struct {
+	name string
+	size int64
+}
+
+func (f fileInfo) Name() string       { return f.name }
+func (f fileInfo) Size() int64        { return f.size }
+func (f fileInfo) Mode() fs.FileMode  { return 0600 }
+func (f fileInfo) ModTime() time.Time { return time.Time{} }
+func (f fileInfo) IsDir() bool        { return false }
+func (f fileInfo) Sys() any           { return nil }
+
+func newSnapshot(t *testing.T, files map[string][]byte) []byte {
+	buf := &bytes.Buffer{}
+	w := store.NewSnapshotWriter(buf, "test", store.SnapshotMeta{Index: 10, Term: 2})
+	for name, data := range files {
+		if err := w.WriteFile(name, fileInfo{name: name, size: int64(len(data))}, int64(len(data)), bytes.NewReader(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// newArchive writes a tar & gzip archive without manifest
+func newArchive(t *testing.T, files map[string][]byte) []byte {
+	buf := &bytes.Buffer{}
+	gw := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gw)
+	for name, data := range files {
+		if err := tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: name, Mode: 0600, Size: int64(len(data))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assert.NoError(t, tw.Close())
+	assert.NoError(t, gw.Close())
+	return buf.Bytes()
+}
+
+func TestExtractSnapshot(t *testing.T) {
+	dst := t.TempDir()
+	snapshot := newSnapshot(t, map[string][]byte{"0.dat": []byte("data")})
+
+	manifest, err := store.ExtractSnapshot(dst, bytes.NewReader(snapshot), false)
+	assert.NoError(t, err)
+	assert.Equal(t, store.SnapshotFormatVersion, manifest.Version)
+	assert.Equal(t, "test", manifest.Backend)
+	assert.Equal(t, uint64(10), manifest.Index)
+	assert.Equal(t, uint64(2), manifest.Term)
+	assert.Len(t, manifest.Files, 1)
+
+	data, err := os.ReadFile(filepath.Join(dst, "0.dat"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("data"), data)
+}
+
+func TestVerifySnapshot(t *testing.T) {
+	snapshot := newSnapshot(t, map[string][]byte{"0.dat": []byte("data")})
+	_, err := store.VerifySnapshot(bytes.NewReader(snapshot), false)
+	assert.NoError(t, err)
+
+	// truncated snapshot
+	_, err = store.VerifySnapshot(bytes.NewReader(snapshot[:len(snapshot)/2]), false)
+	assert.Error(t, err)
+
+	// legacy snapshot without manifest, only accepted explicitly
+	legacy := newArchive(t, map[string][]byte{"0.dat": []byte("data")})
+	_, err = store.VerifySnapshot(bytes.NewReader(legacy), false)
+	assert.ErrorIs(t, err, store.ErrSnapshotLegacy)
+	manifest, err := store.VerifySnapshot(bytes.NewReader(legacy), true)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, manifest.Version)
+
+	// entry escaping the target dir
+	_, err = store.VerifySnapshot(bytes.NewReader(newArchive(t, map[string][]byte{"../0.dat": []byte("data")})), false)
+	assert.ErrorIs(t, err, store.ErrSnapshotPath)
+}
+
+func TestVerifySnapshot_Checksum(t *testing.T) {
+	snapshot := newSnapshot(t, map[string][]byte{"0.dat": []byte("data")})
+
+	// replace the data with a tampered copy, keep the original manifest
+	var tampered []byte
+	{
+		gr, err := gzip.NewReader(bytes.NewReader(snapshot))
+		if err != nil {
+			t.Fatal(err)
+		}
+		tr := tar.NewReader(gr)
+		entries := map[string][]byte{}
+		var order []string
+		for {
+			header, rErr := tr.Next()
+			if rErr != nil {
+				break
+			}
+			buf := &bytes.Buffer{}
+			_, _ = buf.ReadFrom(tr)
+			entries[header.Name] = buf.Bytes()
+			order = append(order, header.Name)
+		}
+		entries["0.dat"] = []byte("dato")
+
+		buf := &bytes.Buffer{}
+		gw := gzip.NewWriter(buf)
+		tw := tar.NewWriter(gw)
+		for _, name := range order {
+			assert.NoError(t, tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: name, Mode: 0600, Size: int64(len(entries[name]))}))
+			_, _ = tw.Write(entries[name])
+		}
+		assert.NoError(t, tw.Close())
+		assert.NoError(t, gw.Close())
+		tampered = buf.Bytes()
+	}
+
+	_, err := store.VerifySnapshot(bytes.NewReader(tampered), false)
+	assert.ErrorIs(t, err, store.ErrSnapshotChecksum)
+}
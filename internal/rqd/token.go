@@ -0,0 +1,219 @@
+package rqd
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/RealFax/RedQueen/api/serverpb"
+	"github.com/RealFax/RedQueen/internal/rqd/config"
+	"github.com/RealFax/RedQueen/internal/rqd/store"
+	"github.com/RealFax/RedQueen/pkg/expr"
+	"github.com/RealFax/RedQueen/pkg/httputil"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// TokenKeyNamespace holds the key the bearer tokens are signed with, the leader generates it once
+// so that every member accepts the tokens issued by the others
+const TokenKeyNamespace = "_TokenKey"
+
+const tokenKeySize = 32
+
+var tokenSigningKey = []byte("signing-key")
+
+var (
+	ErrTokenKeyNotFound = errors.New("token signing key not found")
+	ErrInvalidToken     = errors.New("invalid token")
+	ErrTokenExpired     = errors.New("token expired")
+	ErrTokenRevoked     = errors.New("token revoked by a credentials change")
+	ErrTokenRenewal     = errors.New("a token can't be exchanged for another token")
+	ErrUnauthenticated  = errors.New("unauthenticated")
+)
+
+func init() {
+	store.ReserveNamespace(TokenKeyNamespace)
+}
+
+// LoadTokenKey returns the replicated token signing key
+func LoadTokenKey(s store.Store) ([]byte, error) {
+	actions, err := s.Swap(TokenKeyNamespace)
+	if err != nil {
+		return nil, err
+	}
+
+	val, err := actions.Get(tokenSigningKey)
+	if err != nil {
+		if errors.Is(err, store.ErrKeyNotFound) {
+			return nil, ErrTokenKeyNotFound
+		}
+		return nil, err
+	}
+	return val.Data, nil
+}
+
+func tokenSignature(key []byte, payload string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// SignToken returns the bearer token of claims, the encoded claims followed by their HMAC-SHA256
+func SignToken(key []byte, claims *serverpb.TokenClaims) (string, error) {
+	b, err := proto.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	payload := base64.RawURLEncoding.EncodeToString(b)
+	return payload + "." + tokenSignature(key, payload), nil
+}
+
+// VerifyToken returns the claims of a token signed with key that has not expired at now
+func VerifyToken(key []byte, token string, now time.Time) (*serverpb.TokenClaims, error) {
+	payload, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(tokenSignature(key, payload))) {
+		return nil, ErrInvalidToken
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	claims := &serverpb.TokenClaims{}
+	if err = proto.Unmarshal(b, claims); err != nil || claims.Username == "" {
+		return nil, ErrInvalidToken
+	}
+	if now.Unix() >= claims.ExpiresAt {
+		return nil, ErrTokenExpired
+	}
+	return claims, nil
+}
+
+// CheckTokenUser rejects the tokens issued before the credentials of their user changed, and the
+// tokens of removed users. the users authenticated by certificate only need a user record when
+// required is set, i.e. the user store backs the basic auth or the rbac
+func CheckTokenUser(s store.Store, claims *serverpb.TokenClaims, required bool) error {
+	user, err := LoadUser(s, claims.Username)
+	switch {
+	case errors.Is(err, ErrUserNotFound):
+		if required {
+			return ErrInvalidToken
+		}
+		return nil
+	case err != nil:
+		return err
+	case claims.IssuedAt < user.CredentialsChangedAt:
+		return ErrTokenRevoked
+	}
+	return nil
+}
+
+// tokenEnabled reports whether the clients are authenticated, the tokens are issued then
+func (s *Server) tokenEnabled() bool {
+	return s.basicAuth != nil || s.clientAuthEnabled()
+}
+
+// ensureTokenKey generates the token signing key once the leadership is gained, unless the
+// cluster already has one
+func (s *Server) ensureTokenKey() {
+	if err := s.raft.Barrier(membershipTimeout).Error(); err != nil {
+		return
+	}
+	if _, err := LoadTokenKey(s.store); !errors.Is(err, ErrTokenKeyNotFound) {
+		return
+	}
+
+	key := make([]byte, tokenKeySize)
+	if _, err := rand.Read(key); err != nil {
+		s.raft.cfg.Logger.Warn("failed to generate token signing key", "error", err)
+		return
+	}
+	err := s.applyLog(s.ctx, &serverpb.RaftLogPayload{
+		Command:   serverpb.RaftLogCommand_TrySet,
+		Key:       tokenSigningKey,
+		Value:     key,
+		Namespace: expr.Pointer(TokenKeyNamespace),
+	}, 500*time.Millisecond)
+	if err != nil && !errors.Is(err, store.ErrKeyAlreadyExists) {
+		s.raft.cfg.Logger.Warn("failed to publish token signing key", "error", err)
+	}
+}
+
+// issueToken signs a token of the user, valid for the configured ttl
+func (s *Server) issueToken(username string) (string, time.Time, error) {
+	key, err := LoadTokenKey(s.store)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(expr.OrDefault(s.cfg.Token.TTL, config.DefaultTokenTTL))
+	token, err := SignToken(key, &serverpb.TokenClaims{
+		Username:  username,
+		IssuedAt:  now.UnixNano(),
+		ExpiresAt: expiresAt.Unix(),
+	})
+	return token, expiresAt, err
+}
+
+// verifyToken implements the TokenAuthFunc of the grpc and http auth
+func (s *Server) verifyToken(token string) (string, bool) {
+	key, err := LoadTokenKey(s.store)
+	if err != nil {
+		return "", false
+	}
+	claims, err := VerifyToken(key, token, time.Now())
+	if err != nil {
+		return "", false
+	}
+	if err = CheckTokenUser(s.store, claims, s.basicAuth != nil || s.cfg.Rbac.Enabled); err != nil {
+		return "", false
+	}
+	return claims.Username, true
+}
+
+// authenticate issues a token of the authenticated user, the tokens can't be renewed by
+// themselves so that a leaked token expires
+func (s *Server) authenticate(username string, authenticated, byToken bool) (string, time.Time, error) {
+	if !authenticated {
+		return "", time.Time{}, ErrUnauthenticated
+	}
+	if byToken {
+		return "", time.Time{}, ErrTokenRenewal
+	}
+	return s.issueToken(username)
+}
+
+// tokenStatus converts the error of authenticate to a grpc status
+func tokenStatus(err error) error {
+	switch {
+	case errors.Is(err, ErrUnauthenticated):
+		return status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, ErrTokenRenewal):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, ErrTokenKeyNotFound):
+		return status.Error(codes.Unavailable, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+// tokenHttpStatus converts the error of authenticate to a http status
+func tokenHttpStatus(err error) error {
+	switch {
+	case errors.Is(err, ErrUnauthenticated):
+		return httputil.StatusWrap(http.StatusUnauthorized, 401, err)
+	case errors.Is(err, ErrTokenRenewal):
+		return httputil.StatusWrap(http.StatusForbidden, 0, err)
+	case errors.Is(err, ErrTokenKeyNotFound):
+		return httputil.StatusWrap(http.StatusServiceUnavailable, 0, err)
+	default:
+		return httputil.StatusWrap(http.StatusInternalServerError, 0, err)
+	}
+}
@@ -0,0 +1,86 @@
+package rqd_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/RealFax/RedQueen/api/serverpb"
+	red "github.com/RealFax/RedQueen/internal/rqd"
+	"github.com/RealFax/RedQueen/pkg/expr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestVerifyToken(t *testing.T) {
+	var (
+		key = []byte("0123456789abcdef0123456789abcdef")
+		now = time.Unix(1700000000, 0)
+	)
+	token, err := red.SignToken(key, &serverpb.TokenClaims{
+		Username:  "alice",
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(time.Minute).Unix(),
+	})
+	require.NoError(t, err)
+
+	claims, err := red.VerifyToken(key, token, now)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", claims.Username)
+
+	_, err = red.VerifyToken(key, token, now.Add(time.Minute))
+	assert.ErrorIs(t, err, red.ErrTokenExpired)
+	_, err = red.VerifyToken([]byte("another key"), token, now)
+	assert.ErrorIs(t, err, red.ErrInvalidToken)
+	_, err = red.VerifyToken(key, "x"+token, now)
+	assert.ErrorIs(t, err, red.ErrInvalidToken)
+	_, err = red.VerifyToken(key, "invalid", now)
+	assert.ErrorIs(t, err, red.ErrInvalidToken)
+}
+
+func TestLoadTokenKey(t *testing.T) {
+	var (
+		db       = newTestStore(t)
+		handlers = red.NewFSMHandlers(db)
+	)
+
+	_, err := red.LoadTokenKey(db)
+	assert.ErrorIs(t, err, red.ErrTokenKeyNotFound)
+
+	require.NoError(t, handlers[serverpb.RaftLogCommand_TrySet](&serverpb.RaftLogPayload{
+		Command:   serverpb.RaftLogCommand_TrySet,
+		Key:       []byte("signing-key"),
+		Value:     []byte("key"),
+		Namespace: expr.Pointer(red.TokenKeyNamespace),
+	}))
+	key, err := red.LoadTokenKey(db)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("key"), key)
+}
+
+func TestCheckTokenUser(t *testing.T) {
+	var (
+		db       = newTestStore(t)
+		handlers = red.NewFSMHandlers(db)
+		now      = time.Now()
+		claims   = &serverpb.TokenClaims{Username: "alice", IssuedAt: now.UnixNano()}
+	)
+
+	// the users authenticated by certificate have no record without the user store
+	assert.NoError(t, red.CheckTokenUser(db, claims, false))
+	assert.ErrorIs(t, red.CheckTokenUser(db, claims, true), red.ErrInvalidToken)
+
+	require.NoError(t, red.StoreUser(db, "alice", &serverpb.User{Name: "alice", CredentialsChangedAt: now.Add(-time.Minute).UnixNano()}))
+	assert.NoError(t, red.CheckTokenUser(db, claims, true))
+
+	// the password change revokes the tokens issued before it
+	credentials, err := proto.Marshal(&serverpb.User{PasswordHash: []byte("hash"), CredentialsChangedAt: now.Add(time.Second).UnixNano()})
+	require.NoError(t, err)
+	require.NoError(t, handlers[serverpb.RaftLogCommand_AuthUserPassword](&serverpb.RaftLogPayload{
+		Command: serverpb.RaftLogCommand_AuthUserPassword,
+		Key:     []byte("alice"),
+		Value:   credentials,
+	}))
+	assert.ErrorIs(t, red.CheckTokenUser(db, claims, true), red.ErrTokenRevoked)
+	assert.NoError(t, red.CheckTokenUser(db, &serverpb.TokenClaims{Username: "alice", IssuedAt: now.Add(2 * time.Second).UnixNano()}, true))
+}
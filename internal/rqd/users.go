@@ -0,0 +1,161 @@
+package rqd
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/RealFax/RedQueen/api/serverpb"
+	"github.com/RealFax/RedQueen/internal/rqd/store"
+	"github.com/RealFax/RedQueen/pkg/expr"
+	"github.com/hashicorp/raft"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/protobuf/proto"
+)
+
+// HashPassword returns the bcrypt hash of the password stored in the user record
+func HashPassword(password string) ([]byte, error) {
+	return bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+}
+
+// verifiedPassword is a password verified against hash, the later calls skip bcrypt
+type verifiedPassword struct {
+	hash   []byte
+	digest [sha256.Size]byte
+}
+
+// UserAuth authenticates the basic auth users against the password hashes of the replicated
+// user store. the users of the config are only a seed, the leader adds each of them once
+// (see Server.seedUsers)
+type UserAuth struct {
+	store store.Store
+	seed  atomic.Pointer[map[string]string]
+	// verified caches the last password verified per user, bcrypt is slow on purpose
+	verified sync.Map
+}
+
+// Auth implements the BasicAuthFunc of the grpc and http auth
+func (a *UserAuth) Auth(username, password string) bool {
+	user, err := LoadUser(a.store, username)
+	if err != nil || len(user.PasswordHash) == 0 {
+		return false
+	}
+
+	digest := sha256.Sum256([]byte(password))
+	if v, ok := a.verified.Load(username); ok {
+		cached := v.(verifiedPassword)
+		if subtle.ConstantTimeCompare(cached.hash, user.PasswordHash) == 1 &&
+			subtle.ConstantTimeCompare(cached.digest[:], digest[:]) == 1 {
+			return true
+		}
+	}
+
+	if bcrypt.CompareHashAndPassword(user.PasswordHash, []byte(password)) != nil {
+		return false
+	}
+	a.verified.Store(username, verifiedPassword{hash: user.PasswordHash, digest: digest})
+	return true
+}
+
+// Seed returns the users of the config
+func (a *UserAuth) Seed() map[string]string {
+	return *a.seed.Load()
+}
+
+// StoreSeed replaces the users of the config
+func (a *UserAuth) StoreSeed(users map[string]string) {
+	a.seed.Store(&users)
+}
+
+func NewUserAuth(s store.Store, seed map[string]string) *UserAuth {
+	a := &UserAuth{store: s}
+	a.StoreSeed(seed)
+	return a
+}
+
+// AuthSeedNamespace records the users of the config already seeded, keyed by name. a user is
+// only seeded once, it isn't restored once deleted or its password cleared
+const AuthSeedNamespace = "_AuthSeed"
+
+func init() {
+	store.ReserveNamespace(AuthSeedNamespace)
+}
+
+// LoadSeeded reports whether the user name of the config was seeded
+func LoadSeeded(s store.Store, name string) (bool, error) {
+	actions, err := s.Swap(AuthSeedNamespace)
+	if err != nil {
+		return false, err
+	}
+
+	if _, err = actions.Get([]byte(name)); err != nil {
+		if errors.Is(err, store.ErrKeyNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// seedUsers adds the users of the config that were never seeded. the users already in the user
+// store are kept as they are, the seeded users are never touched again
+func (s *Server) seedUsers() {
+	if s.raft.State() != raft.Leader {
+		return
+	}
+	if err := s.raft.Barrier(membershipTimeout).Error(); err != nil {
+		return
+	}
+
+	logger := s.raft.cfg.Logger.Named("users")
+	for name, password := range s.basicAuth.Seed() {
+		seeded, err := LoadSeeded(s.store, name)
+		if err != nil {
+			logger.Warn("failed to load seed", "user", name, "error", err)
+			continue
+		}
+		if seeded {
+			continue
+		}
+
+		// the fsm only creates the user when it doesn't exist, a user added meanwhile is kept
+		if err = s.seedUser(name, password); err == nil {
+			logger.Info("user seeded", "user", name)
+		} else if !errors.Is(err, ErrUserAlreadyExists) {
+			logger.Warn("failed to seed user", "user", name, "error", err)
+			continue
+		}
+
+		// the user is marked once it exists, a seed interrupted before is completed
+		if err = s.applyLog(s.ctx, &serverpb.RaftLogPayload{
+			Command:   serverpb.RaftLogCommand_Set,
+			Key:       []byte(name),
+			Value:     []byte("1"),
+			Namespace: expr.Pointer(AuthSeedNamespace),
+		}, 500*time.Millisecond); err != nil {
+			logger.Warn("failed to mark user seeded", "user", name, "error", err)
+		}
+	}
+}
+
+func (s *Server) seedUser(name, password string) error {
+	hash, err := HashPassword(password)
+	if err != nil {
+		return err
+	}
+	return s.createUser(s.ctx, name, &serverpb.User{
+		Name:                 name,
+		PasswordHash:         hash,
+		CredentialsChangedAt: time.Now().UnixNano(),
+	})
+}
+
+// publicUser returns the user without its password hash
+func publicUser(user *serverpb.User) *serverpb.User {
+	user = proto.Clone(user).(*serverpb.User)
+	user.PasswordHash = nil
+	return user
+}
@@ -0,0 +1,67 @@
+package rqd_test
+
+import (
+	"testing"
+
+	"github.com/RealFax/RedQueen/api/serverpb"
+	red "github.com/RealFax/RedQueen/internal/rqd"
+	"github.com/RealFax/RedQueen/internal/rqd/store"
+	"github.com/RealFax/RedQueen/pkg/expr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserAuth(t *testing.T) {
+	var (
+		db   = newTestStore(t)
+		auth = red.NewUserAuth(db, map[string]string{"root": "toor"})
+	)
+
+	// the seed doesn't authenticate by itself
+	assert.False(t, auth.Auth("root", "toor"))
+
+	hash, err := red.HashPassword("toor")
+	require.NoError(t, err)
+	require.NoError(t, red.StoreUser(db, "root", &serverpb.User{Name: "root", PasswordHash: hash}))
+	require.NoError(t, red.StoreUser(db, "cert", &serverpb.User{Name: "cert"}))
+
+	assert.True(t, auth.Auth("root", "toor"))
+	// verified again from the cache
+	assert.True(t, auth.Auth("root", "toor"))
+	assert.False(t, auth.Auth("root", "wrong"))
+	// users without a password never pass the basic auth
+	assert.False(t, auth.Auth("cert", ""))
+	assert.False(t, auth.Auth("unknown", "toor"))
+
+	// a changed password replaces the cached one
+	hash, err = red.HashPassword("changed")
+	require.NoError(t, err)
+	require.NoError(t, red.StoreUser(db, "root", &serverpb.User{Name: "root", PasswordHash: hash}))
+	assert.False(t, auth.Auth("root", "toor"))
+	assert.True(t, auth.Auth("root", "changed"))
+
+	auth.StoreSeed(map[string]string{"admin": "123456"})
+	assert.Equal(t, map[string]string{"admin": "123456"}, auth.Seed())
+}
+
+func TestLoadSeeded(t *testing.T) {
+	var (
+		db       = newTestStore(t)
+		handlers = red.NewFSMHandlers(db)
+	)
+	require.True(t, store.IsReservedNamespace(red.AuthSeedNamespace))
+
+	seeded, err := red.LoadSeeded(db, "root")
+	require.NoError(t, err)
+	assert.False(t, seeded)
+
+	require.NoError(t, handlers[serverpb.RaftLogCommand_Set](&serverpb.RaftLogPayload{
+		Command:   serverpb.RaftLogCommand_Set,
+		Key:       []byte("root"),
+		Value:     []byte("1"),
+		Namespace: expr.Pointer(red.AuthSeedNamespace),
+	}))
+	seeded, err = red.LoadSeeded(db, "root")
+	require.NoError(t, err)
+	assert.True(t, seeded)
+}
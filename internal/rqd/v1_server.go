@@ -8,17 +8,21 @@ import (
 	"github.com/RealFax/RedQueen/pkg/dlocker"
 	"github.com/RealFax/RedQueen/pkg/expr"
 	"github.com/RealFax/RedQueen/pkg/fs"
+	"github.com/RealFax/RedQueen/pkg/grpcutil"
 	"github.com/RealFax/RedQueen/pkg/httputil"
 	"github.com/google/uuid"
 	"github.com/hashicorp/raft"
 	"github.com/julienschmidt/httprouter"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/emptypb"
 	"io"
 	"net/http"
+	"slices"
 	"strconv"
 	"time"
 
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
 	"github.com/RealFax/RedQueen/api/serverpb"
@@ -26,12 +30,84 @@ import (
 
 // ---- grpc handler ----
 
+// applyStatus converts the error of an applied raft log to a grpc status
+func applyStatus(err error) error {
+	switch {
+	case errors.Is(err, ErrApplyLogClosed):
+		return status.Error(codes.Unavailable, ErrServerGoingAway.Error())
+	case errors.Is(err, ErrQuotaExceeded):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, store.ErrKeyAlreadyExists):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, store.ErrNamespaceNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+// membershipStatus converts the error of a membership change to a grpc status, changes
+// rejected by raft (e.g. on a follower) stay PermissionDenied
+func membershipStatus(err error) error {
+	switch {
+	case errors.Is(err, ErrServerNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, ErrServerNotVoter), errors.Is(err, ErrServerIsVoter):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+}
+
+// memberList converts the raft members to the MemberList response
+func (s *Server) memberList() (*serverpb.MemberListResponse, error) {
+	members, err := s.raft.Members()
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &serverpb.MemberListResponse{
+		Members:  make([]*serverpb.RaftMember, len(members)),
+		ServerId: string(s.raft.cfg.LocalID),
+		Term:     s.raft.Term(),
+	}
+	for i, member := range members {
+		m := &serverpb.RaftMember{
+			ServerId: string(member.ID),
+			Address:  string(member.Address),
+			// RaftSuffrage follows the order of raft.ServerSuffrage
+			Suffrage:     serverpb.RaftSuffrage(member.Suffrage),
+			Leader:       member.Leader,
+			CommitIndex:  member.CommitIndex,
+			LastLogIndex: member.LastLogIndex,
+		}
+		if !member.LastContact.IsZero() {
+			m.LastContact = member.LastContact.UnixMilli()
+		}
+		if member.ID == s.raft.cfg.LocalID {
+			m.AppliedIndex = &member.AppliedIndex
+			m.ClientAddr = s.cfg.Node.ClientAddr()
+		} else if m.ClientAddr, err = LoadClientAddr(s.store, member.ID); err != nil {
+			return nil, err
+		}
+		if s.autopilot != nil {
+			if healthy, ok := s.autopilot.Healthy(member.ID); ok {
+				m.Healthy = &healthy
+			}
+		}
+		resp.Members[i] = m
+	}
+	return resp, nil
+}
+
 type v1RPCServer struct {
 	*Server
 
 	serverpb.UnimplementedKVServer
 	serverpb.UnimplementedLockerServer
 	serverpb.UnimplementedRedQueenServer
+	serverpb.UnimplementedNamespaceServer
+	serverpb.UnimplementedAuthServer
 }
 
 func (s *v1RPCServer) responseHeader() *serverpb.ResponseHeader {
@@ -41,7 +117,26 @@ func (s *v1RPCServer) responseHeader() *serverpb.ResponseHeader {
 	}
 }
 
+// reservedAccess reports whether the request opted in to access reserved namespaces as a reserved admin
+func (s *v1RPCServer) reservedAccess(ctx context.Context) bool {
+	md, _ := metadata.FromIncomingContext(ctx)
+	optIn := md.Get(MetadataReservedAccess)
+	username, ok := grpcutil.UserFromContext(ctx)
+	return ok && len(optIn) == 1 && optIn[0] == "true" && s.allowReserved(username)
+}
+
+func (s *v1RPCServer) checkReserved(ctx context.Context, namespace *string) error {
+	if namespace == nil || !store.IsReservedNamespace(*namespace) || s.reservedAccess(ctx) {
+		return nil
+	}
+	return status.Error(codes.PermissionDenied, store.ErrReservedNamespace.Error())
+}
+
 func (s *v1RPCServer) Set(ctx context.Context, req *serverpb.SetRequest) (*serverpb.SetResponse, error) {
+	if err := s.checkReserved(ctx, req.Namespace); err != nil {
+		return nil, err
+	}
+
 	if err := s.applyLog(ctx, &serverpb.RaftLogPayload{
 		Command:   expr.If(req.IgnoreTtl, serverpb.RaftLogCommand_Set, serverpb.RaftLogCommand_SetWithTTL),
 		Key:       req.Key,
@@ -49,30 +144,65 @@ func (s *v1RPCServer) Set(ctx context.Context, req *serverpb.SetRequest) (*serve
 		Ttl:       expr.Pointer(req.Ttl),
 		Namespace: req.Namespace,
 	}, 500*time.Millisecond); err != nil {
-		return nil, status.Error(codes.Internal, err.Error())
+		return nil, applyStatus(err)
 	}
 	return &serverpb.SetResponse{Header: s.responseHeader()}, nil
 }
 
-func (s *v1RPCServer) Get(_ context.Context, req *serverpb.GetRequest) (*serverpb.GetResponse, error) {
+func (s *v1RPCServer) Get(ctx context.Context, req *serverpb.GetRequest) (*serverpb.GetResponse, error) {
+	if err := s.checkReserved(ctx, req.Namespace); err != nil {
+		return nil, err
+	}
+
 	act, err := s.trySwapContext(req.Namespace)
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
-	value, err := act.Get(req.Key)
+	var value *store.Value
+	if req.Revision != nil {
+		value, err = act.GetRevision(req.Key, req.GetRevision())
+	} else {
+		value, err = act.Get(req.Key)
+	}
 	if err != nil {
 		return nil, status.Error(codes.NotFound, err.Error())
 	}
 
 	return &serverpb.GetResponse{
-		Header: s.responseHeader(),
-		Value:  value.Data,
-		Ttl:    value.TTL,
+		Header:   s.responseHeader(),
+		Value:    value.Data,
+		Ttl:      value.TTL,
+		Revision: value.Revision,
 	}, nil
 }
 
-func (s *v1RPCServer) PrefixScan(_ context.Context, req *serverpb.PrefixScanRequest) (*serverpb.PrefixScanResponse, error) {
+func (s *v1RPCServer) History(ctx context.Context, req *serverpb.HistoryRequest) (*serverpb.HistoryResponse, error) {
+	if err := s.checkReserved(ctx, req.Namespace); err != nil {
+		return nil, err
+	}
+
+	act, err := s.trySwapContext(req.Namespace)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	versions, err := act.History(req.Key, int(req.Limit))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &serverpb.HistoryResponse{
+		Header:   s.responseHeader(),
+		Versions: historyVersions(versions),
+	}, nil
+}
+
+func (s *v1RPCServer) PrefixScan(ctx context.Context, req *serverpb.PrefixScanRequest) (*serverpb.PrefixScanResponse, error) {
+	if err := s.checkReserved(ctx, req.Namespace); err != nil {
+		return nil, err
+	}
+
 	act, err := s.trySwapContext(req.Namespace)
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
@@ -101,6 +231,10 @@ func (s *v1RPCServer) PrefixScan(_ context.Context, req *serverpb.PrefixScanRequ
 }
 
 func (s *v1RPCServer) TrySet(ctx context.Context, req *serverpb.SetRequest) (*serverpb.SetResponse, error) {
+	if err := s.checkReserved(ctx, req.Namespace); err != nil {
+		return nil, err
+	}
+
 	if err := s.applyLog(ctx, &serverpb.RaftLogPayload{
 		Command:   expr.If(req.IgnoreTtl, serverpb.RaftLogCommand_TrySet, serverpb.RaftLogCommand_TrySetWithTTL),
 		Key:       req.Key,
@@ -108,25 +242,41 @@ func (s *v1RPCServer) TrySet(ctx context.Context, req *serverpb.SetRequest) (*se
 		Ttl:       &req.Ttl,
 		Namespace: req.Namespace,
 	}, 500*time.Millisecond); err != nil {
-		return nil, status.Error(codes.Internal, err.Error())
+		return nil, applyStatus(err)
 	}
 
 	return &serverpb.SetResponse{Header: s.responseHeader()}, nil
 }
 
 func (s *v1RPCServer) Delete(ctx context.Context, req *serverpb.DeleteRequest) (*serverpb.DeleteResponse, error) {
+	if err := s.checkReserved(ctx, req.Namespace); err != nil {
+		return nil, err
+	}
+
 	if err := s.applyLog(ctx, &serverpb.RaftLogPayload{
 		Command:   serverpb.RaftLogCommand_Del,
 		Key:       req.Key,
 		Namespace: req.Namespace,
 	}, 500*time.Millisecond); err != nil {
-		return nil, status.Error(codes.Internal, err.Error())
+		return nil, applyStatus(err)
 	}
 
 	return &serverpb.DeleteResponse{Header: s.responseHeader()}, nil
 }
 
+// sendGoingAway tells a watcher that the server shuts down, so that it resumes on another member
+func (s *v1RPCServer) sendGoingAway(stream interface {
+	Send(*serverpb.WatchResponse) error
+}) error {
+	_ = stream.Send(&serverpb.WatchResponse{Header: s.responseHeader(), GoingAway: true})
+	return status.Error(codes.Unavailable, ErrServerGoingAway.Error())
+}
+
 func (s *v1RPCServer) Watch(req *serverpb.WatchRequest, stream serverpb.KV_WatchServer) error {
+	if err := s.checkReserved(stream.Context(), req.Namespace); err != nil {
+		return err
+	}
+
 	storeAPI, err := s.trySwapContext(req.Namespace)
 	if err != nil {
 		return status.Error(codes.Internal, err.Error())
@@ -145,7 +295,14 @@ func (s *v1RPCServer) Watch(req *serverpb.WatchRequest, stream serverpb.KV_Watch
 	defer watcher.Close()
 
 	for {
-		value := <-watcher.Notify()
+		var value *store.WatchValue
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case <-s.goingAway:
+			return s.sendGoingAway(stream)
+		case value = <-watcher.Notify():
+		}
 		if value.Deleted() && !req.IgnoreErrors {
 			return status.Error(codes.Unavailable, "key has deleted")
 		}
@@ -170,6 +327,10 @@ func (s *v1RPCServer) Watch(req *serverpb.WatchRequest, stream serverpb.KV_Watch
 }
 
 func (s *v1RPCServer) WatchPrefix(req *serverpb.WatchPrefixRequest, stream serverpb.KV_WatchPrefixServer) error {
+	if err := s.checkReserved(stream.Context(), req.Namespace); err != nil {
+		return err
+	}
+
 	storeAPI, err := s.trySwapContext(req.Namespace)
 	if err != nil {
 		return status.Error(codes.Internal, err.Error())
@@ -179,7 +340,14 @@ func (s *v1RPCServer) WatchPrefix(req *serverpb.WatchPrefixRequest, stream serve
 	defer watcher.Close()
 
 	for {
-		value := <-watcher.Notify()
+		var value *store.WatchValue
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case <-s.goingAway:
+			return s.sendGoingAway(stream)
+		case value = <-watcher.Notify():
+		}
 		if err = stream.Send(&serverpb.WatchResponse{
 			Header:    s.responseHeader(),
 			UpdateSeq: value.Seq,
@@ -220,13 +388,61 @@ func (s *v1RPCServer) TryLock(_ context.Context, req *serverpb.TryLockRequest) (
 	return &serverpb.TryLockResponse{Header: s.responseHeader()}, nil
 }
 
-func (s *v1RPCServer) AppendCluster(_ context.Context, req *serverpb.AppendClusterRequest) (*serverpb.AppendClusterResponse, error) {
-	if err := s.raft.AddCluster(raft.ServerID(req.ServerId), raft.ServerAddress(req.PeerAddr)); err != nil {
-		return nil, status.Error(codes.PermissionDenied, err.Error())
+func (s *v1RPCServer) AppendCluster(ctx context.Context, req *serverpb.AppendClusterRequest) (*serverpb.AppendClusterResponse, error) {
+	staged := req.Voter && s.stagedJoin()
+	if err := s.raft.AddCluster(raft.ServerID(req.ServerId), raft.ServerAddress(req.PeerAddr), req.Voter && !staged); err != nil {
+		return nil, membershipStatus(err)
+	}
+	if staged {
+		if err := s.markPromotion(ctx, raft.ServerID(req.ServerId), true); err != nil {
+			return nil, applyStatus(err)
+		}
+	}
+	if req.ClientAddr != nil {
+		if err := s.publishClientAddr(ctx, raft.ServerID(req.ServerId), *req.ClientAddr); err != nil {
+			return nil, applyStatus(err)
+		}
 	}
 	return &serverpb.AppendClusterResponse{}, nil
 }
 
+func (s *v1RPCServer) RemoveCluster(ctx context.Context, req *serverpb.RemoveClusterRequest) (*serverpb.RemoveClusterResponse, error) {
+	if err := s.raft.RemoveCluster(raft.ServerID(req.ServerId)); err != nil {
+		return nil, membershipStatus(err)
+	}
+	if err := s.markPromotion(ctx, raft.ServerID(req.ServerId), false); err != nil {
+		return nil, applyStatus(err)
+	}
+	if err := s.forgetClientAddr(ctx, raft.ServerID(req.ServerId)); err != nil {
+		return nil, applyStatus(err)
+	}
+	return &serverpb.RemoveClusterResponse{}, nil
+}
+
+func (s *v1RPCServer) DemoteVoter(_ context.Context, req *serverpb.DemoteVoterRequest) (*serverpb.DemoteVoterResponse, error) {
+	if err := s.raft.DemoteCluster(raft.ServerID(req.ServerId)); err != nil {
+		return nil, membershipStatus(err)
+	}
+	return &serverpb.DemoteVoterResponse{}, nil
+}
+
+func (s *v1RPCServer) PromoteLearner(ctx context.Context, req *serverpb.PromoteLearnerRequest) (*serverpb.PromoteLearnerResponse, error) {
+	if err := s.raft.PromoteCluster(raft.ServerID(req.ServerId)); err != nil {
+		return nil, membershipStatus(err)
+	}
+	if err := s.markPromotion(ctx, raft.ServerID(req.ServerId), false); err != nil {
+		return nil, applyStatus(err)
+	}
+	return &serverpb.PromoteLearnerResponse{}, nil
+}
+
+func (s *v1RPCServer) LeadershipTransfer(_ context.Context, req *serverpb.LeadershipTransferRequest) (*serverpb.LeadershipTransferResponse, error) {
+	if err := s.raft.TransferLeadership(raft.ServerID(req.GetServerId())); err != nil {
+		return nil, membershipStatus(err)
+	}
+	return &serverpb.LeadershipTransferResponse{}, nil
+}
+
 func (s *v1RPCServer) LeaderMonitor(_ *serverpb.LeaderMonitorRequest, stream serverpb.RedQueen_LeaderMonitorServer) error {
 	var (
 		notifyID = uuid.New().String()
@@ -234,20 +450,33 @@ func (s *v1RPCServer) LeaderMonitor(_ *serverpb.LeaderMonitorRequest, stream ser
 	)
 	s.stateNotify.Store(notifyID, notify)
 
-	defer func() {
-		s.stateNotify.Delete(notifyID)
-		close(notify)
-	}()
-	s.raft.Stats()
+	// notify is never closed, stateUpdater may still be sending to it
+	defer s.stateNotify.Delete(notifyID)
 	for {
+		var leader bool
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case <-s.goingAway:
+			return status.Error(codes.Unavailable, ErrServerGoingAway.Error())
+		case leader = <-notify:
+		}
 		if err := stream.Send(&serverpb.LeaderMonitorResponse{
-			Leader: <-notify,
+			Leader: leader,
 		}); err != nil {
 			return err
 		}
 	}
 }
 
+func (s *v1RPCServer) MemberList(_ context.Context, _ *emptypb.Empty) (*serverpb.MemberListResponse, error) {
+	resp, err := s.memberList()
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	return resp, nil
+}
+
 func (s *v1RPCServer) RaftState(_ context.Context, _ *emptypb.Empty) (*serverpb.RaftStateResponse, error) {
 	var state serverpb.RaftState
 	switch s.raft.Stats()["state"] {
@@ -294,8 +523,323 @@ func (s *v1RPCServer) RaftSnapshot(_ context.Context, req *serverpb.RaftSnapshot
 	return &emptypb.Empty{}, nil
 }
 
+func (s *v1RPCServer) List(ctx context.Context, _ *serverpb.NamespaceListRequest) (*serverpb.NamespaceListResponse, error) {
+	namespaces, err := s.store.Namespaces()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &serverpb.NamespaceListResponse{
+		Header:     s.responseHeader(),
+		Namespaces: s.filterReserved(namespaces, s.reservedAccess(ctx)),
+	}, nil
+}
+
+func (s *v1RPCServer) Create(ctx context.Context, req *serverpb.NamespaceCreateRequest) (*serverpb.NamespaceCreateResponse, error) {
+	if err := s.checkReserved(ctx, &req.Namespace); err != nil {
+		return nil, err
+	}
+
+	if err := s.applyLog(ctx, &serverpb.RaftLogPayload{
+		Command:   serverpb.RaftLogCommand_NamespaceCreate,
+		Namespace: &req.Namespace,
+	}, 500*time.Millisecond); err != nil {
+		return nil, applyStatus(err)
+	}
+	return &serverpb.NamespaceCreateResponse{Header: s.responseHeader()}, nil
+}
+
+func (s *v1RPCServer) Drop(ctx context.Context, req *serverpb.NamespaceDropRequest) (*serverpb.NamespaceDropResponse, error) {
+	if err := s.checkReserved(ctx, &req.Namespace); err != nil {
+		return nil, err
+	}
+
+	if err := s.applyLog(ctx, &serverpb.RaftLogPayload{
+		Command:   serverpb.RaftLogCommand_NamespaceDrop,
+		Namespace: &req.Namespace,
+	}, 500*time.Millisecond); err != nil {
+		return nil, applyStatus(err)
+	}
+	return &serverpb.NamespaceDropResponse{Header: s.responseHeader()}, nil
+}
+
+func (s *v1RPCServer) Stats(ctx context.Context, req *serverpb.NamespaceStatsRequest) (*serverpb.NamespaceStatsResponse, error) {
+	if err := s.checkReserved(ctx, &req.Namespace); err != nil {
+		return nil, err
+	}
+
+	stats, err := s.store.NamespaceStats(req.Namespace)
+	if err != nil {
+		if errors.Is(err, store.ErrNamespaceNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &serverpb.NamespaceStatsResponse{
+		Header:    s.responseHeader(),
+		Namespace: stats.Namespace,
+		Keys:      stats.Keys,
+		Bytes:     stats.Bytes,
+	}, nil
+}
+
+func (s *v1RPCServer) GetQuota(ctx context.Context, req *serverpb.NamespaceGetQuotaRequest) (*serverpb.NamespaceGetQuotaResponse, error) {
+	if err := s.checkReserved(ctx, &req.Namespace); err != nil {
+		return nil, err
+	}
+
+	quota, err := LoadQuota(s.store, req.Namespace)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &serverpb.NamespaceGetQuotaResponse{
+		Header: s.responseHeader(),
+		Quota:  expr.If(quota == nil, &serverpb.Quota{}, quota),
+	}, nil
+}
+
+func (s *v1RPCServer) SetQuota(ctx context.Context, req *serverpb.NamespaceSetQuotaRequest) (*serverpb.NamespaceSetQuotaResponse, error) {
+	if err := s.checkReserved(ctx, &req.Namespace); err != nil {
+		return nil, err
+	}
+
+	quota, err := proto.Marshal(req.Quota)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err = s.applyLog(ctx, &serverpb.RaftLogPayload{
+		Command:   serverpb.RaftLogCommand_NamespaceQuota,
+		Value:     quota,
+		Namespace: &req.Namespace,
+	}, 500*time.Millisecond); err != nil {
+		return nil, applyStatus(err)
+	}
+	return &serverpb.NamespaceSetQuotaResponse{Header: s.responseHeader()}, nil
+}
+
+func (s *v1RPCServer) Authenticate(ctx context.Context, _ *serverpb.AuthenticateRequest) (*serverpb.AuthenticateResponse, error) {
+	username, ok := grpcutil.UserFromContext(ctx)
+	token, expiresAt, err := s.authenticate(username, ok, grpcutil.TokenFromContext(ctx))
+	if err != nil {
+		return nil, tokenStatus(err)
+	}
+	return &serverpb.AuthenticateResponse{
+		Header:    s.responseHeader(),
+		Token:     token,
+		ExpiresAt: expiresAt.Unix(),
+	}, nil
+}
+
+func (s *v1RPCServer) UserAdd(ctx context.Context, req *serverpb.UserAddRequest) (*serverpb.UserAddResponse, error) {
+	if req.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "user name should not be empty")
+	}
+
+	user := &serverpb.User{Name: req.Name, CredentialsChangedAt: time.Now().UnixNano()}
+	if req.Password != "" {
+		hash, err := HashPassword(req.Password)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		user.PasswordHash = hash
+	}
+
+	if err := s.createUser(ctx, req.Name, user); err != nil {
+		return nil, rbacStatus(err)
+	}
+	return &serverpb.UserAddResponse{Header: s.responseHeader()}, nil
+}
+
+func (s *v1RPCServer) UserDelete(ctx context.Context, req *serverpb.UserDeleteRequest) (*serverpb.UserDeleteResponse, error) {
+	if _, err := LoadUser(s.store, req.Name); err != nil {
+		return nil, rbacStatus(err)
+	}
+
+	if err := s.applyUser(ctx, req.Name, nil); err != nil {
+		return nil, applyStatus(err)
+	}
+	return &serverpb.UserDeleteResponse{Header: s.responseHeader()}, nil
+}
+
+func (s *v1RPCServer) UserChangePassword(ctx context.Context, req *serverpb.UserChangePasswordRequest) (*serverpb.UserChangePasswordResponse, error) {
+	if _, err := LoadUser(s.store, req.Name); err != nil {
+		return nil, rbacStatus(err)
+	}
+
+	credentials := &serverpb.User{CredentialsChangedAt: time.Now().UnixNano()}
+	if req.Password != "" {
+		var err error
+		if credentials.PasswordHash, err = HashPassword(req.Password); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+	b, err := proto.Marshal(credentials)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if err = s.applyAuthUpdate(ctx, serverpb.RaftLogCommand_AuthUserPassword, req.Name, b); err != nil {
+		return nil, rbacStatus(err)
+	}
+	return &serverpb.UserChangePasswordResponse{Header: s.responseHeader()}, nil
+}
+
+func (s *v1RPCServer) UserGet(_ context.Context, req *serverpb.UserGetRequest) (*serverpb.UserGetResponse, error) {
+	user, err := LoadUser(s.store, req.Name)
+	if err != nil {
+		return nil, rbacStatus(err)
+	}
+	return &serverpb.UserGetResponse{Header: s.responseHeader(), User: publicUser(user)}, nil
+}
+
+func (s *v1RPCServer) UserList(_ context.Context, _ *serverpb.UserListRequest) (*serverpb.UserListResponse, error) {
+	users, err := ListUsers(s.store)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &serverpb.UserListResponse{Header: s.responseHeader(), Users: users}, nil
+}
+
+func (s *v1RPCServer) UserGrantRole(ctx context.Context, req *serverpb.UserGrantRoleRequest) (*serverpb.UserGrantRoleResponse, error) {
+	if _, err := LoadUser(s.store, req.User); err != nil {
+		return nil, rbacStatus(err)
+	}
+	if _, err := LoadRole(s.store, req.Role); err != nil {
+		return nil, rbacStatus(err)
+	}
+
+	if err := s.applyAuthUpdate(ctx, serverpb.RaftLogCommand_AuthUserGrantRole, req.User, []byte(req.Role)); err != nil {
+		return nil, rbacStatus(err)
+	}
+	return &serverpb.UserGrantRoleResponse{Header: s.responseHeader()}, nil
+}
+
+func (s *v1RPCServer) UserRevokeRole(ctx context.Context, req *serverpb.UserRevokeRoleRequest) (*serverpb.UserRevokeRoleResponse, error) {
+	user, err := LoadUser(s.store, req.User)
+	if err != nil {
+		return nil, rbacStatus(err)
+	}
+
+	if slices.Contains(user.Roles, req.Role) {
+		if err = s.applyAuthUpdate(ctx, serverpb.RaftLogCommand_AuthUserRevokeRole, req.User, []byte(req.Role)); err != nil {
+			return nil, rbacStatus(err)
+		}
+	}
+	return &serverpb.UserRevokeRoleResponse{Header: s.responseHeader()}, nil
+}
+
+func (s *v1RPCServer) RoleAdd(ctx context.Context, req *serverpb.RoleAddRequest) (*serverpb.RoleAddResponse, error) {
+	if req.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "role name should not be empty")
+	}
+
+	if err := s.createRole(ctx, req.Name, &serverpb.Role{Name: req.Name}); err != nil {
+		return nil, rbacStatus(err)
+	}
+	return &serverpb.RoleAddResponse{Header: s.responseHeader()}, nil
+}
+
+// RoleDelete removes the role, the users holding it lose its grants
+func (s *v1RPCServer) RoleDelete(ctx context.Context, req *serverpb.RoleDeleteRequest) (*serverpb.RoleDeleteResponse, error) {
+	if _, err := LoadRole(s.store, req.Name); err != nil {
+		return nil, rbacStatus(err)
+	}
+
+	if err := s.applyRole(ctx, req.Name, nil); err != nil {
+		return nil, applyStatus(err)
+	}
+	return &serverpb.RoleDeleteResponse{Header: s.responseHeader()}, nil
+}
+
+func (s *v1RPCServer) RoleGet(_ context.Context, req *serverpb.RoleGetRequest) (*serverpb.RoleGetResponse, error) {
+	role, err := LoadRole(s.store, req.Name)
+	if err != nil {
+		return nil, rbacStatus(err)
+	}
+	return &serverpb.RoleGetResponse{Header: s.responseHeader(), Role: role}, nil
+}
+
+func (s *v1RPCServer) RoleList(_ context.Context, _ *serverpb.RoleListRequest) (*serverpb.RoleListResponse, error) {
+	roles, err := ListRoles(s.store)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &serverpb.RoleListResponse{Header: s.responseHeader(), Roles: roles}, nil
+}
+
+func (s *v1RPCServer) RoleGrantPermission(ctx context.Context, req *serverpb.RoleGrantPermissionRequest) (*serverpb.RoleGrantPermissionResponse, error) {
+	if req.Grant == nil || len(req.Grant.Permissions) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "grant should hold a permission")
+	}
+	for _, perm := range req.Grant.Permissions {
+		if _, ok := serverpb.Permission_name[int32(perm)]; !ok {
+			return nil, status.Errorf(codes.InvalidArgument, "unknown permission %d", perm)
+		}
+	}
+
+	if _, err := LoadRole(s.store, req.Role); err != nil {
+		return nil, rbacStatus(err)
+	}
+	grant, err := proto.Marshal(req.Grant)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err = s.applyAuthUpdate(ctx, serverpb.RaftLogCommand_AuthRoleGrantPermission, req.Role, grant); err != nil {
+		return nil, rbacStatus(err)
+	}
+	return &serverpb.RoleGrantPermissionResponse{Header: s.responseHeader()}, nil
+}
+
+func (s *v1RPCServer) RoleRevokePermission(ctx context.Context, req *serverpb.RoleRevokePermissionRequest) (*serverpb.RoleRevokePermissionResponse, error) {
+	role, err := LoadRole(s.store, req.Role)
+	if err != nil {
+		return nil, rbacStatus(err)
+	}
+	if grantIndex(role.Grants, req.Namespace, req.Prefix) == -1 {
+		return nil, rbacStatus(ErrGrantNotFound)
+	}
+
+	grant, err := proto.Marshal(&serverpb.Grant{Namespace: req.Namespace, Prefix: req.Prefix})
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err = s.applyAuthUpdate(ctx, serverpb.RaftLogCommand_AuthRoleRevokePermission, req.Role, grant); err != nil {
+		return nil, rbacStatus(err)
+	}
+	return &serverpb.RoleRevokePermissionResponse{Header: s.responseHeader()}, nil
+}
+
 // ---- http handler ----
 
+// applyHttpStatus converts the error of an applied raft log to a http status
+func applyHttpStatus(err error) error {
+	switch {
+	case errors.Is(err, ErrApplyLogClosed):
+		return httputil.StatusWrap(http.StatusServiceUnavailable, 0, ErrServerGoingAway)
+	case errors.Is(err, ErrQuotaExceeded):
+		return httputil.StatusWrap(http.StatusInsufficientStorage, 0, err)
+	case errors.Is(err, store.ErrKeyAlreadyExists):
+		return httputil.StatusWrap(http.StatusConflict, 0, err)
+	case errors.Is(err, store.ErrNamespaceNotFound):
+		return httputil.StatusWrap(http.StatusNotFound, 0, err)
+	default:
+		return httputil.StatusWrap(http.StatusInternalServerError, 0, err)
+	}
+}
+
+// membershipHttpStatus converts the error of a membership change to a http status
+func membershipHttpStatus(err error) error {
+	switch {
+	case errors.Is(err, ErrServerNotFound):
+		return httputil.StatusWrap(http.StatusNotFound, 0, err)
+	case errors.Is(err, ErrServerNotVoter), errors.Is(err, ErrServerIsVoter):
+		return httputil.StatusWrap(http.StatusConflict, 0, err)
+	default:
+		return httputil.StatusWrap(http.StatusForbidden, 0, err)
+	}
+}
+
 type v1HttpServer struct {
 	*Server
 }
@@ -316,7 +860,24 @@ func (s *v1HttpServer) responseHeader(w http.ResponseWriter) {
 	w.Header().Set("X-Raft-Term", strconv.FormatUint(s.raft.Term(), 10))
 }
 
+// reservedAccess reports whether the request opted in to access reserved namespaces as a reserved admin
+func (s *v1HttpServer) reservedAccess(r *http.Request) bool {
+	username, ok := httputil.UserFromContext(r.Context())
+	return ok && r.Header.Get(MetadataReservedAccess) == "true" && s.allowReserved(username)
+}
+
+func (s *v1HttpServer) checkReserved(r *http.Request, namespace *string) error {
+	if namespace == nil || !store.IsReservedNamespace(*namespace) || s.reservedAccess(r) {
+		return nil
+	}
+	return httputil.StatusWrap(http.StatusForbidden, 0, store.ErrReservedNamespace)
+}
+
 func (s *v1HttpServer) Set(w http.ResponseWriter, r *http.Request) error {
+	if err := s.checkReserved(r, s.getBucket(r.Context())); err != nil {
+		return err
+	}
+
 	req, err := httputil.XBindJSON[*serverpb.SetRequest](r.Body)
 	if err != nil {
 		return err
@@ -329,7 +890,7 @@ func (s *v1HttpServer) Set(w http.ResponseWriter, r *http.Request) error {
 		Ttl:       expr.Pointer(req.Ttl),
 		Namespace: s.getBucket(r.Context()),
 	}, 500*time.Millisecond); err != nil {
-		return httputil.StatusWrap(http.StatusInternalServerError, 0, err)
+		return applyHttpStatus(err)
 	}
 
 	defer s.responseHeader(w)
@@ -338,6 +899,10 @@ func (s *v1HttpServer) Set(w http.ResponseWriter, r *http.Request) error {
 }
 
 func (s *v1HttpServer) Get(w http.ResponseWriter, r *http.Request) error {
+	if err := s.checkReserved(r, s.getBucket(r.Context())); err != nil {
+		return err
+	}
+
 	_key := r.URL.Query().Get("key")
 	if _key == "" {
 		return httputil.NewStatus(http.StatusBadRequest, 0, "invalid query key")
@@ -352,20 +917,64 @@ func (s *v1HttpServer) Get(w http.ResponseWriter, r *http.Request) error {
 		return httputil.StatusWrap(http.StatusInternalServerError, 0, err)
 	}
 
-	value, err := act.Get(key)
+	var value *store.Value
+	if _revision := r.URL.Query().Get("revision"); _revision != "" {
+		revision, pErr := strconv.ParseUint(_revision, 10, 64)
+		if pErr != nil {
+			return httputil.NewStatus(http.StatusBadRequest, 0, "invalid query revision")
+		}
+		value, err = act.GetRevision(key, revision)
+	} else {
+		value, err = act.Get(key)
+	}
 	if err != nil {
 		return httputil.StatusWrap(http.StatusNotFound, -1, err)
 	}
 
 	defer s.responseHeader(w)
 	httputil.NewAck[*serverpb.GetResponse](http.StatusOK, 1).Data(&serverpb.GetResponse{
-		Value: value.Data,
-		Ttl:   value.TTL,
+		Value:    value.Data,
+		Ttl:      value.TTL,
+		Revision: value.Revision,
 	}).Ok(w)
 	return nil
 }
 
+func (s *v1HttpServer) History(w http.ResponseWriter, r *http.Request) error {
+	if err := s.checkReserved(r, s.getBucket(r.Context())); err != nil {
+		return err
+	}
+
+	var (
+		q     = r.URL.Query()
+		limit uint64
+	)
+	key, err := base64.URLEncoding.DecodeString(q.Get("key"))
+	if err != nil || len(key) == 0 {
+		return httputil.NewStatus(http.StatusBadRequest, 0, "invalid query key")
+	}
+	limit, _ = strconv.ParseUint(q.Get("limit"), 10, 64)
+
+	act, err := s.trySwapContext(s.getBucket(r.Context()))
+	if err != nil {
+		return httputil.StatusWrap(http.StatusInternalServerError, 0, err)
+	}
+
+	versions, err := act.History(key, int(limit))
+	if err != nil {
+		return httputil.StatusWrap(http.StatusInternalServerError, 0, err)
+	}
+
+	defer s.responseHeader(w)
+	httputil.NewAck[[]*serverpb.HistoryResponse_Version](http.StatusOK, 1).Data(historyVersions(versions)).Ok(w)
+	return nil
+}
+
 func (s *v1HttpServer) PrefixScan(w http.ResponseWriter, r *http.Request) error {
+	if err := s.checkReserved(r, s.getBucket(r.Context())); err != nil {
+		return err
+	}
+
 	var (
 		q      = r.URL.Query()
 		prefix []byte
@@ -409,6 +1018,10 @@ func (s *v1HttpServer) PrefixScan(w http.ResponseWriter, r *http.Request) error
 }
 
 func (s *v1HttpServer) TrySet(w http.ResponseWriter, r *http.Request) error {
+	if err := s.checkReserved(r, s.getBucket(r.Context())); err != nil {
+		return err
+	}
+
 	req, err := httputil.XBindJSON[*serverpb.SetRequest](r.Body)
 	if err != nil {
 		return err
@@ -421,7 +1034,7 @@ func (s *v1HttpServer) TrySet(w http.ResponseWriter, r *http.Request) error {
 		Ttl:       &req.Ttl,
 		Namespace: s.getBucket(r.Context()),
 	}, 500*time.Millisecond); err != nil {
-		return httputil.StatusWrap(http.StatusInternalServerError, 0, err)
+		return applyHttpStatus(err)
 	}
 
 	defer s.responseHeader(w)
@@ -430,6 +1043,10 @@ func (s *v1HttpServer) TrySet(w http.ResponseWriter, r *http.Request) error {
 }
 
 func (s *v1HttpServer) Delete(w http.ResponseWriter, r *http.Request) error {
+	if err := s.checkReserved(r, s.getBucket(r.Context())); err != nil {
+		return err
+	}
+
 	req, err := httputil.XBindJSON[*serverpb.DeleteRequest](r.Body)
 	if err != nil {
 		return err
@@ -440,7 +1057,7 @@ func (s *v1HttpServer) Delete(w http.ResponseWriter, r *http.Request) error {
 		Key:       req.Key,
 		Namespace: s.getBucket(r.Context()),
 	}, 500*time.Millisecond); err != nil {
-		return httputil.StatusWrap(http.StatusInternalServerError, 0, err)
+		return applyHttpStatus(err)
 	}
 
 	defer s.responseHeader(w)
@@ -499,8 +1116,19 @@ func (s *v1HttpServer) AppendCluster(w http.ResponseWriter, r *http.Request) err
 		return err
 	}
 
-	if err = s.raft.AddCluster(raft.ServerID(req.ServerId), raft.ServerAddress(req.PeerAddr)); err != nil {
-		return httputil.StatusWrap(http.StatusForbidden, 0, err)
+	staged := req.Voter && s.stagedJoin()
+	if err = s.raft.AddCluster(raft.ServerID(req.ServerId), raft.ServerAddress(req.PeerAddr), req.Voter && !staged); err != nil {
+		return membershipHttpStatus(err)
+	}
+	if staged {
+		if err = s.markPromotion(r.Context(), raft.ServerID(req.ServerId), true); err != nil {
+			return applyHttpStatus(err)
+		}
+	}
+	if req.ClientAddr != nil {
+		if err = s.publishClientAddr(r.Context(), raft.ServerID(req.ServerId), *req.ClientAddr); err != nil {
+			return applyHttpStatus(err)
+		}
 	}
 
 	defer s.responseHeader(w)
@@ -508,6 +1136,75 @@ func (s *v1HttpServer) AppendCluster(w http.ResponseWriter, r *http.Request) err
 	return nil
 }
 
+func (s *v1HttpServer) RemoveCluster(w http.ResponseWriter, r *http.Request) error {
+	req, err := httputil.XBindJSON[*serverpb.RemoveClusterRequest](r.Body)
+	if err != nil {
+		return err
+	}
+
+	if err = s.raft.RemoveCluster(raft.ServerID(req.ServerId)); err != nil {
+		return membershipHttpStatus(err)
+	}
+	if err = s.markPromotion(r.Context(), raft.ServerID(req.ServerId), false); err != nil {
+		return applyHttpStatus(err)
+	}
+	if err = s.forgetClientAddr(r.Context(), raft.ServerID(req.ServerId)); err != nil {
+		return applyHttpStatus(err)
+	}
+
+	defer s.responseHeader(w)
+	httputil.Any(http.StatusOK, 1).Ok(w)
+	return nil
+}
+
+func (s *v1HttpServer) DemoteVoter(w http.ResponseWriter, r *http.Request) error {
+	req, err := httputil.XBindJSON[*serverpb.DemoteVoterRequest](r.Body)
+	if err != nil {
+		return err
+	}
+
+	if err = s.raft.DemoteCluster(raft.ServerID(req.ServerId)); err != nil {
+		return membershipHttpStatus(err)
+	}
+
+	defer s.responseHeader(w)
+	httputil.Any(http.StatusOK, 1).Ok(w)
+	return nil
+}
+
+func (s *v1HttpServer) PromoteLearner(w http.ResponseWriter, r *http.Request) error {
+	req, err := httputil.XBindJSON[*serverpb.PromoteLearnerRequest](r.Body)
+	if err != nil {
+		return err
+	}
+
+	if err = s.raft.PromoteCluster(raft.ServerID(req.ServerId)); err != nil {
+		return membershipHttpStatus(err)
+	}
+	if err = s.markPromotion(r.Context(), raft.ServerID(req.ServerId), false); err != nil {
+		return applyHttpStatus(err)
+	}
+
+	defer s.responseHeader(w)
+	httputil.Any(http.StatusOK, 1).Ok(w)
+	return nil
+}
+
+func (s *v1HttpServer) LeadershipTransfer(w http.ResponseWriter, r *http.Request) error {
+	req, err := httputil.XBindJSON[*serverpb.LeadershipTransferRequest](r.Body)
+	if err != nil {
+		return err
+	}
+
+	if err = s.raft.TransferLeadership(raft.ServerID(req.GetServerId())); err != nil {
+		return membershipHttpStatus(err)
+	}
+
+	defer s.responseHeader(w)
+	httputil.Any(http.StatusOK, 1).Ok(w)
+	return nil
+}
+
 func (s *v1HttpServer) Stats(w http.ResponseWriter, _ *http.Request) error {
 	s.responseHeader(w)
 	h := s.raft.Stats()
@@ -516,3 +1213,154 @@ func (s *v1HttpServer) Stats(w http.ResponseWriter, _ *http.Request) error {
 	httputil.NewAck[map[string]string](http.StatusOK, 1).Data(h).Ok(w)
 	return nil
 }
+
+func (s *v1HttpServer) MemberList(w http.ResponseWriter, _ *http.Request) error {
+	resp, err := s.memberList()
+	if err != nil {
+		return httputil.StatusWrap(http.StatusServiceUnavailable, 0, err)
+	}
+
+	defer s.responseHeader(w)
+	httputil.NewAck[*serverpb.MemberListResponse](http.StatusOK, 1).Data(resp).Ok(w)
+	return nil
+}
+
+func (s *v1HttpServer) NamespaceList(w http.ResponseWriter, r *http.Request) error {
+	namespaces, err := s.store.Namespaces()
+	if err != nil {
+		return httputil.StatusWrap(http.StatusInternalServerError, 0, err)
+	}
+
+	defer s.responseHeader(w)
+	httputil.NewAck[[]string](http.StatusOK, 1).Data(s.filterReserved(namespaces, s.reservedAccess(r))).Ok(w)
+	return nil
+}
+
+func (s *v1HttpServer) NamespaceCreate(w http.ResponseWriter, r *http.Request) error {
+	if err := s.checkReserved(r, s.getBucket(r.Context())); err != nil {
+		return err
+	}
+
+	if err := s.applyLog(r.Context(), &serverpb.RaftLogPayload{
+		Command:   serverpb.RaftLogCommand_NamespaceCreate,
+		Namespace: s.getBucket(r.Context()),
+	}, 500*time.Millisecond); err != nil {
+		return applyHttpStatus(err)
+	}
+
+	defer s.responseHeader(w)
+	httputil.Any(http.StatusCreated, 1).Ok(w)
+	return nil
+}
+
+func (s *v1HttpServer) NamespaceDrop(w http.ResponseWriter, r *http.Request) error {
+	if err := s.checkReserved(r, s.getBucket(r.Context())); err != nil {
+		return err
+	}
+
+	if err := s.applyLog(r.Context(), &serverpb.RaftLogPayload{
+		Command:   serverpb.RaftLogCommand_NamespaceDrop,
+		Namespace: s.getBucket(r.Context()),
+	}, 500*time.Millisecond); err != nil {
+		return applyHttpStatus(err)
+	}
+
+	defer s.responseHeader(w)
+	httputil.Any(http.StatusOK, 1).Ok(w)
+	return nil
+}
+
+func (s *v1HttpServer) NamespaceStats(w http.ResponseWriter, r *http.Request) error {
+	if err := s.checkReserved(r, s.getBucket(r.Context())); err != nil {
+		return err
+	}
+
+	namespace := s.getBucket(r.Context())
+	if namespace == nil {
+		return httputil.NewStatus(http.StatusBadRequest, 0, "invalid namespace")
+	}
+
+	stats, err := s.store.NamespaceStats(*namespace)
+	if err != nil {
+		if errors.Is(err, store.ErrNamespaceNotFound) {
+			return httputil.StatusWrap(http.StatusNotFound, 0, err)
+		}
+		return httputil.StatusWrap(http.StatusInternalServerError, 0, err)
+	}
+
+	defer s.responseHeader(w)
+	httputil.NewAck[*serverpb.NamespaceStatsResponse](http.StatusOK, 1).Data(&serverpb.NamespaceStatsResponse{
+		Namespace: stats.Namespace,
+		Keys:      stats.Keys,
+		Bytes:     stats.Bytes,
+	}).Ok(w)
+	return nil
+}
+
+func (s *v1HttpServer) NamespaceGetQuota(w http.ResponseWriter, r *http.Request) error {
+	if err := s.checkReserved(r, s.getBucket(r.Context())); err != nil {
+		return err
+	}
+
+	namespace := s.getBucket(r.Context())
+	if namespace == nil {
+		return httputil.NewStatus(http.StatusBadRequest, 0, "invalid namespace")
+	}
+
+	quota, err := LoadQuota(s.store, *namespace)
+	if err != nil {
+		return httputil.StatusWrap(http.StatusInternalServerError, 0, err)
+	}
+
+	defer s.responseHeader(w)
+	httputil.NewAck[*serverpb.Quota](http.StatusOK, 1).Data(expr.If(quota == nil, &serverpb.Quota{}, quota)).Ok(w)
+	return nil
+}
+
+func (s *v1HttpServer) NamespaceSetQuota(w http.ResponseWriter, r *http.Request) error {
+	if err := s.checkReserved(r, s.getBucket(r.Context())); err != nil {
+		return err
+	}
+
+	namespace := s.getBucket(r.Context())
+	if namespace == nil {
+		return httputil.NewStatus(http.StatusBadRequest, 0, "invalid namespace")
+	}
+
+	req, err := httputil.XBindJSON[*serverpb.Quota](r.Body)
+	if err != nil {
+		return err
+	}
+
+	quota, err := proto.Marshal(req)
+	if err != nil {
+		return httputil.StatusWrap(http.StatusBadRequest, 0, err)
+	}
+
+	if err = s.applyLog(r.Context(), &serverpb.RaftLogPayload{
+		Command:   serverpb.RaftLogCommand_NamespaceQuota,
+		Value:     quota,
+		Namespace: namespace,
+	}, 500*time.Millisecond); err != nil {
+		return applyHttpStatus(err)
+	}
+
+	defer s.responseHeader(w)
+	httputil.Any(http.StatusOK, 1).Ok(w)
+	return nil
+}
+
+func (s *v1HttpServer) Authenticate(w http.ResponseWriter, r *http.Request) error {
+	username, ok := httputil.UserFromContext(r.Context())
+	token, expiresAt, err := s.authenticate(username, ok, httputil.TokenFromContext(r.Context()))
+	if err != nil {
+		return tokenHttpStatus(err)
+	}
+
+	defer s.responseHeader(w)
+	httputil.NewAck[*serverpb.AuthenticateResponse](http.StatusOK, 1).Data(&serverpb.AuthenticateResponse{
+		Token:     token,
+		ExpiresAt: expiresAt.Unix(),
+	}).Ok(w)
+	return nil
+}
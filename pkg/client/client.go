@@ -9,6 +9,8 @@ type Client struct {
 	InternalClient
 	KvClient
 	LockerClient
+	NamespaceClient
+	AuthClient
 
 	conn      Conn
 	ctx       context.Context
@@ -34,6 +36,8 @@ func New(ctx context.Context, endpoints []string, opts ...grpc.DialOption) (*Cli
 	client.InternalClient = newInternalClient(ctx, client.conn)
 	client.KvClient = newKvClient(ctx, client.conn)
 	client.LockerClient = newLockerClient(ctx, client.conn)
+	client.NamespaceClient = newNamespaceClient(ctx, client.conn)
+	client.AuthClient = newAuthClient(ctx, client.conn)
 
 	return client, nil
 }
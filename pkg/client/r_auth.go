@@ -0,0 +1,240 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/RealFax/RedQueen/api/serverpb"
+)
+
+type Permission = serverpb.Permission
+
+const (
+	PermissionRead  = serverpb.Permission_read
+	PermissionWrite = serverpb.Permission_write
+	PermissionWatch = serverpb.Permission_watch
+	PermissionLock  = serverpb.Permission_lock
+	PermissionAdmin = serverpb.Permission_admin
+)
+
+// Grant holds permissions on the keys (lock ids) starting with Prefix in Namespace, empty is the
+// default namespace and "*" every namespace
+type Grant struct {
+	Namespace   string
+	Prefix      []byte
+	Permissions []Permission
+}
+
+type Role struct {
+	Name   string
+	Grants []Grant
+}
+
+type User struct {
+	Name  string
+	Roles []string
+}
+
+// AuthClient manages the users and roles of the rbac, it requires the admin permission on every
+// namespace once the rbac is enabled
+type AuthClient interface {
+	// Authenticate exchanges the credentials of the connection for a bearer token, see NewTokenAuth
+	Authenticate(ctx context.Context) (token string, expiresAt time.Time, err error)
+	// UserAdd adds the user, it's authenticated by the basic auth with password unless empty
+	UserAdd(ctx context.Context, name, password string) error
+	UserDelete(ctx context.Context, name string) error
+	// UserChangePassword replaces the basic auth password of the user, empty removes it. the users
+	// change their own password without the admin permission
+	UserChangePassword(ctx context.Context, name, password string) error
+	UserGet(ctx context.Context, name string) (*User, error)
+	UserList(ctx context.Context) ([]string, error)
+	UserGrantRole(ctx context.Context, user, role string) error
+	UserRevokeRole(ctx context.Context, user, role string) error
+	RoleAdd(ctx context.Context, name string) error
+	// RoleDelete removes the role, the users holding it lose its grants
+	RoleDelete(ctx context.Context, name string) error
+	RoleGet(ctx context.Context, name string) (*Role, error)
+	RoleList(ctx context.Context) ([]string, error)
+	// RoleGrantPermission replaces the grant of the role with the same namespace and prefix
+	RoleGrantPermission(ctx context.Context, role string, grant Grant) error
+	RoleRevokePermission(ctx context.Context, role, namespace string, prefix []byte) error
+}
+
+type authClient struct {
+	ctx  context.Context
+	conn Conn
+}
+
+func (c *authClient) Authenticate(ctx context.Context) (string, time.Time, error) {
+	client, err := newClientCall[serverpb.AuthClient](false, c.conn, serverpb.NewAuthClient)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	resp, err := client.instance.Authenticate(ctx, &serverpb.AuthenticateRequest{})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return resp.Token, time.Unix(resp.ExpiresAt, 0), nil
+}
+
+func (c *authClient) UserAdd(ctx context.Context, name, password string) error {
+	client, err := newClientCall[serverpb.AuthClient](true, c.conn, serverpb.NewAuthClient)
+	if err != nil {
+		return err
+	}
+	_, err = client.instance.UserAdd(ctx, &serverpb.UserAddRequest{Name: name, Password: password})
+	return err
+}
+
+func (c *authClient) UserChangePassword(ctx context.Context, name, password string) error {
+	client, err := newClientCall[serverpb.AuthClient](true, c.conn, serverpb.NewAuthClient)
+	if err != nil {
+		return err
+	}
+	_, err = client.instance.UserChangePassword(ctx, &serverpb.UserChangePasswordRequest{Name: name, Password: password})
+	return err
+}
+
+func (c *authClient) UserDelete(ctx context.Context, name string) error {
+	client, err := newClientCall[serverpb.AuthClient](true, c.conn, serverpb.NewAuthClient)
+	if err != nil {
+		return err
+	}
+	_, err = client.instance.UserDelete(ctx, &serverpb.UserDeleteRequest{Name: name})
+	return err
+}
+
+func (c *authClient) UserGet(ctx context.Context, name string) (*User, error) {
+	client, err := newClientCall[serverpb.AuthClient](false, c.conn, serverpb.NewAuthClient)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.instance.UserGet(ctx, &serverpb.UserGetRequest{Name: name})
+	if err != nil {
+		return nil, err
+	}
+	return &User{
+		Name:  resp.GetUser().GetName(),
+		Roles: resp.GetUser().GetRoles(),
+	}, nil
+}
+
+func (c *authClient) UserList(ctx context.Context) ([]string, error) {
+	client, err := newClientCall[serverpb.AuthClient](false, c.conn, serverpb.NewAuthClient)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.instance.UserList(ctx, &serverpb.UserListRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Users, nil
+}
+
+func (c *authClient) UserGrantRole(ctx context.Context, user, role string) error {
+	client, err := newClientCall[serverpb.AuthClient](true, c.conn, serverpb.NewAuthClient)
+	if err != nil {
+		return err
+	}
+	_, err = client.instance.UserGrantRole(ctx, &serverpb.UserGrantRoleRequest{User: user, Role: role})
+	return err
+}
+
+func (c *authClient) UserRevokeRole(ctx context.Context, user, role string) error {
+	client, err := newClientCall[serverpb.AuthClient](true, c.conn, serverpb.NewAuthClient)
+	if err != nil {
+		return err
+	}
+	_, err = client.instance.UserRevokeRole(ctx, &serverpb.UserRevokeRoleRequest{User: user, Role: role})
+	return err
+}
+
+func (c *authClient) RoleAdd(ctx context.Context, name string) error {
+	client, err := newClientCall[serverpb.AuthClient](true, c.conn, serverpb.NewAuthClient)
+	if err != nil {
+		return err
+	}
+	_, err = client.instance.RoleAdd(ctx, &serverpb.RoleAddRequest{Name: name})
+	return err
+}
+
+func (c *authClient) RoleDelete(ctx context.Context, name string) error {
+	client, err := newClientCall[serverpb.AuthClient](true, c.conn, serverpb.NewAuthClient)
+	if err != nil {
+		return err
+	}
+	_, err = client.instance.RoleDelete(ctx, &serverpb.RoleDeleteRequest{Name: name})
+	return err
+}
+
+func (c *authClient) RoleGet(ctx context.Context, name string) (*Role, error) {
+	client, err := newClientCall[serverpb.AuthClient](false, c.conn, serverpb.NewAuthClient)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.instance.RoleGet(ctx, &serverpb.RoleGetRequest{Name: name})
+	if err != nil {
+		return nil, err
+	}
+
+	role := &Role{
+		Name:   resp.GetRole().GetName(),
+		Grants: make([]Grant, 0, len(resp.GetRole().GetGrants())),
+	}
+	for _, grant := range resp.GetRole().GetGrants() {
+		role.Grants = append(role.Grants, Grant{
+			Namespace:   grant.Namespace,
+			Prefix:      grant.Prefix,
+			Permissions: grant.Permissions,
+		})
+	}
+	return role, nil
+}
+
+func (c *authClient) RoleList(ctx context.Context) ([]string, error) {
+	client, err := newClientCall[serverpb.AuthClient](false, c.conn, serverpb.NewAuthClient)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.instance.RoleList(ctx, &serverpb.RoleListRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Roles, nil
+}
+
+func (c *authClient) RoleGrantPermission(ctx context.Context, role string, grant Grant) error {
+	client, err := newClientCall[serverpb.AuthClient](true, c.conn, serverpb.NewAuthClient)
+	if err != nil {
+		return err
+	}
+	_, err = client.instance.RoleGrantPermission(ctx, &serverpb.RoleGrantPermissionRequest{
+		Role: role,
+		Grant: &serverpb.Grant{
+			Namespace:   grant.Namespace,
+			Prefix:      grant.Prefix,
+			Permissions: grant.Permissions,
+		},
+	})
+	return err
+}
+
+func (c *authClient) RoleRevokePermission(ctx context.Context, role, namespace string, prefix []byte) error {
+	client, err := newClientCall[serverpb.AuthClient](true, c.conn, serverpb.NewAuthClient)
+	if err != nil {
+		return err
+	}
+	_, err = client.instance.RoleRevokePermission(ctx, &serverpb.RoleRevokePermissionRequest{
+		Role:      role,
+		Namespace: namespace,
+		Prefix:    prefix,
+	})
+	return err
+}
+
+func newAuthClient(ctx context.Context, conn Conn) AuthClient {
+	return &authClient{
+		ctx:  ctx,
+		conn: conn,
+	}
+}
@@ -3,12 +3,40 @@ package client
 import (
 	"context"
 	"github.com/pkg/errors"
+	"time"
 
 	"github.com/RealFax/RedQueen/api/serverpb"
+	"google.golang.org/protobuf/types/known/emptypb"
 )
 
+// Member is a server of the raft cluster as seen by the leader
+type Member struct {
+	ServerID string
+	Address  string
+	// ClientAddr is the advertised grpc address, empty when the server never published it
+	ClientAddr string
+	// Suffrage is one of voter, nonvoter and staging
+	Suffrage string
+	Leader   bool
+	// LastContact is zero when unknown
+	LastContact time.Time
+	// AppliedIndex is only known for the server answering the request
+	AppliedIndex *uint64
+	CommitIndex  uint64
+	LastLogIndex uint64
+	// Healthy is only known when the leader runs autopilot
+	Healthy *bool
+}
+
 type InternalClient interface {
 	AppendCluster(ctx context.Context, serverID string, peerAddr string, voter bool) error
+	RemoveCluster(ctx context.Context, serverID string) error
+	DemoteVoter(ctx context.Context, serverID string) error
+	PromoteLearner(ctx context.Context, serverID string) error
+	// LeadershipTransfer transfers the leadership to serverID, or to any voter when serverID is empty
+	LeadershipTransfer(ctx context.Context, serverID string) error
+	// MemberList returns the servers of the raft configuration, it is answered by the leader
+	MemberList(ctx context.Context) ([]Member, error)
 	LeaderMonitor(ctx context.Context, recv *chan bool) error
 	Snapshot(ctx context.Context, serverPath *string) error
 }
@@ -32,6 +60,81 @@ func (c *internalClient) AppendCluster(ctx context.Context, serverID, peerAddr s
 	return err
 }
 
+func (c *internalClient) RemoveCluster(ctx context.Context, serverID string) error {
+	client, err := newClientCall[serverpb.RedQueenClient](true, c.conn, serverpb.NewRedQueenClient)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.instance.RemoveCluster(ctx, &serverpb.RemoveClusterRequest{ServerId: serverID})
+	return err
+}
+
+func (c *internalClient) DemoteVoter(ctx context.Context, serverID string) error {
+	client, err := newClientCall[serverpb.RedQueenClient](true, c.conn, serverpb.NewRedQueenClient)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.instance.DemoteVoter(ctx, &serverpb.DemoteVoterRequest{ServerId: serverID})
+	return err
+}
+
+func (c *internalClient) PromoteLearner(ctx context.Context, serverID string) error {
+	client, err := newClientCall[serverpb.RedQueenClient](true, c.conn, serverpb.NewRedQueenClient)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.instance.PromoteLearner(ctx, &serverpb.PromoteLearnerRequest{ServerId: serverID})
+	return err
+}
+
+func (c *internalClient) LeadershipTransfer(ctx context.Context, serverID string) error {
+	client, err := newClientCall[serverpb.RedQueenClient](true, c.conn, serverpb.NewRedQueenClient)
+	if err != nil {
+		return err
+	}
+
+	req := &serverpb.LeadershipTransferRequest{}
+	if serverID != "" {
+		req.ServerId = &serverID
+	}
+	_, err = client.instance.LeadershipTransfer(ctx, req)
+	return err
+}
+
+func (c *internalClient) MemberList(ctx context.Context) ([]Member, error) {
+	client, err := newClientCall[serverpb.RedQueenClient](true, c.conn, serverpb.NewRedQueenClient)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.instance.MemberList(ctx, &emptypb.Empty{})
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([]Member, len(resp.Members))
+	for i, member := range resp.Members {
+		members[i] = Member{
+			ServerID:     member.ServerId,
+			Address:      member.Address,
+			ClientAddr:   member.ClientAddr,
+			Suffrage:     member.Suffrage.String(),
+			Leader:       member.Leader,
+			AppliedIndex: member.AppliedIndex,
+			CommitIndex:  member.CommitIndex,
+			LastLogIndex: member.LastLogIndex,
+			Healthy:      member.Healthy,
+		}
+		if member.LastContact != 0 {
+			members[i].LastContact = time.UnixMilli(member.LastContact)
+		}
+	}
+	return members, nil
+}
+
 func (c *internalClient) LeaderMonitor(ctx context.Context, recv *chan bool) error {
 	if len(*recv) != 0 || cap(*recv) != 1 {
 		return errors.New("invalid receiver channel")
@@ -10,11 +10,17 @@ type Value struct {
 	Key  []byte
 	Data []byte
 	TTL  uint32
+	// Revision, Timestamp and Deleted are only set on the values returned by GetRevision and History
+	Revision  uint64
+	Timestamp uint64
+	Deleted   bool
 }
 
 type KvClient interface {
 	Set(ctx context.Context, key, value []byte, ttl uint32, namespace *string) error
 	Get(ctx context.Context, key []byte, namespace *string) (*Value, error)
+	GetRevision(ctx context.Context, key []byte, revision uint64, namespace *string) (*Value, error)
+	History(ctx context.Context, key []byte, limit uint64, namespace *string) ([]*Value, error)
 	PrefixScan(ctx context.Context, prefix []byte, offset, limit uint64, reg, namespace *string) ([]*Value, error)
 	TrySet(ctx context.Context, key, value []byte, ttl uint32, namespace *string) error
 	Delete(ctx context.Context, key []byte, namespace *string) error
@@ -64,6 +70,57 @@ func (c *kvClient) Get(ctx context.Context, key []byte, namespace *string) (*Val
 	}, nil
 }
 
+func (c *kvClient) GetRevision(ctx context.Context, key []byte, revision uint64, namespace *string) (*Value, error) {
+	client, err := newClientCall[serverpb.KVClient](false, c.conn, serverpb.NewKVClient)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.instance.Get(ctx, &serverpb.GetRequest{
+		Key:       key,
+		Namespace: namespace,
+		Revision:  &revision,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Value{
+		Key:      key,
+		Data:     resp.Value,
+		TTL:      resp.Ttl,
+		Revision: resp.Revision,
+	}, nil
+}
+
+func (c *kvClient) History(ctx context.Context, key []byte, limit uint64, namespace *string) ([]*Value, error) {
+	client, err := newClientCall[serverpb.KVClient](false, c.conn, serverpb.NewKVClient)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.instance.History(ctx, &serverpb.HistoryRequest{
+		Key:       key,
+		Limit:     limit,
+		Namespace: namespace,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]*Value, len(resp.Versions))
+	for i, version := range resp.Versions {
+		values[i] = &Value{
+			Key:       key,
+			Data:      version.Value,
+			TTL:       version.Ttl,
+			Revision:  version.Revision,
+			Timestamp: version.Timestamp,
+			Deleted:   version.Deleted,
+		}
+	}
+	return values, nil
+}
+
 func (c *kvClient) PrefixScan(ctx context.Context, prefix []byte, offset, limit uint64, reg, namespace *string) ([]*Value, error) {
 	client, err := newClientCall[serverpb.KVClient](false, c.conn, serverpb.NewKVClient)
 	if err != nil {
@@ -160,6 +217,10 @@ func (c *kvClient) Watch(ctx context.Context, watcher *Watcher) error {
 		if watcher.close.Load() {
 			return ErrWatcherClosed
 		}
+		if resp.GoingAway {
+			// the server shuts down, the caller watches again on another member
+			return ErrServerGoingAway
+		}
 
 		watcher.ch <- &WatchValue{
 			seq:       resp.UpdateSeq,
@@ -206,6 +267,10 @@ func (c *kvClient) WatchPrefix(ctx context.Context, watcher *Watcher) error {
 		if watcher.close.Load() {
 			return ErrWatcherClosed
 		}
+		if resp.GoingAway {
+			// the server shuts down, the caller watches again on another member
+			return ErrServerGoingAway
+		}
 
 		watcher.ch <- &WatchValue{
 			seq:       resp.UpdateSeq,
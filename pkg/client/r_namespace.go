@@ -0,0 +1,138 @@
+package client
+
+import (
+	"context"
+
+	"github.com/RealFax/RedQueen/api/serverpb"
+)
+
+type NamespaceStats struct {
+	Namespace string
+	Keys      uint64
+	Bytes     uint64
+}
+
+// Quota limits the writes into a namespace, 0 means unlimited
+type Quota struct {
+	MaxKeys      uint64
+	MaxBytes     uint64
+	MaxValueSize uint32
+	// MaxTTL is the maximum ttl of a key, keys that never ttl are rejected once it is set
+	MaxTTL uint32
+}
+
+type NamespaceClient interface {
+	ListNamespaces(ctx context.Context) ([]string, error)
+	CreateNamespace(ctx context.Context, namespace string) error
+	DropNamespace(ctx context.Context, namespace string) error
+	NamespaceStats(ctx context.Context, namespace string) (*NamespaceStats, error)
+	GetQuota(ctx context.Context, namespace string) (*Quota, error)
+	// SetQuota replaces the quota of the namespace, an empty quota removes it
+	SetQuota(ctx context.Context, namespace string, quota Quota) error
+}
+
+type namespaceClient struct {
+	ctx  context.Context
+	conn Conn
+}
+
+func (c *namespaceClient) ListNamespaces(ctx context.Context) ([]string, error) {
+	client, err := newClientCall[serverpb.NamespaceClient](false, c.conn, serverpb.NewNamespaceClient)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.instance.List(ctx, &serverpb.NamespaceListRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Namespaces, nil
+}
+
+func (c *namespaceClient) CreateNamespace(ctx context.Context, namespace string) error {
+	client, err := newClientCall[serverpb.NamespaceClient](true, c.conn, serverpb.NewNamespaceClient)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.instance.Create(ctx, &serverpb.NamespaceCreateRequest{
+		Namespace: namespace,
+	})
+	return err
+}
+
+func (c *namespaceClient) DropNamespace(ctx context.Context, namespace string) error {
+	client, err := newClientCall[serverpb.NamespaceClient](true, c.conn, serverpb.NewNamespaceClient)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.instance.Drop(ctx, &serverpb.NamespaceDropRequest{
+		Namespace: namespace,
+	})
+	return err
+}
+
+func (c *namespaceClient) NamespaceStats(ctx context.Context, namespace string) (*NamespaceStats, error) {
+	client, err := newClientCall[serverpb.NamespaceClient](false, c.conn, serverpb.NewNamespaceClient)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.instance.Stats(ctx, &serverpb.NamespaceStatsRequest{
+		Namespace: namespace,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &NamespaceStats{
+		Namespace: resp.Namespace,
+		Keys:      resp.Keys,
+		Bytes:     resp.Bytes,
+	}, nil
+}
+
+func (c *namespaceClient) GetQuota(ctx context.Context, namespace string) (*Quota, error) {
+	client, err := newClientCall[serverpb.NamespaceClient](false, c.conn, serverpb.NewNamespaceClient)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.instance.GetQuota(ctx, &serverpb.NamespaceGetQuotaRequest{
+		Namespace: namespace,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Quota{
+		MaxKeys:      resp.GetQuota().GetMaxKeys(),
+		MaxBytes:     resp.GetQuota().GetMaxBytes(),
+		MaxValueSize: resp.GetQuota().GetMaxValueSize(),
+		MaxTTL:       resp.GetQuota().GetMaxTtl(),
+	}, nil
+}
+
+func (c *namespaceClient) SetQuota(ctx context.Context, namespace string, quota Quota) error {
+	client, err := newClientCall[serverpb.NamespaceClient](true, c.conn, serverpb.NewNamespaceClient)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.instance.SetQuota(ctx, &serverpb.NamespaceSetQuotaRequest{
+		Namespace: namespace,
+		Quota: &serverpb.Quota{
+			MaxKeys:      quota.MaxKeys,
+			MaxBytes:     quota.MaxBytes,
+			MaxValueSize: quota.MaxValueSize,
+			MaxTtl:       quota.MaxTTL,
+		},
+	})
+	return err
+}
+
+func newNamespaceClient(ctx context.Context, conn Conn) NamespaceClient {
+	return &namespaceClient{
+		ctx:  ctx,
+		conn: conn,
+	}
+}
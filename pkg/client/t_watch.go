@@ -9,7 +9,8 @@ import (
 const DefaultWatchBufSize uint32 = 8
 
 var (
-	ErrWatcherClosed = errors.New("watcher has closed")
+	ErrWatcherClosed   = errors.New("watcher has closed")
+	ErrServerGoingAway = errors.New("server going away")
 )
 
 type WatchValue struct {
@@ -1,9 +1,14 @@
 package client
 
 import (
+	"context"
 	"encoding/hex"
+	"github.com/RealFax/RedQueen/api/serverpb"
+	"github.com/RealFax/RedQueen/pkg/grpcutil"
 	"github.com/google/uuid"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"time"
 	"unicode/utf8"
 )
 
@@ -40,6 +45,29 @@ func Namespace(s string) *string {
 	return &s
 }
 
+// ReservedAccess opts the calls using ctx in to access reserved namespaces,
+// the server only honors it for the users listed in reserved admins
+func ReservedAccess(ctx context.Context) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, "X-Reserved-Access", "true")
+}
+
+// authenticateMethod is the full method of the Auth.Authenticate rpc
+const authenticateMethod = "/serverpb.Auth/Authenticate"
+
+// NewTokenAuth authenticates the calls by a bearer token, the credentials are only sent to
+// exchange the token and it's refreshed before it expires. pass its Unary and Stream as the
+// interceptors of the dial options
+func NewTokenAuth(username, password string) *grpcutil.TokenAuthClient {
+	return grpcutil.NewTokenAuthClient(username, password, authenticateMethod,
+		func(ctx context.Context, cc *grpc.ClientConn) (string, time.Time, error) {
+			resp, err := serverpb.NewAuthClient(cc).Authenticate(ctx, &serverpb.AuthenticateRequest{})
+			if err != nil {
+				return "", time.Time{}, err
+			}
+			return resp.Token, time.Unix(resp.ExpiresAt, 0), nil
+		})
+}
+
 func NewLeaderMonitorReceiver() *chan bool {
 	c := make(chan bool, 1)
 	return &c
@@ -12,6 +12,10 @@ const (
 	Namespace string = "_Locker"
 )
 
+func init() {
+	store.ReserveNamespace(Namespace)
+}
+
 type Backend interface {
 	Get(key []byte) (*store.Value, error)
 	TrySetWithTTL(key, value []byte, ttl uint32) error
@@ -10,3 +10,8 @@ func If[T any](f bool, then, end T) T {
 	}
 	return end
 }
+
+// OrDefault returns v, or def when v is zero
+func OrDefault[T comparable](v, def T) T {
+	return If(IsZero(v), def, v)
+}
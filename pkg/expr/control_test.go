@@ -59,3 +59,23 @@ func TestIf(t *testing.T) {
 		})
 	}
 }
+
+func TestOrDefault(t *testing.T) {
+	tests := []struct {
+		name   string
+		expect any
+		want   any
+	}{
+		{"TestZeroInt", expr.OrDefault(0, 10), 10},
+		{"TestNonZeroInt", expr.OrDefault(5, 10), 5},
+		{"TestZeroString", expr.OrDefault("", "World"), "World"},
+		{"TestNonZeroString", expr.OrDefault("Hello", "World"), "Hello"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.expect != tt.want {
+				t.Errorf("OrDefault() = %v, want %v", tt.expect, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package fs_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/RealFax/RedQueen/pkg/fs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotateFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "audit.log")
+	f, err := fs.OpenRotateFile(path, 8, 2)
+	require.NoError(t, err)
+
+	for _, line := range []string{"line-1\n", "line-2\n", "line-3\n", "line-4\n"} {
+		_, err = f.Write([]byte(line))
+		require.NoError(t, err)
+	}
+	require.NoError(t, f.Close())
+
+	for file, expect := range map[string]string{
+		path:        "line-4\n",
+		path + ".1": "line-3\n",
+		path + ".2": "line-2\n",
+	} {
+		b, rErr := os.ReadFile(file)
+		require.NoError(t, rErr)
+		assert.Equal(t, expect, string(b))
+	}
+	assert.NoFileExists(t, path+".3")
+
+	// reopened files keep appending
+	f, err = fs.OpenRotateFile(path, 0, 2)
+	require.NoError(t, err)
+	_, err = f.Write([]byte("line-5\n"))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "line-4\nline-5\n", string(b))
+}
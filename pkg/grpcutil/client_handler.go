@@ -3,17 +3,20 @@ package grpcutil
 import (
 	"context"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"sync"
+	"time"
 )
 
 type BasicAuthClient struct {
 	AuthKey string
 }
 
+// ctxWrap appends the authorization to the outgoing metadata, the key is lowercased as grpc requires
 func (c BasicAuthClient) ctxWrap(ctx context.Context) context.Context {
-	return metadata.NewOutgoingContext(ctx, metadata.MD{
-		MetadataAuthorization: []string{c.AuthKey},
-	})
+	return metadata.AppendToOutgoingContext(ctx, MetadataAuthorization, c.AuthKey)
 }
 
 func (c BasicAuthClient) Unary(
@@ -40,3 +43,104 @@ func (c BasicAuthClient) Stream(
 func NewBasicAuthClient(username, password string) *BasicAuthClient {
 	return &BasicAuthClient{AuthKey: BuildAuthorization(username, password)}
 }
+
+// IssueTokenFunc exchanges the credentials for a bearer token over cc, the call of the issue
+// method is authenticated by the basic auth
+type IssueTokenFunc func(ctx context.Context, cc *grpc.ClientConn) (token string, expiresAt time.Time, err error)
+
+// TokenAuthClient authenticates the calls by a bearer token. the token is issued on the first
+// call and issued again once 3/4 of its lifetime passed or the server rejects it
+type TokenAuthClient struct {
+	basic       BasicAuthClient
+	issueMethod string
+	issueFC     IssueTokenFunc
+
+	mu        sync.Mutex
+	token     string
+	refreshAt time.Time
+}
+
+// Token returns the current token, a new one is issued over cc when it's due
+func (c *TokenAuthClient) Token(ctx context.Context, cc *grpc.ClientConn) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if c.token != "" && now.Before(c.refreshAt) {
+		return c.token, nil
+	}
+
+	token, expiresAt, err := c.issueFC(ctx, cc)
+	if err != nil {
+		return "", err
+	}
+	c.token = token
+	c.refreshAt = now.Add(expiresAt.Sub(now) * 3 / 4)
+	return token, nil
+}
+
+// invalidate drops token, unless another call has replaced it already
+func (c *TokenAuthClient) invalidate(token string) {
+	c.mu.Lock()
+	if c.token == token {
+		c.token = ""
+	}
+	c.mu.Unlock()
+}
+
+func (c *TokenAuthClient) ctxWrap(ctx context.Context, token string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, MetadataAuthorization, BuildBearerAuthorization(token))
+}
+
+func (c *TokenAuthClient) Unary(
+	ctx context.Context,
+	method string, req, reply any,
+	cc *grpc.ClientConn,
+	invoker grpc.UnaryInvoker,
+	opts ...grpc.CallOption,
+) error {
+	if method == c.issueMethod {
+		return c.basic.Unary(ctx, method, req, reply, cc, invoker, opts...)
+	}
+
+	token, err := c.Token(ctx, cc)
+	if err != nil {
+		return err
+	}
+	err = invoker(c.ctxWrap(ctx, token), method, req, reply, cc, opts...)
+	if status.Code(err) != codes.Unauthenticated {
+		return err
+	}
+
+	// the token expired early or the signing key changed, retry once with a new token
+	c.invalidate(token)
+	if token, err = c.Token(ctx, cc); err != nil {
+		return err
+	}
+	return invoker(c.ctxWrap(ctx, token), method, req, reply, cc, opts...)
+}
+
+func (c *TokenAuthClient) Stream(
+	ctx context.Context,
+	desc *grpc.StreamDesc,
+	cc *grpc.ClientConn,
+	method string,
+	streamer grpc.Streamer,
+	opts ...grpc.CallOption,
+) (grpc.ClientStream, error) {
+	token, err := c.Token(ctx, cc)
+	if err != nil {
+		return nil, err
+	}
+	return streamer(c.ctxWrap(ctx, token), desc, cc, method, opts...)
+}
+
+// NewTokenAuthClient authenticates the calls of issueMethod by the basic auth, fc calls it to
+// issue the tokens of the other calls
+func NewTokenAuthClient(username, password, issueMethod string, fc IssueTokenFunc) *TokenAuthClient {
+	return &TokenAuthClient{
+		basic:       BasicAuthClient{AuthKey: BuildAuthorization(username, password)},
+		issueMethod: issueMethod,
+		issueFC:     fc,
+	}
+}
@@ -2,11 +2,15 @@ package grpcutil_test
 
 import (
 	"context"
+	"fmt"
 	"github.com/RealFax/RedQueen/pkg/grpcutil"
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	"testing"
+	"time"
 )
 
 type mockClientStream struct {
@@ -56,3 +60,75 @@ func TestBasicAuthClientStream(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, stream)
 }
+
+func TestTokenAuthClientUnary(t *testing.T) {
+	var (
+		issued int
+		issue  = func(ctx context.Context, cc *grpc.ClientConn) (string, time.Time, error) {
+			issued++
+			return fmt.Sprintf("token-%d", issued), time.Now().Add(time.Hour), nil
+		}
+		client = grpcutil.NewTokenAuthClient("username", "password", "/test/Authenticate", issue)
+		sent   []string
+	)
+
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		md, ok := metadata.FromOutgoingContext(ctx)
+		assert.True(t, ok)
+		sent = append(sent, md.Get(grpcutil.MetadataAuthorization)[0])
+		return nil
+	}
+
+	// the issue method is authenticated by the basic auth
+	assert.NoError(t, client.Unary(context.Background(), "/test/Authenticate", nil, nil, &grpc.ClientConn{}, invoker))
+	assert.Equal(t, grpcutil.BuildAuthorization("username", "password"), sent[0])
+
+	// the token is issued once and reused
+	assert.NoError(t, client.Unary(context.Background(), "/test/Method", nil, nil, &grpc.ClientConn{}, invoker))
+	assert.NoError(t, client.Unary(context.Background(), "/test/Method", nil, nil, &grpc.ClientConn{}, invoker))
+	assert.Equal(t, 1, issued)
+	assert.Equal(t, []string{
+		grpcutil.BuildBearerAuthorization("token-1"),
+		grpcutil.BuildBearerAuthorization("token-1"),
+	}, sent[1:])
+}
+
+func TestTokenAuthClientUnary_Refresh(t *testing.T) {
+	var (
+		issued int
+		ttl    = time.Hour
+		issue  = func(ctx context.Context, cc *grpc.ClientConn) (string, time.Time, error) {
+			issued++
+			return fmt.Sprintf("token-%d", issued), time.Now().Add(ttl), nil
+		}
+		client = grpcutil.NewTokenAuthClient("username", "password", "/test/Authenticate", issue)
+		sent   []string
+	)
+
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		authorization := md.Get(grpcutil.MetadataAuthorization)[0]
+		sent = append(sent, authorization)
+		if authorization == grpcutil.BuildBearerAuthorization("token-1") {
+			return status.Error(codes.Unauthenticated, "invalid or expired token")
+		}
+		return nil
+	}
+
+	// a rejected token is issued again and the call retried once
+	assert.NoError(t, client.Unary(context.Background(), "/test/Method", nil, nil, &grpc.ClientConn{}, invoker))
+	assert.Equal(t, 2, issued)
+	assert.Equal(t, []string{
+		grpcutil.BuildBearerAuthorization("token-1"),
+		grpcutil.BuildBearerAuthorization("token-2"),
+	}, sent)
+
+	// a token past 3/4 of its lifetime is issued again before the call
+	ttl = 0
+	client = grpcutil.NewTokenAuthClient("username", "password", "/test/Authenticate", issue)
+	token, err := client.Token(context.Background(), &grpc.ClientConn{})
+	assert.NoError(t, err)
+	next, err := client.Token(context.Background(), &grpc.ClientConn{})
+	assert.NoError(t, err)
+	assert.NotEqual(t, token, next)
+}
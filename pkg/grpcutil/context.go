@@ -0,0 +1,39 @@
+package grpcutil
+
+import (
+	"context"
+	"google.golang.org/grpc"
+)
+
+type userContextKey struct{}
+
+// ContextWithUser returns a copy of ctx carrying the authenticated username
+func ContextWithUser(ctx context.Context, username string) context.Context {
+	return context.WithValue(ctx, userContextKey{}, username)
+}
+
+// UserFromContext returns the authenticated username, ok is false when the request is not authenticated
+func UserFromContext(ctx context.Context) (username string, ok bool) {
+	username, ok = ctx.Value(userContextKey{}).(string)
+	return
+}
+
+type tokenContextKey struct{}
+
+func contextWithToken(ctx context.Context) context.Context {
+	return context.WithValue(ctx, tokenContextKey{}, true)
+}
+
+// TokenFromContext reports whether the user of ctx was authenticated by a bearer token
+func TokenFromContext(ctx context.Context) bool {
+	ok, _ := ctx.Value(tokenContextKey{}).(bool)
+	return ok
+}
+
+// wrappedServerStream replaces the context of a grpc.ServerStream
+type wrappedServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *wrappedServerStream) Context() context.Context { return s.ctx }
@@ -3,10 +3,14 @@ package grpcutil
 import (
 	"context"
 	"crypto/subtle"
+	"crypto/tls"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
+	"strings"
 )
 
 const (
@@ -14,56 +18,195 @@ const (
 )
 
 type BasicAuthFunc func(username, password string) bool
+
+// CertAuthFunc returns the identity of the verified client certificate of the connection
+type CertAuthFunc func(state tls.ConnectionState) (username string, ok bool)
+
+// TokenAuthFunc returns the user the bearer token was issued to, ok is false for invalid or
+// expired tokens
+type TokenAuthFunc func(token string) (username string, ok bool)
+
+// AuthorizeFunc checks whether the user may call method with req, username is empty for
+// unauthenticated requests. the requests of a stream are checked as they are received
+type AuthorizeFunc func(ctx context.Context, username, method string, req any) error
+
 type BasicAuth struct {
-	authFC BasicAuthFunc
+	authFC      BasicAuthFunc
+	certFC      CertAuthFunc
+	tokenFC     TokenAuthFunc
+	authorizeFC AuthorizeFunc
+}
+
+// WithToken accepts the bearer tokens verified by fc alongside the basic auth
+func (a *BasicAuth) WithToken(fc TokenAuthFunc) *BasicAuth {
+	a.tokenFC = fc
+	return a
+}
+
+// WithAuthorize checks every authenticated request with fc
+func (a *BasicAuth) WithAuthorize(fc AuthorizeFunc) *BasicAuth {
+	a.authorizeFC = fc
+	return a
+}
+
+// certAuth returns the identity of the client certificate of ctx
+func (a BasicAuth) certAuth(ctx context.Context) (string, bool) {
+	if a.certFC == nil {
+		return "", false
+	}
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return "", false
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return "", false
+	}
+	return a.certFC(info.State)
 }
 
-func (a BasicAuth) auth(ctx context.Context) error {
+func (a BasicAuth) auth(ctx context.Context) (context.Context, error) {
+	if username, ok := a.certAuth(ctx); ok {
+		return ContextWithUser(ctx, username), nil
+	}
+	if a.authFC == nil && a.tokenFC == nil {
+		// only the certificates authenticate
+		if a.certFC != nil {
+			return nil, status.Error(codes.Unauthenticated, "client certificate required")
+		}
+		return ctx, nil
+	}
+
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
-		return status.Error(codes.InvalidArgument, "failed get metadata")
+		return nil, status.Error(codes.InvalidArgument, "failed get metadata")
 	}
 
 	authorization := md.Get(MetadataAuthorization)
 	if len(authorization) != 1 {
-		return status.Error(codes.InvalidArgument, "invalid metadata 'Authorization'")
+		switch {
+		case a.certFC != nil:
+			// the client has neither a verified certificate nor credentials
+			return nil, status.Error(codes.Unauthenticated, "unauthenticated")
+		case a.authFC == nil:
+			// the client has no token
+			return ctx, nil
+		}
+		return nil, status.Error(codes.InvalidArgument, "invalid metadata 'Authorization'")
 	}
 
-	if !ParseAuthorization(authorization[0], a.authFC) {
-		return status.Error(codes.Unauthenticated, "unauthenticated")
+	if token, ok := strings.CutPrefix(authorization[0], BearerPrefix); ok && a.tokenFC != nil {
+		username, ok := a.tokenFC(token)
+		if !ok {
+			return nil, status.Error(codes.Unauthenticated, "invalid or expired token")
+		}
+		return contextWithToken(ContextWithUser(ctx, username)), nil
+	}
+	if a.authFC == nil {
+		return nil, status.Error(codes.Unauthenticated, "unauthenticated")
 	}
 
-	return nil
+	username, password, ok := DecodeAuthorization(authorization[0])
+	if !ok || !a.authFC(username, password) {
+		return nil, status.Error(codes.Unauthenticated, "unauthenticated")
+	}
+
+	return ContextWithUser(ctx, username), nil
 }
 
 func (a BasicAuth) Unary(
 	ctx context.Context,
 	req any,
-	_ *grpc.UnaryServerInfo,
+	info *grpc.UnaryServerInfo,
 	handler grpc.UnaryHandler,
 ) (any, error) {
-	if err := a.auth(ctx); err != nil {
+	ctx, err := a.auth(ctx)
+	if err != nil {
 		return nil, err
 	}
+	if a.authorizeFC != nil {
+		return NewAuthorize(a.authorizeFC).Unary(ctx, req, info, handler)
+	}
 	return handler(ctx, req)
 }
 
 func (a BasicAuth) Stream(
 	srv any,
 	ss grpc.ServerStream,
-	_ *grpc.StreamServerInfo,
+	info *grpc.StreamServerInfo,
 	handler grpc.StreamHandler,
 ) error {
-	if err := a.auth(ss.Context()); err != nil {
+	ctx, err := a.auth(ss.Context())
+	if err != nil {
 		return err
 	}
+	ss = &wrappedServerStream{ServerStream: ss, ctx: ctx}
+	if a.authorizeFC != nil {
+		return NewAuthorize(a.authorizeFC).Stream(srv, ss, info, handler)
+	}
 	return handler(srv, ss)
 }
 
+// Authorize checks the requests of the users authenticated by the interceptors before it, e.g.
+// to run other interceptors between the authentication and the authorization
+type Authorize struct {
+	fc AuthorizeFunc
+}
+
+func (a *Authorize) authorize(ctx context.Context, method string, req any) error {
+	username, _ := UserFromContext(ctx)
+	return a.fc(ctx, username, method, req)
+}
+
+func (a *Authorize) Unary(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (any, error) {
+	if err := a.authorize(ctx, info.FullMethod, req); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (a *Authorize) Stream(
+	srv any,
+	ss grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	return handler(srv, &authorizedServerStream{ServerStream: ss, auth: a, method: info.FullMethod})
+}
+
+// authorizedServerStream checks every received request
+type authorizedServerStream struct {
+	grpc.ServerStream
+	auth   *Authorize
+	method string
+}
+
+func (s *authorizedServerStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return s.auth.authorize(s.Context(), s.method, m)
+}
+
+func NewAuthorize(fc AuthorizeFunc) *Authorize {
+	return &Authorize{fc: fc}
+}
+
 func NewBasicAuth(fc BasicAuthFunc) *BasicAuth {
 	return &BasicAuth{authFC: fc}
 }
 
+// NewAuth authenticates a client by its verified certificate, falling back to the bearer token
+// and the basic auth. basic may be nil, the clients without a verified certificate then need a token
+func NewAuth(basic BasicAuthFunc, cert CertAuthFunc) *BasicAuth {
+	return &BasicAuth{authFC: basic, certFC: cert}
+}
+
 func NewMemoryBasicAuthFunc(users map[string]string) BasicAuthFunc {
 	return func(username, password string) bool {
 		return subtle.ConstantTimeCompare([]byte(users[username]), []byte(password)) == 1
@@ -2,10 +2,16 @@ package grpcutil_test
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"github.com/RealFax/RedQueen/pkg/grpcutil"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 	"testing"
 )
@@ -20,6 +26,9 @@ func TestBasicAuthUnary_Success(t *testing.T) {
 	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(grpcutil.MetadataAuthorization, "dXNlcm5hbWU6cGFzc3dvcmQ="))
 
 	handler := func(ctx context.Context, req any) (any, error) {
+		username, ok := grpcutil.UserFromContext(ctx)
+		assert.True(t, ok)
+		assert.Equal(t, "username", username)
 		return "success", nil
 	}
 
@@ -69,3 +78,109 @@ func TestBasicAuthUnary_MissingMetadata(t *testing.T) {
 	assert.Nil(t, result)
 	assert.Equal(t, codes.InvalidArgument, status.Code(err))
 }
+
+func TestAuthUnary_Certificate(t *testing.T) {
+	var (
+		basic = grpcutil.NewMemoryBasicAuthFunc(map[string]string{"username": "password"})
+		cert  = func(state tls.ConnectionState) (string, bool) {
+			if len(state.PeerCertificates) == 0 {
+				return "", false
+			}
+			return state.PeerCertificates[0].Subject.CommonName, true
+		}
+		withCert = func(ctx context.Context, cn string) context.Context {
+			state := tls.ConnectionState{}
+			if cn != "" {
+				state.PeerCertificates = []*x509.Certificate{{Subject: pkix.Name{CommonName: cn}}}
+			}
+			return peer.NewContext(ctx, &peer.Peer{AuthInfo: credentials.TLSInfo{State: state}})
+		}
+		handler = func(ctx context.Context, req any) (any, error) {
+			username, _ := grpcutil.UserFromContext(ctx)
+			return username, nil
+		}
+	)
+
+	// the certificate authenticates without basic auth
+	result, err := grpcutil.NewAuth(basic, cert).Unary(withCert(context.Background(), "client-1"), nil, nil, handler)
+	assert.NoError(t, err)
+	assert.Equal(t, "client-1", result)
+
+	// falls back to basic auth
+	ctx := metadata.NewIncomingContext(withCert(context.Background(), ""), metadata.Pairs(grpcutil.MetadataAuthorization, "dXNlcm5hbWU6cGFzc3dvcmQ="))
+	result, err = grpcutil.NewAuth(basic, cert).Unary(ctx, nil, nil, handler)
+	assert.NoError(t, err)
+	assert.Equal(t, "username", result)
+
+	_, err = grpcutil.NewAuth(basic, cert).Unary(withCert(context.Background(), ""), nil, nil, handler)
+	assert.Error(t, err)
+
+	// without basic auth the clients without certificate are rejected
+	_, err = grpcutil.NewAuth(nil, cert).Unary(withCert(context.Background(), ""), nil, nil, handler)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// a valid token still authenticates them
+	auth := grpcutil.NewAuth(nil, cert).WithToken(func(token string) (string, bool) {
+		return "token-user", token == "valid"
+	})
+	ctx = metadata.NewIncomingContext(withCert(context.Background(), ""), metadata.Pairs(grpcutil.MetadataAuthorization, grpcutil.BuildBearerAuthorization("valid")))
+	result, err = auth.Unary(ctx, nil, nil, handler)
+	assert.NoError(t, err)
+	assert.Equal(t, "token-user", result)
+	_, err = auth.Unary(metadata.NewIncomingContext(withCert(context.Background(), ""), metadata.MD{}), nil, nil, handler)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestAuthUnary_Authorize(t *testing.T) {
+	auth := grpcutil.NewBasicAuth(grpcutil.NewMemoryBasicAuthFunc(map[string]string{
+		"username": "password",
+	})).WithAuthorize(func(_ context.Context, username, method string, req any) error {
+		if username == "username" && method == "/test/Allowed" {
+			return nil
+		}
+		return status.Error(codes.PermissionDenied, "denied")
+	})
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		grpcutil.MetadataAuthorization, grpcutil.BuildAuthorization("username", "password"),
+	))
+	handler := func(ctx context.Context, req any) (any, error) {
+		return "success", nil
+	}
+
+	result, err := auth.Unary(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/test/Allowed"}, handler)
+	assert.NoError(t, err)
+	assert.Equal(t, "success", result)
+
+	_, err = auth.Unary(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/test/Denied"}, handler)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestAuthUnary_Token(t *testing.T) {
+	auth := grpcutil.NewBasicAuth(grpcutil.NewMemoryBasicAuthFunc(map[string]string{
+		"username": "password",
+	})).WithToken(func(token string) (string, bool) {
+		return "token-user", token == "valid"
+	})
+
+	handler := func(ctx context.Context, req any) (any, error) {
+		username, _ := grpcutil.UserFromContext(ctx)
+		return username, nil
+	}
+	call := func(authorization string) (any, error) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(grpcutil.MetadataAuthorization, authorization))
+		return auth.Unary(ctx, nil, nil, handler)
+	}
+
+	result, err := call(grpcutil.BuildBearerAuthorization("valid"))
+	assert.NoError(t, err)
+	assert.Equal(t, "token-user", result)
+
+	_, err = call(grpcutil.BuildBearerAuthorization("expired"))
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// the basic auth is still accepted
+	result, err = call(grpcutil.BuildAuthorization("username", "password"))
+	assert.NoError(t, err)
+	assert.Equal(t, "username", result)
+}
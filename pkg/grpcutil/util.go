@@ -7,18 +7,30 @@ import (
 	"strings"
 )
 
-func ParseAuthorization(auth string, fc BasicAuthFunc) bool {
+// BearerPrefix prefixes a bearer token in the authorization, the basic auth is sent bare
+const BearerPrefix = "Bearer "
+
+// DecodeAuthorization returns the username and password carried by the authorization
+func DecodeAuthorization(auth string) (username, password string, ok bool) {
 	p, err := base64.StdEncoding.DecodeString(auth)
 	if err != nil {
-		return false
+		return "", "", false
 	}
 
 	xp := strings.Split(hack.Bytes2String(p), ":")
 	if len(xp) != 2 {
-		return false
+		return "", "", false
 	}
 
-	return fc(xp[0], xp[1])
+	return xp[0], xp[1], true
+}
+
+func ParseAuthorization(auth string, fc BasicAuthFunc) bool {
+	username, password, ok := DecodeAuthorization(auth)
+	if !ok {
+		return false
+	}
+	return fc(username, password)
 }
 
 func BuildAuthorization(username, password string) string {
@@ -31,3 +43,8 @@ func BuildAuthorization(username, password string) string {
 
 	return base64.StdEncoding.EncodeToString(b.Bytes())
 }
+
+// BuildBearerAuthorization returns the authorization carrying the bearer token
+func BuildBearerAuthorization(token string) string {
+	return BearerPrefix + token
+}
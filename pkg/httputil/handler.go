@@ -1,8 +1,11 @@
 package httputil
 
 import (
+	"context"
 	"crypto/subtle"
+	"crypto/tls"
 	"net/http"
+	"strings"
 )
 
 func WrapE(fc func(w http.ResponseWriter, r *http.Request) error) http.HandlerFunc {
@@ -45,12 +48,73 @@ func UseMiddleware(next http.Handler, fc func(w http.ResponseWriter, r *http.Req
 }
 
 type BasicAuthFunc func(username, password string) bool
+
+type userContextKey struct{}
+
+// ContextWithUser returns a copy of ctx carrying the authenticated username
+func ContextWithUser(ctx context.Context, username string) context.Context {
+	return context.WithValue(ctx, userContextKey{}, username)
+}
+
+// UserFromContext returns the authenticated username, ok is false when the request is not authenticated
+func UserFromContext(ctx context.Context) (username string, ok bool) {
+	username, ok = ctx.Value(userContextKey{}).(string)
+	return
+}
+
+type tokenContextKey struct{}
+
+// TokenFromContext reports whether the user of ctx was authenticated by a bearer token
+func TokenFromContext(ctx context.Context) bool {
+	ok, _ := ctx.Value(tokenContextKey{}).(bool)
+	return ok
+}
+
+// CertAuthFunc returns the identity of the verified client certificate of the connection
+type CertAuthFunc func(state tls.ConnectionState) (username string, ok bool)
+
+// TokenAuthFunc returns the user the bearer token was issued to, ok is false for invalid or
+// expired tokens
+type TokenAuthFunc func(token string) (username string, ok bool)
+
+// bearerPrefix prefixes a bearer token in the Authorization header
+const bearerPrefix = "Bearer "
+
 type basicAuth struct {
-	next   http.Handler
-	authFC BasicAuthFunc
+	next    http.Handler
+	authFC  BasicAuthFunc
+	certFC  CertAuthFunc
+	tokenFC TokenAuthFunc
 }
 
 func (a *basicAuth) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if a.certFC != nil && r.TLS != nil {
+		if username, ok := a.certFC(*r.TLS); ok {
+			a.next.ServeHTTP(w, r.WithContext(ContextWithUser(r.Context(), username)))
+			return
+		}
+	}
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), bearerPrefix); ok && a.tokenFC != nil {
+		username, ok := a.tokenFC(token)
+		if !ok {
+			w.Header().Add("WWW-Authenticate", `Bearer error="invalid_token"`)
+			Any(http.StatusUnauthorized, 401).Message("Invalid or expired token").Ok(w)
+			return
+		}
+		ctx := context.WithValue(ContextWithUser(r.Context(), username), tokenContextKey{}, true)
+		a.next.ServeHTTP(w, r.WithContext(ctx))
+		return
+	}
+	if a.authFC == nil {
+		// only the certificates (and tokens) authenticate
+		if a.certFC != nil {
+			Any(http.StatusUnauthorized, 401).Message("No verified client certificate or token present").Ok(w)
+			return
+		}
+		a.next.ServeHTTP(w, r)
+		return
+	}
+
 	username, password, found := r.BasicAuth()
 	if !found {
 		w.Header().Add("WWW-Authenticate", `Basic realm="auth failed"`)
@@ -64,13 +128,35 @@ func (a *basicAuth) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	a.next.ServeHTTP(w, r)
+	a.next.ServeHTTP(w, r.WithContext(ContextWithUser(r.Context(), username)))
 }
 
 func NewBasicAuth(next http.Handler, fc BasicAuthFunc) http.Handler {
 	return &basicAuth{next: next, authFC: fc}
 }
 
+// NewAuth authenticates a client by its verified certificate, falling back to the bearer token and
+// the basic auth. any of them may be nil, the clients matching none are rejected unless only the
+// token is set
+func NewAuth(next http.Handler, basic BasicAuthFunc, cert CertAuthFunc, token TokenAuthFunc) http.Handler {
+	return &basicAuth{next: next, authFC: basic, certFC: cert, tokenFC: token}
+}
+
+// AuthorizeFunc checks whether the user may serve r, username is empty for unauthenticated requests
+type AuthorizeFunc func(r *http.Request, username string) error
+
+// NewAuthorize serves next once fc accepts the request, the error is written like WrapE
+func NewAuthorize(next http.Handler, fc AuthorizeFunc) http.Handler {
+	return WrapE(func(w http.ResponseWriter, r *http.Request) error {
+		username, _ := UserFromContext(r.Context())
+		if err := fc(r, username); err != nil {
+			return err
+		}
+		next.ServeHTTP(w, r)
+		return nil
+	})
+}
+
 func NewMemoryBasicAuthFunc(users map[string]string) BasicAuthFunc {
 	return func(username, password string) bool {
 		return subtle.ConstantTimeCompare([]byte(users[username]), []byte(password)) == 1
@@ -0,0 +1,58 @@
+package ratelimit_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/RealFax/RedQueen/pkg/ratelimit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiter(t *testing.T) {
+	var (
+		l   = ratelimit.New(2, 3)
+		now = time.Now()
+	)
+
+	// the burst, then nothing until the bucket is refilled
+	for i := 0; i < 3; i++ {
+		assert.True(t, l.AllowAt("a", now))
+	}
+	assert.False(t, l.AllowAt("a", now))
+	assert.True(t, l.AllowAt("b", now), "the keys have their own bucket")
+
+	assert.False(t, l.AllowAt("a", now.Add(400*time.Millisecond)))
+	assert.True(t, l.AllowAt("a", now.Add(500*time.Millisecond)))
+	assert.False(t, l.AllowAt("a", now.Add(500*time.Millisecond)))
+
+	// the buckets are capped at the burst
+	later := now.Add(time.Minute)
+	for i := 0; i < 3; i++ {
+		assert.True(t, l.AllowAt("a", later))
+	}
+	assert.False(t, l.AllowAt("a", later))
+}
+
+func TestLimiter_Sweep(t *testing.T) {
+	var (
+		l   = ratelimit.New(10, 10)
+		now = time.Now()
+	)
+	l.AllowAt("a", now)
+	l.AllowAt("b", now)
+	assert.Equal(t, 2, l.Len())
+
+	// the idle buckets are full again and dropped
+	l.AllowAt("c", now.Add(2*time.Second))
+	assert.Equal(t, 1, l.Len())
+}
+
+func TestLimiter_DefaultBurst(t *testing.T) {
+	var (
+		l   = ratelimit.New(0.5, 0)
+		now = time.Now()
+	)
+	assert.True(t, l.AllowAt("a", now))
+	assert.False(t, l.AllowAt("a", now))
+	assert.True(t, l.AllowAt("a", now.Add(2*time.Second)))
+}
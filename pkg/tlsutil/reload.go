@@ -0,0 +1,81 @@
+package tlsutil
+
+import (
+	"crypto/tls"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// KeyPairReloader serves the key pair of the cert & key files, it's loaded again when the files
+// change. the previous key pair is kept when loading fails, e.g. while the files are rewritten
+type KeyPairReloader struct {
+	certFile string
+	keyFile  string
+
+	mu      sync.Mutex
+	modTime time.Time
+	cert    atomic.Pointer[tls.Certificate]
+}
+
+// modified returns the latest modification time of the files
+func (r *KeyPairReloader) modified() (time.Time, error) {
+	var latest time.Time
+	for _, file := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+// Reload loads the key pair, unchanged files are skipped unless force. reloaded reports whether
+// a new key pair is served
+func (r *KeyPairReloader) Reload(force bool) (reloaded bool, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	modTime, err := r.modified()
+	if err != nil {
+		return false, err
+	}
+	if !force && !modTime.After(r.modTime) {
+		return false, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return false, err
+	}
+	r.cert.Store(&cert)
+	r.modTime = modTime
+	return true, nil
+}
+
+// Certificate returns the key pair currently served
+func (r *KeyPairReloader) Certificate() *tls.Certificate {
+	return r.cert.Load()
+}
+
+// GetCertificate implements tls.Config.GetCertificate
+func (r *KeyPairReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.cert.Load(), nil
+}
+
+// GetClientCertificate implements tls.Config.GetClientCertificate
+func (r *KeyPairReloader) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return r.cert.Load(), nil
+}
+
+func NewKeyPairReloader(certFile, keyFile string) (*KeyPairReloader, error) {
+	r := &KeyPairReloader{certFile: certFile, keyFile: keyFile}
+	if _, err := r.Reload(true); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
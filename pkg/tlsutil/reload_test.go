@@ -0,0 +1,67 @@
+package tlsutil_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/RealFax/RedQueen/pkg/tlsutil"
+	"github.com/stretchr/testify/require"
+)
+
+// writeKeyPair writes a new key pair for cn, the files are dated at modTime
+func writeKeyPair(t *testing.T, certFile, keyFile, cn string, modTime time.Time) {
+	cert, err := tlsutil.GenX509KeyPair(pkix.Name{CommonName: cn})
+	require.NoError(t, err)
+	key, err := x509.MarshalECPrivateKey(cert.PrivateKey.(*ecdsa.PrivateKey))
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]}), 0600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: key}), 0600))
+	require.NoError(t, os.Chtimes(certFile, modTime, modTime))
+	require.NoError(t, os.Chtimes(keyFile, modTime, modTime))
+}
+
+func commonName(t *testing.T, r *tlsutil.KeyPairReloader) string {
+	cert, err := x509.ParseCertificate(r.Certificate().Certificate[0])
+	require.NoError(t, err)
+	return cert.Subject.CommonName
+}
+
+func TestKeyPairReloader(t *testing.T) {
+	var (
+		dir      = t.TempDir()
+		certFile = filepath.Join(dir, "cert.pem")
+		keyFile  = filepath.Join(dir, "key.pem")
+		now      = time.Now()
+	)
+	writeKeyPair(t, certFile, keyFile, "first", now.Add(-time.Hour))
+
+	r, err := tlsutil.NewKeyPairReloader(certFile, keyFile)
+	require.NoError(t, err)
+	require.Equal(t, "first", commonName(t, r))
+
+	reloaded, err := r.Reload(false)
+	require.NoError(t, err)
+	require.False(t, reloaded)
+
+	writeKeyPair(t, certFile, keyFile, "second", now)
+	reloaded, err = r.Reload(false)
+	require.NoError(t, err)
+	require.True(t, reloaded)
+	require.Equal(t, "second", commonName(t, r))
+
+	// a broken key pair keeps the previous one
+	require.NoError(t, os.WriteFile(keyFile, []byte("broken"), 0600))
+	_, err = r.Reload(true)
+	require.Error(t, err)
+	cert, err := r.GetCertificate(nil)
+	require.NoError(t, err)
+	require.Equal(t, r.Certificate(), cert)
+	require.Equal(t, "second", commonName(t, r))
+}
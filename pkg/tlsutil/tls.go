@@ -7,7 +7,9 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"errors"
 	"math/big"
+	"os"
 	"time"
 )
 
@@ -47,3 +49,61 @@ func GenX509KeyPair(subject pkix.Name) (tls.Certificate, error) {
 
 	return outCert, nil
 }
+
+// LoadCertPool reads the PEM encoded ca bundle
+func LoadCertPool(file string) (*x509.CertPool, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(b) {
+		return nil, errors.New("no certificate found in " + file)
+	}
+	return pool, nil
+}
+
+// CertificateIdentity returns the common name of the certificate, or its first SAN
+func CertificateIdentity(cert *x509.Certificate) string {
+	switch {
+	case cert.Subject.CommonName != "":
+		return cert.Subject.CommonName
+	case len(cert.DNSNames) != 0:
+		return cert.DNSNames[0]
+	case len(cert.EmailAddresses) != 0:
+		return cert.EmailAddresses[0]
+	case len(cert.URIs) != 0:
+		return cert.URIs[0].String()
+	}
+	return ""
+}
+
+// ClientIdentity returns the identity of the client certificate of the connection, ok is false
+// unless the certificate is verified. certificates the handshake didn't verify (e.g. asked with
+// tls.RequestClientCert) are verified against roots
+func ClientIdentity(state tls.ConnectionState, roots *x509.CertPool) (identity string, ok bool) {
+	if len(state.PeerCertificates) == 0 {
+		return "", false
+	}
+	cert := state.PeerCertificates[0]
+
+	if len(state.VerifiedChains) == 0 {
+		if roots == nil {
+			return "", false
+		}
+		intermediates := x509.NewCertPool()
+		for _, c := range state.PeerCertificates[1:] {
+			intermediates.AddCert(c)
+		}
+		if _, err := cert.Verify(x509.VerifyOptions{
+			Roots:         roots,
+			Intermediates: intermediates,
+			KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		}); err != nil {
+			return "", false
+		}
+	}
+
+	identity = CertificateIdentity(cert)
+	return identity, identity != ""
+}